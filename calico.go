@@ -0,0 +1,64 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"net"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// Annotations of addresses of nodes autodetected by Calico. Values are CIDRs, e.g. 10.0.0.1/24.
+const (
+	calicoIPv4Annotation = "projectcalico.org/IPv4Address"
+	calicoIPv6Annotation = "projectcalico.org/IPv6Address"
+)
+
+// withCalicoAddresses returns the event with addresses of Calico annotations added to the node as InternalIP
+// addresses. An annotation is used only if the node has no InternalIP address of its family.
+func withCalicoAddresses(e watch.Event) watch.Event {
+	var node = e.Object.(*corev1.Node)
+	var added []corev1.NodeAddress
+	for _, annotation := range []string{calicoIPv4Annotation, calicoIPv6Annotation} {
+		var value, ok = node.Annotations[annotation]
+		if !ok {
+			continue
+		}
+		var ip = parseIPOrCIDR(value)
+		if ip != "" && !hasInternalIPOfFamily(node, ip) {
+			added = append(added, corev1.NodeAddress{Type: corev1.NodeInternalIP, Address: ip})
+		}
+	}
+	if len(added) == 0 {
+		return e
+	}
+
+	node = node.DeepCopy()
+	node.Status.Addresses = append(node.Status.Addresses, added...)
+	e.Object = node
+	return e
+}
+
+func hasInternalIPOfFamily(node *corev1.Node, ip string) bool {
+	for _, address := range node.Status.Addresses {
+		if address.Type == corev1.NodeInternalIP && net.ParseIP(address.Address) != nil && isIPv4(address.Address) == isIPv4(ip) {
+			return true
+		}
+	}
+	return false
+}
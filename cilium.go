@@ -0,0 +1,63 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/cilium"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+)
+
+func startCiliumNodeSource(ctx context.Context, conf *Config, f *informerFactories, eventsCh chan<- mapipwriter.Event) {
+	var informer = f.customInformer(v1.NamespaceAll, cilium.GroupVersionResource)
+	f.run(ctx, conf, cilium.Resource, informer, newCiliumNodeTranslator(conf), eventsCh)
+}
+
+// newCiliumNodeTranslator returns a translator of CiliumNodes. InternalIP and ExternalIP addresses advertised by
+// Cilium are translated as addresses of the node and merged with translations of the Node object.
+func newCiliumNodeTranslator(conf *Config) func(watch.Event) []mapipwriter.Event {
+	var published publishedEntries
+	return func(e watch.Event) []mapipwriter.Event {
+		var ciliumNode = fromUnstructured[cilium.CiliumNode](e.Object)
+		var events []mapipwriter.Event
+		if e.Type != watch.Deleted {
+			events = translationFromNode(watch.Event{Type: e.Type, Object: ciliumNodeNode(ciliumNode)}, "", conf.NodeAllExternalIPs)
+		}
+		for i := range events {
+			events[i].Node = ciliumNode.Name
+		}
+		return published.update(ciliumNode.Name, e.Type, events)
+	}
+}
+
+// ciliumNodeNode returns a node with addresses of the CiliumNode. CiliumInternalIP addresses are kept, but they are
+// not translated.
+func ciliumNodeNode(ciliumNode *cilium.CiliumNode) *corev1.Node {
+	var node = &corev1.Node{ObjectMeta: v1.ObjectMeta{Name: ciliumNode.Name}}
+	for _, address := range ciliumNode.Spec.Addresses {
+		node.Status.Addresses = append(node.Status.Addresses, corev1.NodeAddress{
+			Type:    corev1.NodeAddressType(address.Type),
+			Address: address.IP,
+		})
+	}
+	return node
+}
@@ -0,0 +1,146 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/cloudmeta"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/gateway"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/stun"
+)
+
+// discoverPublicIPs queries public IPs of the instance from the metadata service of CloudMetadata. The gateway of
+// GatewayProtocol and then STUN servers are queried if there is no metadata service or it returns no IPs. Errors are
+// logged, so the app works without them.
+func discoverPublicIPs(ctx context.Context, conf *Config) []string {
+	if conf.CloudMetadata != "" {
+		provider, err := cloudmeta.New(conf.CloudMetadata, conf.CloudMetadataURL)
+		if err != nil {
+			log.FromContext(ctx).Fatal(err.Error())
+		}
+		if ips := queryPublicIPs(ctx, provider); len(ips) > 0 {
+			return ips
+		}
+	}
+	if conf.GatewayProtocol != "" {
+		client, err := gateway.New(conf.GatewayProtocol, conf.GatewayAddress)
+		if err != nil {
+			log.FromContext(ctx).Fatal(err.Error())
+		}
+		if ips := queryPublicIPs(ctx, client); len(ips) > 0 {
+			return ips
+		}
+	}
+	if conf.StunServers != "" {
+		var client = &stun.Client{Servers: splitList(conf.StunServers)}
+		result, err := client.Discover(ctx)
+		if err != nil {
+			log.FromContext(ctx).Errorf("can't discover public IPs using STUN: %v", err.Error())
+			return nil
+		}
+		if !result.EndpointIndependent {
+			log.FromContext(ctx).Warnf("NAT mapping is not endpoint independent or it can't be detected: %v", result.Mappings)
+		}
+		log.FromContext(ctx).Infof("public IPs from %v: %v", client.Name(), result.IPs())
+		return result.IPs()
+	}
+	return nil
+}
+
+// portMappingLifetime is the lifetime of port mappings requested from the gateway. They are renewed each half of it.
+const portMappingLifetime = time.Hour
+
+// startPortMappings requests GatewayPortMappings from the gateway and renews them until ctx is done
+func startPortMappings(ctx context.Context, conf *Config) {
+	mappings, err := gateway.ParsePortMappings(conf.GatewayPortMappings)
+	if err != nil {
+		log.FromContext(ctx).Fatal(err.Error())
+	}
+	client, err := gateway.New(conf.GatewayProtocol, conf.GatewayAddress)
+	if err != nil {
+		log.FromContext(ctx).Fatal(err.Error())
+	}
+
+	go func() {
+		var ticker = time.NewTicker(portMappingLifetime / 2)
+		defer ticker.Stop()
+		for {
+			for _, mapping := range mappings {
+				port, mapErr := client.MapPort(ctx, mapping, portMappingLifetime)
+				if mapErr != nil {
+					log.FromContext(ctx).Errorf("can't map %v port %v: %v", mapping.Protocol, mapping.Port, mapErr.Error())
+					continue
+				}
+				log.FromContext(ctx).Debugf("%v port %v is mapped to the external port %v", mapping.Protocol, mapping.Port, port)
+			}
+			select {
+			case <-ctx.Done():
+				// the context is done, so mappings are deleted with a new one
+				deleteCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), gateway.DefaultTimeout)
+				for _, mapping := range mappings {
+					_, _ = client.MapPort(deleteCtx, mapping, 0)
+				}
+				cancel()
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func queryPublicIPs(ctx context.Context, provider cloudmeta.Provider) []string {
+	ips, err := provider.PublicIPs(ctx)
+	if err != nil {
+		log.FromContext(ctx).Errorf("can't get public IPs from %v: %v", provider.Name(), err.Error())
+		return nil
+	}
+	log.FromContext(ctx).Infof("public IPs from %v: %v", provider.Name(), ips)
+	return ips
+}
+
+// withCloudAddresses adds public IPs of the instance as external addresses of the node the app runs on if the node
+// has no external addresses. If CloudMetadataOverride is set then they replace external addresses of the node status.
+// The override annotation of the node takes precedence in both cases.
+func withCloudAddresses(e watch.Event, conf *Config, ips []string) watch.Event {
+	var node = e.Object.(*corev1.Node)
+	if len(ips) == 0 || node.Name != conf.NodeName {
+		return e
+	}
+	if overrides, _ := overrideAddresses(node, conf.ExternalIPAnnotation); len(overrides) > 0 {
+		return e
+	}
+	if !conf.CloudMetadataOverride && len(externalAddresses(node, conf.ExternalIPAnnotation)) > 0 {
+		return e
+	}
+	node = node.DeepCopy()
+	node.Status.Addresses = slices.DeleteFunc(node.Status.Addresses, func(address corev1.NodeAddress) bool {
+		return address.Type == corev1.NodeExternalIP
+	})
+	for _, ip := range ips {
+		node.Status.Addresses = append(node.Status.Addresses, corev1.NodeAddress{Type: corev1.NodeExternalIP, Address: ip})
+	}
+	e.Object = node
+	return e
+}
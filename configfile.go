@@ -0,0 +1,259 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/kelseyhightower/envconfig"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+)
+
+// envPrefix is the prefix of environment variables of Config
+const envPrefix = "nsm"
+
+// configKeysTemplate prints environment variables of Config with names of their fields
+var configKeysTemplate = template.Must(template.New("keys").Parse("{{range .}}{{.Key}} {{.Name}}\n{{end}}"))
+
+// LoadConfig processes Config from the environment, ConfigFile and command-line flags of args. Flags take precedence
+// over ConfigFile and ConfigFile takes precedence over the environment. It returns pflag.ErrHelp if help is requested
+// and ErrVersion if the version is requested.
+func LoadConfig(args []string) (*Config, error) {
+	var conf = new(Config)
+	if err := envconfig.Process(envPrefix, conf); err != nil {
+		return nil, errors.Wrap(err, "can't process config from the environment")
+	}
+	flags, err := newConfigFlags(conf)
+	if err != nil {
+		return nil, err
+	}
+	if err = flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if printVersion, _ := flags.GetBool("version"); printVersion {
+		return nil, ErrVersion
+	}
+	// the file is set by the environment or a flag, so flags are applied after it again
+	if err = flags.apply(conf, "config-file"); err != nil {
+		return nil, err
+	}
+	if conf.ConfigFile != "" {
+		if err = loadConfigFile(conf, conf.ConfigFile); err != nil {
+			return nil, err
+		}
+	}
+	if err = flags.apply(conf); err != nil {
+		return nil, err
+	}
+	return conf, nil
+}
+
+// configFields returns names of fields of Config by names of their environment variables
+func configFields(conf *Config) (map[string]string, error) {
+	var keys bytes.Buffer
+	if err := envconfig.Usaget(envPrefix, conf, &keys, configKeysTemplate); err != nil {
+		return nil, errors.Wrap(err, "can't list config keys")
+	}
+	var fields = make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(keys.String()), "\n") {
+		if key, name, ok := strings.Cut(line, " "); ok {
+			fields[key] = name
+		}
+	}
+	return fields, nil
+}
+
+// loadConfigFile sets fields of conf from the YAML file of environment variables, e.g. "NSM_FROM_CONFIG_MAP: nsm".
+// The prefix of names can be omitted and the case is ignored, e.g. "from_config_map: nsm".
+func loadConfigFile(conf *Config, path string) error {
+	// #nosec G304
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return errors.Wrapf(err, "can't read config file %v", path)
+	}
+	var values map[string]string
+	if err = yaml.Unmarshal(data, &values); err != nil {
+		return errors.Wrapf(err, "can't parse config file %v", path)
+	}
+
+	fields, err := configFields(conf)
+	if err != nil {
+		return err
+	}
+
+	var spec = reflect.ValueOf(conf).Elem()
+	for key, value := range values {
+		var envKey = strings.ToUpper(key)
+		if !strings.HasPrefix(envKey, strings.ToUpper(envPrefix)+"_") {
+			envKey = strings.ToUpper(envPrefix) + "_" + envKey
+		}
+		name, ok := fields[envKey]
+		if !ok {
+			return errors.Errorf("unknown key %q of config file %v", key, path)
+		}
+		if err = setConfigField(spec.FieldByName(name), value); err != nil {
+			return errors.Wrapf(err, "invalid value of %q of config file %v", key, path)
+		}
+	}
+	return nil
+}
+
+func setConfigField(field reflect.Value, value string) error {
+	if field.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(value, 0, field.Type().Bits())
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		field.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(value, 0, field.Type().Bits())
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		field.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(value, field.Type().Bits())
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		field.SetFloat(f)
+	default:
+		return errors.Errorf("unsupported type %v", field.Type())
+	}
+	return nil
+}
+
+// StartWithReload starts the app with conf like Start and restarts it with the config loaded by LoadConfig of args on
+// changes of ConfigFile. Pending events of the running app are written before the restart, OutputOnShutdown isn't
+// applied to outputs on the restart and the written map is kept until the initial lists of sources are loaded by the
+// restarted app. The running app is kept if the changed config can't be loaded.
+func StartWithReload(ctx context.Context, conf *Config, c kubernetes.Interface, d dynamic.Interface, args []string) <-chan struct{} {
+	var reloadCh = watchConfigFile(ctx, conf.ConfigFile)
+	var done = make(chan struct{})
+
+	go func() {
+		defer close(done)
+		var appCtx, cancel = context.WithCancelCause(ctx)
+		var appDone = Start(appCtx, conf, c, d)
+		for {
+			select {
+			case <-ctx.Done():
+				cancel(nil)
+				<-appDone
+				return
+			case <-reloadCh:
+			}
+			next, err := LoadConfig(args)
+			if err != nil {
+				log.FromContext(ctx).Errorf("%v, the current config is kept", err.Error())
+				continue
+			}
+			if reflect.DeepEqual(next, conf) {
+				continue
+			}
+			log.FromContext(ctx).Infof("config file %v is changed, restarting", conf.ConfigFile)
+			cancel(mapipwriter.ErrRestart)
+			<-appDone
+			if level, levelErr := logrus.ParseLevel(next.LogLevel); levelErr == nil {
+				logrus.SetLevel(level)
+			}
+			conf = next
+			appCtx, cancel = context.WithCancelCause(ctx)
+			appDone = start(appCtx, conf, c, d, true)
+		}
+	}()
+	return done
+}
+
+// watchConfigFile notifies about changes of the content of the file. The directory of the file is watched, so the
+// file replaced by the symlink swap of projected volumes is tracked as well.
+func watchConfigFile(ctx context.Context, path string) <-chan struct{} {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.FromContext(ctx).Fatalf("can't create file watcher: %v", err.Error())
+	}
+	if err = watcher.Add(filepath.Dir(path)); err != nil {
+		log.FromContext(ctx).Fatalf("can't watch %v: %v", filepath.Dir(path), err.Error())
+	}
+
+	// #nosec G304
+	var last, _ = os.ReadFile(path)
+	var changed = make(chan struct{}, 1)
+	go func() {
+		defer func() { _ = watcher.Close() }()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case _, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				// #nosec G304
+				current, readErr := os.ReadFile(path)
+				if readErr != nil || bytes.Equal(current, last) {
+					continue
+				}
+				last = current
+				select {
+				case changed <- struct{}{}:
+				default:
+				}
+			case watchErr, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.FromContext(ctx).Errorf("an error during watching config file: %v", watchErr.Error())
+			}
+		}
+	}()
+	return changed
+}
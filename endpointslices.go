@@ -0,0 +1,144 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+)
+
+// endpointSliceService is a Service EndpointSlices are watched for
+type endpointSliceService struct {
+	namespace, name string
+}
+
+func (s endpointSliceService) listOptions() v1.ListOptions {
+	return v1.ListOptions{LabelSelector: discoveryv1.LabelServiceName + "=" + s.name}
+}
+
+// parseEndpointSliceServices parses a comma separated list of namespace/name of Services. Name without namespace
+// refers to a Service in the namespace of the app.
+func parseEndpointSliceServices(conf *Config) []endpointSliceService {
+	var result []endpointSliceService
+	for _, item := range strings.Split(conf.EndpointSliceServices, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		var service = endpointSliceService{namespace: conf.Namespace, name: item}
+		if namespace, name, ok := strings.Cut(item, "/"); ok {
+			service = endpointSliceService{namespace: namespace, name: name}
+		}
+		result = append(result, service)
+	}
+	return result
+}
+
+func startEndpointSliceSource(ctx context.Context, conf *Config, f *informerFactories, eventsCh chan<- mapipwriter.Event) {
+	var nodes = f.nodes()
+	var translate = newEndpointSliceTranslator(ctx, conf, &cachedObjects{nodes: corelisters.NewNodeLister(nodes.GetIndexer())})
+	for _, service := range parseEndpointSliceServices(conf) {
+		var informer = f.get(service.namespace, service.listOptions()).Discovery().V1().EndpointSlices().Informer()
+		f.follow(ctx, nodes, informer, externalAddressesChanged(conf), hasEndpointOn, translate, eventsCh)
+		f.run(ctx, conf, "endpointslices", informer, translate, eventsCh)
+	}
+}
+
+// hasEndpointOn reports whether an endpoint of the EndpointSlice runs on the node
+func hasEndpointOn(nodeObj, sliceObj interface{}) bool {
+	var node, slice = nodeObj.(*corev1.Node), sliceObj.(*discoveryv1.EndpointSlice)
+	for i := range slice.Endpoints {
+		if nodeName := slice.Endpoints[i].NodeName; nodeName != nil && *nodeName == node.Name {
+			return true
+		}
+	}
+	return false
+}
+
+func listEndpointSlices(ctx context.Context, c kubernetes.Interface, service endpointSliceService, translate func(watch.Event) []mapipwriter.Event) ([]mapipwriter.Event, error) {
+	list, err := c.DiscoveryV1().EndpointSlices(service.namespace).List(ctx, service.listOptions())
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't list endpointslices of %v/%v", service.namespace, service.name)
+	}
+
+	var result []mapipwriter.Event
+	for i := 0; i < len(list.Items); i++ {
+		result = append(result, translate(watch.Event{
+			Type:   watch.Added,
+			Object: &list.Items[i],
+		})...)
+	}
+	return result, nil
+}
+
+// newEndpointSliceTranslator returns a translator of EndpointSlices mapping addresses of endpoints to the external IP
+// of the node they run on. The translator is shared by watchers of all services and is safe for concurrent use.
+func newEndpointSliceTranslator(ctx context.Context, conf *Config, objects clusterObjects) func(watch.Event) []mapipwriter.Event {
+	var published publishedEntries
+	var mu sync.Mutex
+	return func(e watch.Event) []mapipwriter.Event {
+		var slice = e.Object.(*discoveryv1.EndpointSlice)
+		var events []mapipwriter.Event
+		if e.Type != watch.Deleted {
+			events = translationFromEndpointSlice(ctx, conf, objects, e.Type, slice)
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		return published.update(slice.Namespace+"/"+slice.Name, e.Type, events)
+	}
+}
+
+func translationFromEndpointSlice(ctx context.Context, conf *Config, objects clusterObjects, eventType watch.EventType, slice *discoveryv1.EndpointSlice) []mapipwriter.Event {
+	var externals = make(map[string][]string)
+	var result []mapipwriter.Event
+	for i := range slice.Endpoints {
+		var endpoint = &slice.Endpoints[i]
+		if endpoint.NodeName == nil || *endpoint.NodeName == "" {
+			continue
+		}
+		addresses, ok := externals[*endpoint.NodeName]
+		if !ok {
+			addresses = nodeExternalAddresses(ctx, conf, objects, *endpoint.NodeName)
+			externals[*endpoint.NodeName] = addresses
+		}
+		result = append(result, translationToSameFamily(eventType, endpoint.Addresses, addresses)...)
+	}
+	return result
+}
+
+// nodeExternalAddresses gets the node and returns its external addresses
+func nodeExternalAddresses(ctx context.Context, conf *Config, objects clusterObjects, nodeName string) []string {
+	node, err := objects.getNode(ctx, nodeName)
+	if err != nil {
+		log.FromContext(ctx).Warnf("can't get node %v: %v", nodeName, err.Error())
+		return nil
+	}
+	return externalAddresses(node, conf.ExternalIPAnnotation)
+}
@@ -0,0 +1,138 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+)
+
+// fileTranslator reads files of FromFiles and withdraws entries removed from them
+type fileTranslator struct {
+	published publishedEntries
+	mu        sync.Mutex
+}
+
+// read returns events of the entries of the file. Entries of the file are withdrawn if it doesn't exist.
+func (f *fileTranslator) read(path string) ([]mapipwriter.Event, error) {
+	// #nosec G304
+	data, err := os.ReadFile(path)
+	var eventType = watch.Modified
+	switch {
+	case os.IsNotExist(err):
+		eventType = watch.Deleted
+	case err != nil:
+		return nil, errors.Wrapf(err, "can't read %v", path)
+	}
+
+	var m map[string]string
+	if err = yaml.Unmarshal(data, &m); err != nil {
+		return nil, errors.Wrapf(err, "can't parse %v", path)
+	}
+	var events []mapipwriter.Event
+	for from, to := range m {
+		events = append(events, mapipwriter.Event{
+			Type:        eventType,
+			Translation: mapipwriter.Translation{From: from, To: to},
+		})
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.published.update(path, eventType, events), nil
+}
+
+// readFiles returns events of the entries of files of FromFiles
+func readFiles(conf *Config, translator *fileTranslator) ([]mapipwriter.Event, error) {
+	var result []mapipwriter.Event
+	for _, path := range splitList(conf.FromFiles) {
+		events, err := translator.read(path)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, events...)
+	}
+	return result, nil
+}
+
+// startFileSource reads files of FromFiles and re-reads them on changes. Directories of the files are watched instead
+// of the files, so files replaced by rename or by the symlink swap of projected volumes are tracked as well.
+func startFileSource(ctx context.Context, conf *Config, eventsCh chan<- mapipwriter.Event) {
+	var translator = new(fileTranslator)
+	var paths = splitList(conf.FromFiles)
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		log.FromContext(ctx).Fatalf("can't create file watcher: %v", err.Error())
+	}
+	var dirs = make(map[string][]string)
+	for _, path := range paths {
+		var dir = filepath.Dir(path)
+		if _, ok := dirs[dir]; !ok {
+			if err = watcher.Add(dir); err != nil {
+				log.FromContext(ctx).Fatalf("can't watch %v: %v", dir, err.Error())
+			}
+		}
+		dirs[dir] = append(dirs[dir], path)
+	}
+
+	var reload = func(paths []string) {
+		for _, path := range paths {
+			events, readErr := translator.read(path)
+			if readErr != nil {
+				log.FromContext(ctx).Errorf("%v, previous entries are kept", readErr.Error())
+				continue
+			}
+			for _, event := range events {
+				eventsCh <- event
+			}
+		}
+	}
+	reload(paths)
+
+	go func() {
+		defer func() { _ = watcher.Close() }()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				log.FromContext(ctx).Debugf("file event: %v", event.String())
+				reload(dirs[filepath.Dir(event.Name)])
+			case watchErr, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.FromContext(ctx).Errorf("an error during watching files: %v", watchErr.Error())
+			}
+		}
+	}()
+}
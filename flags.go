@@ -0,0 +1,102 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+)
+
+// ErrVersion is returned by LoadConfig if the version is requested with --version
+var ErrVersion = errors.New("version is requested")
+
+// configFlags are command-line flags of fields of Config named after their environment variables without the prefix,
+// e.g. --output-path for NSM_OUTPUT_PATH
+type configFlags struct {
+	*pflag.FlagSet
+	fields map[string]string
+}
+
+func newConfigFlags(conf *Config) (*configFlags, error) {
+	envFields, err := configFields(conf)
+	if err != nil {
+		return nil, err
+	}
+	var flags = &configFlags{
+		FlagSet: pflag.NewFlagSet("cmd-map-ip-k8s", pflag.ContinueOnError),
+		fields:  make(map[string]string, len(envFields)),
+	}
+	var spec = reflect.TypeOf(conf).Elem()
+	for key, name := range envFields {
+		var flagName = strings.ReplaceAll(strings.ToLower(strings.TrimPrefix(key, strings.ToUpper(envPrefix)+"_")), "_", "-")
+		field, _ := spec.FieldByName(name)
+		flags.Var(&flagValue{value: field.Tag.Get("default"), typ: flagType(field.Type)}, flagName, field.Tag.Get("desc"))
+		if field.Type.Kind() == reflect.Bool {
+			flags.Lookup(flagName).NoOptDefVal = "true"
+		}
+		flags.fields[flagName] = name
+	}
+	flags.Bool("version", false, "Print the version of the build and exit")
+	flags.SortFlags = true
+	return flags, nil
+}
+
+// flagValue keeps the raw value of a flag, it's parsed when it's applied to Config
+type flagValue struct {
+	value, typ string
+}
+
+func (v *flagValue) String() string {
+	return v.value
+}
+
+func (v *flagValue) Set(value string) error {
+	v.value = value
+	return nil
+}
+
+func (v *flagValue) Type() string {
+	return v.typ
+}
+
+// flagType returns the type of values of a field shown in the usage
+func flagType(t reflect.Type) string {
+	if t == reflect.TypeOf(time.Duration(0)) {
+		return "duration"
+	}
+	return t.Kind().String()
+}
+
+// apply sets fields of conf from flags set in the command line. All set flags are applied if names are empty.
+func (f *configFlags) apply(conf *Config, names ...string) error {
+	var spec = reflect.ValueOf(conf).Elem()
+	var err error
+	f.Visit(func(flag *pflag.Flag) {
+		if _, ok := f.fields[flag.Name]; !ok || err != nil || (len(names) > 0 && !slices.Contains(names, flag.Name)) {
+			return
+		}
+		if setErr := setConfigField(spec.FieldByName(f.fields[flag.Name]), flag.Value.String()); setErr != nil {
+			err = errors.Wrapf(setErr, "invalid value of flag --%v", flag.Name)
+		}
+	})
+	return err
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"net"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/watch"
+	corelisters "k8s.io/client-go/listers/core/v1"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/gatewayapi"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+)
+
+func startGatewayAPISource(ctx context.Context, conf *Config, f *informerFactories, eventsCh chan<- mapipwriter.Event) {
+	var services = f.services()
+	var informer = f.customInformer(conf.GatewayAPINamespace, gatewayapi.GroupVersionResource)
+	var translate = synchronized(newGatewayTranslator(ctx, &cachedObjects{services: corelisters.NewServiceLister(services.GetIndexer())}))
+	f.follow(ctx, services, informer, resourceChanged, isGatewayService, translate, eventsCh)
+	f.run(ctx, conf, gatewayapi.Resource, informer, translate, eventsCh)
+}
+
+// isGatewayService reports whether the Service is labeled with the name of the Gateway in its namespace
+func isGatewayService(serviceObj, gatewayObj interface{}) bool {
+	var service, gateway = serviceObj.(*corev1.Service), fromUnstructured[gatewayapi.Gateway](gatewayObj)
+	return service.Namespace == gateway.Namespace && service.Labels[gatewayapi.GatewayNameLabel] == gateway.Name
+}
+
+// newGatewayTranslator returns a translator of Gateways mapping ClusterIPs of Services of the in-cluster deployment of
+// the Gateway to its IP addresses. Services are resolved on events of the Gateway and of the Services.
+func newGatewayTranslator(ctx context.Context, objects clusterObjects) func(watch.Event) []mapipwriter.Event {
+	var published publishedEntries
+	return func(e watch.Event) []mapipwriter.Event {
+		var gateway = fromUnstructured[gatewayapi.Gateway](e.Object)
+		var sources, targets []string
+		if e.Type != watch.Deleted {
+			sources = gatewayServiceIPs(ctx, objects, gateway)
+			for _, address := range gateway.IPAddresses() {
+				if net.ParseIP(address) != nil {
+					targets = append(targets, address)
+				}
+			}
+		}
+		return published.update(gateway.Namespace+"/"+gateway.Name, e.Type, translationToSameFamily(e.Type, sources, targets))
+	}
+}
+
+// gatewayServiceIPs returns ClusterIPs of Services labeled with the name of the Gateway in its namespace
+func gatewayServiceIPs(ctx context.Context, objects clusterObjects, gateway *gatewayapi.Gateway) []string {
+	var selector = labels.Set{gatewayapi.GatewayNameLabel: gateway.Name}.String()
+	services, err := objects.listServices(ctx, gateway.Namespace, selector)
+	if err != nil {
+		log.FromContext(ctx).Warnf("can't list services of gateway %v/%v: %v", gateway.Namespace, gateway.Name, err.Error())
+		return nil
+	}
+	var result []string
+	for _, service := range services {
+		result = append(result, serviceClusterIPs(service)...)
+	}
+	return result
+}
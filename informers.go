@@ -0,0 +1,247 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/metric"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/metrics"
+)
+
+// informerSyncPollInterval is the interval the initial list of informers is checked with
+const informerSyncPollInterval = 10 * time.Millisecond
+
+// informerKey is the namespace and selectors of objects of an informer factory
+type informerKey struct {
+	namespace     string
+	labelSelector string
+	fieldSelector string
+}
+
+// informerFactories creates shared informer factories of a cluster. Sources watching objects of the same namespace
+// with the same selectors share informers, so objects are listed and watched once.
+type informerFactories struct {
+	client           kubernetes.Interface
+	dynamic          dynamic.Interface
+	resync           time.Duration
+	factories        map[informerKey]informers.SharedInformerFactory
+	dynamicFactories map[string]dynamicinformer.DynamicSharedInformerFactory
+	synced           []cache.InformerSynced
+}
+
+func newInformerFactories(conf *Config, c kubernetes.Interface, d dynamic.Interface) *informerFactories {
+	return &informerFactories{
+		client:           c,
+		dynamic:          d,
+		resync:           conf.InformerResync,
+		factories:        make(map[informerKey]informers.SharedInformerFactory),
+		dynamicFactories: make(map[string]dynamicinformer.DynamicSharedInformerFactory),
+	}
+}
+
+// waitForSync waits until the initial lists of all informers started by run are loaded. It returns false if ctx is
+// done before.
+func (f *informerFactories) waitForSync(ctx context.Context) bool {
+	return cache.WaitForCacheSync(ctx.Done(), f.synced...)
+}
+
+// get returns the factory of informers of objects of the namespace matching selectors of options. Empty namespace
+// means all namespaces.
+func (f *informerFactories) get(namespace string, options v1.ListOptions) informers.SharedInformerFactory {
+	var key = informerKey{namespace: namespace, labelSelector: options.LabelSelector, fieldSelector: options.FieldSelector}
+	if factory, ok := f.factories[key]; ok {
+		return factory
+	}
+	var factory = informers.NewSharedInformerFactoryWithOptions(f.client, f.resync,
+		informers.WithNamespace(namespace),
+		informers.WithTweakListOptions(func(listOptions *v1.ListOptions) {
+			listOptions.LabelSelector = options.LabelSelector
+			listOptions.FieldSelector = options.FieldSelector
+		}))
+	f.factories[key] = factory
+	return factory
+}
+
+// nodes returns the informer of all nodes translations of other objects are resolved with. The map isn't written
+// before it's loaded.
+func (f *informerFactories) nodes() cache.SharedIndexInformer {
+	var informer = f.get(v1.NamespaceAll, v1.ListOptions{}).Core().V1().Nodes().Informer()
+	f.synced = append(f.synced, informer.HasSynced)
+	return informer
+}
+
+// services returns the informer of all Services translations of other objects are resolved with. The map isn't
+// written before it's loaded.
+func (f *informerFactories) services() cache.SharedIndexInformer {
+	var informer = f.get(v1.NamespaceAll, v1.ListOptions{}).Core().V1().Services().Informer()
+	f.synced = append(f.synced, informer.HasSynced)
+	return informer
+}
+
+// customInformer returns the informer of custom resources of the namespace. Empty namespace means all namespaces.
+// Objects of the informer are *unstructured.Unstructured, they are converted with fromUnstructured.
+func (f *informerFactories) customInformer(namespace string, resource schema.GroupVersionResource) cache.SharedIndexInformer {
+	factory, ok := f.dynamicFactories[namespace]
+	if !ok {
+		factory = dynamicinformer.NewFilteredDynamicSharedInformerFactory(f.dynamic, f.resync, namespace, nil)
+		f.dynamicFactories[namespace] = factory
+	}
+	return factory.ForResource(resource).Informer()
+}
+
+// fromUnstructured converts the custom resource of the dynamic client into T. Objects are validated by the API server
+// with the schema of their definition, so the conversion is not expected to fail. Fields following the first field
+// not matching T are left empty then.
+func fromUnstructured[T any](obj interface{}) *T {
+	var result = new(T)
+	if object, ok := obj.(*unstructured.Unstructured); ok {
+		_ = runtime.DefaultUnstructuredConverter.FromUnstructured(object.UnstructuredContent(), result)
+	}
+	return result
+}
+
+// run starts the informer and sends events of its objects translated by translate into out. Updates are sent as
+// Modified events including resyncs, deletions with unknown final state are sent with the last known object. It
+// returns when the initial list is loaded or fails. Errors are logged and counted, the reflector of the informer
+// retries them with exponential backoff from 800ms to 30s with jitter. Watches are resumed from the resource version
+// of the last event or bookmark, objects are listed again only if it's expired.
+func (f *informerFactories) run(ctx context.Context, conf *Config, resource string, informer cache.SharedIndexInformer,
+	translate func(watch.Event) []mapipwriter.Event, out chan<- mapipwriter.Event) {
+	f.synced = append(f.synced, informer.HasSynced)
+	var errCh = make(chan error, 1)
+	var restarts = metrics.Int64Counter(ctx, metrics.WatchRestartsName,
+		metric.WithDescription("count of failed lists and watches of sources which are retried"))
+	var owned = informer.SetWatchErrorHandler(func(_ *cache.Reflector, err error) {
+		restarts.Add(ctx, 1, metric.WithAttributes(metrics.ResourceKey.String(resource)))
+		logWatchError(ctx, resource, err, conf.ExitOnForbidden)
+		select {
+		case errCh <- err:
+		default:
+		}
+	}) == nil
+
+	var send = func(eventType watch.EventType, obj interface{}) {
+		if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+			obj = tombstone.Obj
+		}
+		object, ok := obj.(runtime.Object)
+		if !ok {
+			return
+		}
+		for _, event := range translate(watch.Event{Type: eventType, Object: object}) {
+			select {
+			case out <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { send(watch.Added, obj) },
+		UpdateFunc: func(_, obj interface{}) { send(watch.Modified, obj) },
+		DeleteFunc: func(obj interface{}) { send(watch.Deleted, obj) },
+	})
+
+	for _, factory := range f.factories {
+		factory.Start(ctx.Done())
+	}
+	for _, factory := range f.dynamicFactories {
+		factory.Start(ctx.Done())
+	}
+	if !owned {
+		// the informer is started by another source which has waited for it
+		return
+	}
+
+	var ticker = time.NewTicker(informerSyncPollInterval)
+	defer ticker.Stop()
+	for !informer.HasSynced() {
+		select {
+		case <-errCh:
+			log.FromContext(ctx).Warnf("can't load %v, entries are loaded when it's available", resource)
+			return
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// follow sends Modified events of objects of the informer matched by dependent translated by translate into out when
+// an object of dependency is added or deleted, or is updated and changed reports it. Updated objects are matched in
+// both the old and the new state. So translations follow objects they are resolved with, e.g. pods follow external
+// addresses of their nodes. translate must be safe for concurrent use with handlers of the informer.
+func (f *informerFactories) follow(ctx context.Context, dependency, informer cache.SharedIndexInformer,
+	changed func(oldObj, newObj interface{}) bool, dependent func(dependencyObj, obj interface{}) bool,
+	translate func(watch.Event) []mapipwriter.Event, out chan<- mapipwriter.Event) {
+	var retranslate = func(dependencyObjs ...interface{}) {
+		for i := range dependencyObjs {
+			if tombstone, ok := dependencyObjs[i].(cache.DeletedFinalStateUnknown); ok {
+				dependencyObjs[i] = tombstone.Obj
+			}
+		}
+		for _, obj := range informer.GetStore().List() {
+			if !slices.ContainsFunc(dependencyObjs, func(dependencyObj interface{}) bool { return dependent(dependencyObj, obj) }) {
+				continue
+			}
+			for _, event := range translate(watch.Event{Type: watch.Modified, Object: obj.(runtime.Object)}) {
+				select {
+				case out <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}
+	dependency.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) { retranslate(obj) },
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			if changed(oldObj, newObj) {
+				retranslate(oldObj, newObj)
+			}
+		},
+		DeleteFunc: func(obj interface{}) { retranslate(obj) },
+	})
+}
+
+// synchronized returns the translator safe for concurrent use, e.g. by handlers of several informers
+func synchronized(translate func(watch.Event) []mapipwriter.Event) func(watch.Event) []mapipwriter.Event {
+	var mu sync.Mutex
+	return func(e watch.Event) []mapipwriter.Event {
+		mu.Lock()
+		defer mu.Unlock()
+		return translate(e)
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"slices"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+)
+
+func startIngressSource(ctx context.Context, conf *Config, f *informerFactories, eventsCh chan<- mapipwriter.Event) {
+	var nodes, services = f.nodes(), f.services()
+	var informer = f.get(conf.IngressNamespace, v1.ListOptions{}).Networking().V1().Ingresses().Informer()
+	var translate = synchronized(newIngressTranslator(ctx, conf, &cachedObjects{
+		nodes:    corelisters.NewNodeLister(nodes.GetIndexer()),
+		services: corelisters.NewServiceLister(services.GetIndexer()),
+	}))
+	f.follow(ctx, services, informer, resourceChanged, isIngressBackend, translate, eventsCh)
+	if conf.IngressNodePorts {
+		f.follow(ctx, nodes, informer, internalIPsChanged, anyObject, translate, eventsCh)
+	}
+	f.run(ctx, conf, "ingresses", informer, translate, eventsCh)
+}
+
+// isIngressBackend reports whether the Service is a backend of the Ingress
+func isIngressBackend(serviceObj, ingressObj interface{}) bool {
+	var service, ingress = serviceObj.(*corev1.Service), ingressObj.(*networkingv1.Ingress)
+	return service.Namespace == ingress.Namespace && slices.Contains(ingressBackendServices(ingress), service.Name)
+}
+
+func listIngresses(ctx context.Context, conf *Config, c kubernetes.Interface, translate func(watch.Event) []mapipwriter.Event) ([]mapipwriter.Event, error) {
+	list, err := c.NetworkingV1().Ingresses(conf.IngressNamespace).List(ctx, v1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "can't list ingresses")
+	}
+
+	var result []mapipwriter.Event
+	for i := 0; i < len(list.Items); i++ {
+		result = append(result, translate(watch.Event{
+			Type:   watch.Added,
+			Object: &list.Items[i],
+		})...)
+	}
+	return result, nil
+}
+
+// newIngressTranslator returns a translator of Ingresses. Backend Services are resolved on events of the Ingress and
+// of the Services.
+func newIngressTranslator(ctx context.Context, conf *Config, objects clusterObjects) func(watch.Event) []mapipwriter.Event {
+	var published publishedEntries
+	return func(e watch.Event) []mapipwriter.Event {
+		var ingress = e.Object.(*networkingv1.Ingress)
+		var sources []string
+		if e.Type != watch.Deleted {
+			sources = ingressBackendIPs(ctx, conf, objects, ingress)
+		}
+		var events = translationToSameFamily(e.Type, sources, loadBalancerIPs(&ingress.Status.LoadBalancer))
+		return published.update(ingress.Namespace+"/"+ingress.Name, e.Type, events)
+	}
+}
+
+// ingressBackendIPs returns ClusterIPs of backend Services of the Ingress and internal IPs of nodes if IngressNodePorts
+// is set and a Service has NodePorts
+func ingressBackendIPs(ctx context.Context, conf *Config, objects clusterObjects, ingress *networkingv1.Ingress) []string {
+	var result []string
+	var nodePorts bool
+	for _, name := range ingressBackendServices(ingress) {
+		service, err := objects.getService(ctx, ingress.Namespace, name)
+		if err != nil {
+			log.FromContext(ctx).Warnf("can't get backend service %v of ingress %v/%v: %v", name, ingress.Namespace, ingress.Name, err.Error())
+			continue
+		}
+		for _, ip := range serviceClusterIPs(service) {
+			if !slices.Contains(result, ip) {
+				result = append(result, ip)
+			}
+		}
+		nodePorts = nodePorts || hasNodePorts(service)
+	}
+	if conf.IngressNodePorts && nodePorts {
+		result = append(result, nodeInternalIPs(ctx, conf, objects)...)
+	}
+	return result
+}
+
+// ingressBackendServices returns distinct names of Services of the default backend and backends of rules
+func ingressBackendServices(ingress *networkingv1.Ingress) []string {
+	var backends []*networkingv1.IngressBackend
+	if ingress.Spec.DefaultBackend != nil {
+		backends = append(backends, ingress.Spec.DefaultBackend)
+	}
+	for i := range ingress.Spec.Rules {
+		if http := ingress.Spec.Rules[i].HTTP; http != nil {
+			for j := range http.Paths {
+				backends = append(backends, &http.Paths[j].Backend)
+			}
+		}
+	}
+
+	var result []string
+	for _, backend := range backends {
+		if backend.Service != nil && !slices.Contains(result, backend.Service.Name) {
+			result = append(result, backend.Service.Name)
+		}
+	}
+	return result
+}
@@ -0,0 +1,51 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cilium provides the CiliumNode resource of Cilium
+package cilium
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// CiliumNode resource of the cilium.io group
+const (
+	Group    = "cilium.io"
+	Version  = "v2"
+	Resource = "ciliumnodes"
+)
+
+// GroupVersionResource of CiliumNodes for the dynamic client
+var GroupVersionResource = schema.GroupVersionResource{Group: Group, Version: Version, Resource: Resource}
+
+// Address is an address of the CiliumNode. Types are InternalIP, ExternalIP and CiliumInternalIP.
+type Address struct {
+	Type string `json:"type"`
+	IP   string `json:"ip"`
+}
+
+// Spec is the spec of the CiliumNode
+type Spec struct {
+	Addresses []Address `json:"addresses,omitempty"`
+}
+
+// CiliumNode is a node of Cilium. Its name is the name of the node. Only addresses are decoded.
+type CiliumNode struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              Spec `json:"spec"`
+}
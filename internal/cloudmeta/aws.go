@@ -0,0 +1,89 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudmeta
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultAWSEndpoint is the endpoint of the EC2 instance metadata service
+const DefaultAWSEndpoint = "http://169.254.169.254"
+
+// awsTokenTTL is the TTL in seconds of IMDSv2 session tokens. A token is requested for each query.
+const awsTokenTTL = 60
+
+// AWS queries the EC2 instance metadata service using IMDSv2 session tokens
+type AWS struct {
+	// Endpoint is the base URL of the metadata service. Empty value means DefaultAWSEndpoint.
+	Endpoint string
+	Client   *http.Client
+}
+
+// Name returns ProviderAWS
+func (a *AWS) Name() string {
+	return ProviderAWS
+}
+
+// PublicIPs returns the public IPv4 address and IPv6 addresses of the instance
+func (a *AWS) PublicIPs(ctx context.Context) ([]string, error) {
+	token, err := a.token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []string
+	for _, path := range []string{"/latest/meta-data/public-ipv4", "/latest/meta-data/ipv6"} {
+		request, requestErr := http.NewRequestWithContext(ctx, http.MethodGet, a.endpoint()+path, http.NoBody)
+		if requestErr != nil {
+			return nil, errors.Wrap(requestErr, "can't create request")
+		}
+		request.Header.Set("X-aws-ec2-metadata-token", token)
+		value, getErr := get(a.Client, request)
+		if getErr != nil {
+			return nil, getErr
+		}
+		result = appendIPs(result, value)
+	}
+	return result, nil
+}
+
+func (a *AWS) token(ctx context.Context) (string, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPut, a.endpoint()+"/latest/api/token", http.NoBody)
+	if err != nil {
+		return "", errors.Wrap(err, "can't create request")
+	}
+	request.Header.Set("X-aws-ec2-metadata-token-ttl-seconds", strconv.Itoa(awsTokenTTL))
+	token, err := get(a.Client, request)
+	if err != nil {
+		return "", errors.Wrap(err, "can't get IMDSv2 token")
+	}
+	if token == "" {
+		return "", errors.New("IMDSv2 token is not available")
+	}
+	return token, nil
+}
+
+func (a *AWS) endpoint() string {
+	if a.Endpoint == "" {
+		return DefaultAWSEndpoint
+	}
+	return a.Endpoint
+}
@@ -0,0 +1,112 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudmeta
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultAzureEndpoint is the endpoint of the Azure Instance Metadata Service
+const DefaultAzureEndpoint = "http://169.254.169.254"
+
+// azureAPIVersion is the version of the Azure Instance Metadata Service API
+const azureAPIVersion = "2021-02-01"
+
+type azureAddresses struct {
+	IPAddress []struct {
+		PublicIPAddress string `json:"publicIpAddress"`
+	} `json:"ipAddress"`
+}
+
+type azureNetwork struct {
+	Interface []struct {
+		IPv4 azureAddresses `json:"ipv4"`
+		IPv6 azureAddresses `json:"ipv6"`
+	} `json:"interface"`
+}
+
+type azureLoadBalancer struct {
+	LoadBalancer struct {
+		PublicIPAddresses []struct {
+			FrontendIPAddress string `json:"frontendIpAddress"`
+		} `json:"publicIpAddresses"`
+	} `json:"loadbalancer"`
+}
+
+// Azure queries the Azure Instance Metadata Service for public IPs of the VM and frontends of its public load balancer
+type Azure struct {
+	// Endpoint is the base URL of the metadata service. Empty value means DefaultAzureEndpoint.
+	Endpoint string
+	Client   *http.Client
+}
+
+// Name returns ProviderAzure
+func (a *Azure) Name() string {
+	return ProviderAzure
+}
+
+// PublicIPs returns public IPs of network interfaces of the VM followed by frontend IPs of the public load balancer
+// the VM is a backend of
+func (a *Azure) PublicIPs(ctx context.Context) ([]string, error) {
+	var network azureNetwork
+	if err := a.get(ctx, "/metadata/instance/network", &network); err != nil {
+		return nil, err
+	}
+	var loadBalancer azureLoadBalancer
+	if err := a.get(ctx, "/metadata/loadbalancer", &loadBalancer); err != nil {
+		return nil, err
+	}
+
+	var result []string
+	var add = func(ip string) {
+		if !slices.Contains(result, ip) {
+			result = appendIPs(result, ip)
+		}
+	}
+	for _, networkInterface := range network.Interface {
+		for _, address := range append(networkInterface.IPv4.IPAddress, networkInterface.IPv6.IPAddress...) {
+			add(address.PublicIPAddress)
+		}
+	}
+	for _, address := range loadBalancer.LoadBalancer.PublicIPAddresses {
+		add(address.FrontendIPAddress)
+	}
+	return result, nil
+}
+
+// get decodes the JSON document of the path. Missing documents are left empty.
+func (a *Azure) get(ctx context.Context, path string, v interface{}) error {
+	var endpoint = a.Endpoint
+	if endpoint == "" {
+		endpoint = DefaultAzureEndpoint
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+path+"?api-version="+azureAPIVersion, http.NoBody)
+	if err != nil {
+		return errors.Wrap(err, "can't create request")
+	}
+	request.Header.Set("Metadata", "true")
+	body, err := get(a.Client, request)
+	if err != nil || body == "" {
+		return err
+	}
+	return errors.Wrapf(json.Unmarshal([]byte(body), v), "can't parse %v", path)
+}
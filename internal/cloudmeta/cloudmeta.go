@@ -0,0 +1,113 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cloudmeta provides sources of public IPs of the instance from metadata services of cloud providers
+package cloudmeta
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Names of providers
+const (
+	ProviderAWS          = "aws"
+	ProviderGCP          = "gcp"
+	ProviderAzure        = "azure"
+	ProviderOpenStack    = "openstack"
+	ProviderHetzner      = "hetzner"
+	ProviderDigitalOcean = "digitalocean"
+	ProviderEquinix      = "equinix"
+)
+
+// DefaultTimeout is the timeout of requests to metadata services used if the client is not set
+const DefaultTimeout = 5 * time.Second
+
+// Provider returns public IPs of the instance the app runs on
+type Provider interface {
+	// Name returns the name of the provider
+	Name() string
+	// PublicIPs returns public IPv4 and IPv6 addresses of the instance. It returns no error and no addresses if the
+	// instance has no public addresses.
+	PublicIPs(ctx context.Context) ([]string, error)
+}
+
+// New returns the provider with the name. Empty endpoint means the default metadata endpoint of the provider.
+func New(name, endpoint string) (Provider, error) {
+	switch strings.ToLower(name) {
+	case ProviderAWS:
+		return &AWS{Endpoint: endpoint}, nil
+	case ProviderGCP:
+		return &GCP{Endpoint: endpoint}, nil
+	case ProviderAzure:
+		return &Azure{Endpoint: endpoint}, nil
+	case ProviderOpenStack:
+		return &OpenStack{Endpoint: endpoint}, nil
+	case ProviderHetzner:
+		return &Hetzner{Endpoint: endpoint}, nil
+	case ProviderDigitalOcean:
+		return &DigitalOcean{Endpoint: endpoint}, nil
+	case ProviderEquinix:
+		return &Equinix{Endpoint: endpoint}, nil
+	default:
+		return nil, errors.Errorf("unknown cloud metadata provider %q: expected one of %v, %v, %v, %v, %v, %v, %v", name,
+			ProviderAWS, ProviderGCP, ProviderAzure, ProviderOpenStack, ProviderHetzner, ProviderDigitalOcean, ProviderEquinix)
+	}
+}
+
+func client(c *http.Client) *http.Client {
+	if c == nil {
+		return &http.Client{Timeout: DefaultTimeout}
+	}
+	return c
+}
+
+// get sends the request and returns the trimmed body. It returns an empty body if the resource is not found.
+func get(c *http.Client, request *http.Request) (string, error) {
+	response, err := client(c).Do(request)
+	if err != nil {
+		return "", errors.Wrapf(err, "can't get %v", request.URL)
+	}
+	defer func() { _ = response.Body.Close() }()
+
+	if response.StatusCode == http.StatusNotFound {
+		return "", nil
+	}
+	body, err := io.ReadAll(io.LimitReader(response.Body, 64*1024))
+	if err != nil {
+		return "", errors.Wrapf(err, "can't read %v", request.URL)
+	}
+	if response.StatusCode != http.StatusOK {
+		return "", errors.Errorf("can't get %v: %v", request.URL, response.Status)
+	}
+	return strings.TrimSpace(string(body)), nil
+}
+
+// appendIPs appends valid IPs of whitespace separated values
+func appendIPs(result []string, values string) []string {
+	for _, value := range strings.Fields(values) {
+		if net.ParseIP(value) != nil {
+			result = append(result, value)
+		}
+	}
+	return result
+}
@@ -0,0 +1,183 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudmeta_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/cloudmeta"
+)
+
+func Test_AWS(t *testing.T) {
+	var mux = http.NewServeMux()
+	mux.HandleFunc("PUT /latest/api/token", func(w http.ResponseWriter, r *http.Request) {
+		require.NotEmpty(t, r.Header.Get("X-aws-ec2-metadata-token-ttl-seconds"))
+		_, _ = w.Write([]byte("token"))
+	})
+	var metadata = map[string]string{
+		"/latest/meta-data/public-ipv4": "203.0.113.5",
+		"/latest/meta-data/ipv6":        "2001:db8::5",
+	}
+	mux.HandleFunc("GET /latest/meta-data/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-aws-ec2-metadata-token") != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		value, ok := metadata[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(value + "\n"))
+	})
+	var server = httptest.NewServer(mux)
+	defer server.Close()
+
+	var provider = &cloudmeta.AWS{Endpoint: server.URL}
+	ips, err := provider.PublicIPs(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{"203.0.113.5", "2001:db8::5"}, ips)
+
+	delete(metadata, "/latest/meta-data/ipv6")
+	ips, err = provider.PublicIPs(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{"203.0.113.5"}, ips)
+}
+
+func Test_GCP(t *testing.T) {
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata-Flavor") != "Google" || r.URL.Path != "/computeMetadata/v1/instance/network-interfaces/" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`[{"ip":"10.128.0.2","accessConfigs":[{"externalIp":"203.0.113.5","type":"ONE_TO_ONE_NAT"}],` +
+			`"ipv6AccessConfigs":[{"externalIpv6":"2001:db8::5"}]},{"ip":"10.129.0.2","accessConfigs":[{"externalIp":""}]}]`))
+	}))
+	defer server.Close()
+
+	ips, err := (&cloudmeta.GCP{Endpoint: server.URL}).PublicIPs(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{"203.0.113.5", "2001:db8::5"}, ips)
+}
+
+func Test_Azure(t *testing.T) {
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata") != "true" || r.URL.Query().Get("api-version") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch r.URL.Path {
+		case "/metadata/instance/network":
+			_, _ = w.Write([]byte(`{"interface":[{"ipv4":{"ipAddress":[{"privateIpAddress":"10.0.0.4","publicIpAddress":"203.0.113.5"}]},` +
+				`"ipv6":{"ipAddress":[{"privateIpAddress":"fd00::4","publicIpAddress":""}]}}]}`))
+		case "/metadata/loadbalancer":
+			_, _ = w.Write([]byte(`{"loadbalancer":{"publicIpAddresses":[{"frontendIpAddress":"203.0.113.10","privateIpAddress":"10.0.0.4"},` +
+				`{"frontendIpAddress":"203.0.113.5","privateIpAddress":"10.0.0.4"}]}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	ips, err := (&cloudmeta.Azure{Endpoint: server.URL}).PublicIPs(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{"203.0.113.5", "203.0.113.10"}, ips)
+}
+
+func Test_OpenStack(t *testing.T) {
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/latest/meta-data/public-ipv4" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte("203.0.113.5"))
+	}))
+	defer server.Close()
+
+	ips, err := (&cloudmeta.OpenStack{Endpoint: server.URL}).PublicIPs(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{"203.0.113.5"}, ips)
+}
+
+func Test_Hetzner(t *testing.T) {
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/hetzner/v1/metadata/public-ipv4":
+			_, _ = w.Write([]byte("203.0.113.5"))
+		case "/hetzner/v1/metadata/network-config":
+			_, _ = w.Write([]byte("config:\n- mac_address: 96:00:00:00:00:01\n  name: eth0\n  subnets:\n" +
+				"  - ipv4: true\n    type: dhcp\n  - address: 2001:db8::1/64\n    gateway: fe80::1\n    ipv6: true\n    type: static\n" +
+				"  type: physical\nversion: 1\n"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	ips, err := (&cloudmeta.Hetzner{Endpoint: server.URL}).PublicIPs(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{"203.0.113.5", "2001:db8::1"}, ips)
+}
+
+func Test_DigitalOcean(t *testing.T) {
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/metadata/v1.json" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"droplet_id":1,"interfaces":{"public":[{"ipv4":{"ip_address":"203.0.113.5"},` +
+			`"ipv6":{"ip_address":"2001:DB8::5"}}],"private":[{"ipv4":{"ip_address":"10.116.0.2"}}]}}`))
+	}))
+	defer server.Close()
+
+	ips, err := (&cloudmeta.DigitalOcean{Endpoint: server.URL}).PublicIPs(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{"203.0.113.5", "2001:DB8::5"}, ips)
+}
+
+func Test_Equinix(t *testing.T) {
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/metadata" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"device","network":{"addresses":[` +
+			`{"address":"203.0.113.20","address_family":4,"cidr":32,"public":true,"management":false},` +
+			`{"address":"203.0.113.5","address_family":4,"cidr":31,"public":true,"management":true},` +
+			`{"address":"2001:db8::5","address_family":6,"cidr":127,"public":true,"management":true},` +
+			`{"address":"10.70.0.5","address_family":4,"cidr":31,"public":false,"management":true}]}}`))
+	}))
+	defer server.Close()
+
+	ips, err := (&cloudmeta.Equinix{Endpoint: server.URL}).PublicIPs(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{"203.0.113.5", "2001:db8::5", "203.0.113.20"}, ips)
+}
+
+func Test_New(t *testing.T) {
+	provider, err := cloudmeta.New("AWS", "")
+	require.NoError(t, err)
+	require.Equal(t, cloudmeta.ProviderAWS, provider.Name())
+
+	_, err = cloudmeta.New("unknown", "")
+	require.Error(t, err)
+}
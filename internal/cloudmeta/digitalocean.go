@@ -0,0 +1,81 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudmeta
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultDigitalOceanEndpoint is the endpoint of the DigitalOcean droplet metadata service
+const DefaultDigitalOceanEndpoint = "http://169.254.169.254"
+
+// digitalOceanMetadata is the part of the droplet metadata used by DigitalOcean
+type digitalOceanMetadata struct {
+	Interfaces struct {
+		Public []struct {
+			IPv4 struct {
+				IPAddress string `json:"ip_address"`
+			} `json:"ipv4"`
+			IPv6 struct {
+				IPAddress string `json:"ip_address"`
+			} `json:"ipv6"`
+		} `json:"public"`
+	} `json:"interfaces"`
+}
+
+// DigitalOcean queries the DigitalOcean droplet metadata service for addresses of public interfaces of the droplet
+type DigitalOcean struct {
+	// Endpoint is the base URL of the metadata service. Empty value means DefaultDigitalOceanEndpoint.
+	Endpoint string
+	Client   *http.Client
+}
+
+// Name returns ProviderDigitalOcean
+func (d *DigitalOcean) Name() string {
+	return ProviderDigitalOcean
+}
+
+// PublicIPs returns public IPv4 and IPv6 addresses of the droplet
+func (d *DigitalOcean) PublicIPs(ctx context.Context) ([]string, error) {
+	var endpoint = d.Endpoint
+	if endpoint == "" {
+		endpoint = DefaultDigitalOceanEndpoint
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"/metadata/v1.json", http.NoBody)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't create request")
+	}
+	body, err := get(d.Client, request)
+	if err != nil || body == "" {
+		return nil, err
+	}
+
+	var metadata digitalOceanMetadata
+	if err = json.Unmarshal([]byte(body), &metadata); err != nil {
+		return nil, errors.Wrap(err, "can't parse droplet metadata")
+	}
+	var result []string
+	for _, public := range metadata.Interfaces.Public {
+		result = appendIPs(result, public.IPv4.IPAddress)
+		result = appendIPs(result, public.IPv6.IPAddress)
+	}
+	return result, nil
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudmeta
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultEquinixEndpoint is the endpoint of the Equinix Metal metadata service
+const DefaultEquinixEndpoint = "https://metadata.platformequinix.com"
+
+// equinixAddress is the part of an address of the Equinix Metal metadata used by Equinix
+type equinixAddress struct {
+	Address    string `json:"address"`
+	Public     bool   `json:"public"`
+	Management bool   `json:"management"`
+}
+
+// Equinix queries the Equinix Metal metadata service for public addresses bound to the device
+type Equinix struct {
+	// Endpoint is the base URL of the metadata service. Empty value means DefaultEquinixEndpoint.
+	Endpoint string
+	Client   *http.Client
+}
+
+// Name returns ProviderEquinix
+func (e *Equinix) Name() string {
+	return ProviderEquinix
+}
+
+// PublicIPs returns public management addresses of the device followed by its public elastic IPs
+func (e *Equinix) PublicIPs(ctx context.Context) ([]string, error) {
+	var endpoint = e.Endpoint
+	if endpoint == "" {
+		endpoint = DefaultEquinixEndpoint
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"/metadata", http.NoBody)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't create request")
+	}
+	body, err := get(e.Client, request)
+	if err != nil || body == "" {
+		return nil, err
+	}
+
+	var metadata struct {
+		Network struct {
+			Addresses []equinixAddress `json:"addresses"`
+		} `json:"network"`
+	}
+	if err = json.Unmarshal([]byte(body), &metadata); err != nil {
+		return nil, errors.Wrap(err, "can't parse device metadata")
+	}
+	var result []string
+	for _, management := range []bool{true, false} {
+		for _, address := range metadata.Network.Addresses {
+			if address.Public && address.Management == management {
+				result = appendIPs(result, address.Address)
+			}
+		}
+	}
+	return result, nil
+}
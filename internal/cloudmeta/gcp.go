@@ -0,0 +1,82 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudmeta
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultGCPEndpoint is the endpoint of the GCE metadata server
+const DefaultGCPEndpoint = "http://metadata.google.internal"
+
+// gcpInterface is the part of a network interface of the GCE metadata used by GCP
+type gcpInterface struct {
+	AccessConfigs []struct {
+		ExternalIP string `json:"externalIp"`
+	} `json:"accessConfigs"`
+	IPv6AccessConfigs []struct {
+		ExternalIPv6 string `json:"externalIpv6"`
+	} `json:"ipv6AccessConfigs"`
+}
+
+// GCP queries the GCE metadata server for external IPs of access configs of network interfaces of the instance
+type GCP struct {
+	// Endpoint is the base URL of the metadata server. Empty value means DefaultGCPEndpoint.
+	Endpoint string
+	Client   *http.Client
+}
+
+// Name returns ProviderGCP
+func (g *GCP) Name() string {
+	return ProviderGCP
+}
+
+// PublicIPs returns external NAT IPs and external IPv6 addresses of network interfaces of the instance
+func (g *GCP) PublicIPs(ctx context.Context) ([]string, error) {
+	var endpoint = g.Endpoint
+	if endpoint == "" {
+		endpoint = DefaultGCPEndpoint
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"/computeMetadata/v1/instance/network-interfaces/?recursive=true", http.NoBody)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't create request")
+	}
+	request.Header.Set("Metadata-Flavor", "Google")
+	body, err := get(g.Client, request)
+	if err != nil || body == "" {
+		return nil, err
+	}
+
+	var interfaces []gcpInterface
+	if err = json.Unmarshal([]byte(body), &interfaces); err != nil {
+		return nil, errors.Wrap(err, "can't parse network interfaces")
+	}
+	var result []string
+	for i := range interfaces {
+		for _, config := range interfaces[i].AccessConfigs {
+			result = appendIPs(result, config.ExternalIP)
+		}
+		for _, config := range interfaces[i].IPv6AccessConfigs {
+			result = appendIPs(result, config.ExternalIPv6)
+		}
+	}
+	return result, nil
+}
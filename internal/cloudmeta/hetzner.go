@@ -0,0 +1,90 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudmeta
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// DefaultHetznerEndpoint is the endpoint of the Hetzner Cloud metadata service
+const DefaultHetznerEndpoint = "http://169.254.169.254"
+
+// hetznerNetworkConfig is the part of the cloud-init network config of the Hetzner Cloud metadata used by Hetzner
+type hetznerNetworkConfig struct {
+	Config []struct {
+		Subnets []struct {
+			Type    string `yaml:"type"`
+			Address string `yaml:"address"`
+		} `yaml:"subnets"`
+	} `yaml:"config"`
+}
+
+// Hetzner queries the Hetzner Cloud metadata service for the public IPv4 address of the server and static IPv6
+// addresses of its network config
+type Hetzner struct {
+	// Endpoint is the base URL of the metadata service. Empty value means DefaultHetznerEndpoint.
+	Endpoint string
+	Client   *http.Client
+}
+
+// Name returns ProviderHetzner
+func (h *Hetzner) Name() string {
+	return ProviderHetzner
+}
+
+// PublicIPs returns the public IPv4 address and public IPv6 addresses of the server
+func (h *Hetzner) PublicIPs(ctx context.Context) ([]string, error) {
+	ipv4, err := h.get(ctx, "/hetzner/v1/metadata/public-ipv4")
+	if err != nil {
+		return nil, err
+	}
+	var result = appendIPs(nil, ipv4)
+
+	body, err := h.get(ctx, "/hetzner/v1/metadata/network-config")
+	if err != nil || body == "" {
+		return result, err
+	}
+	var config hetznerNetworkConfig
+	if err = yaml.Unmarshal([]byte(body), &config); err != nil {
+		return nil, errors.Wrap(err, "can't parse network config")
+	}
+	for i := range config.Config {
+		for _, subnet := range config.Config[i].Subnets {
+			if ip, _, parseErr := net.ParseCIDR(subnet.Address); parseErr == nil && ip.To4() == nil && ip.IsGlobalUnicast() {
+				result = append(result, ip.String())
+			}
+		}
+	}
+	return result, nil
+}
+
+func (h *Hetzner) get(ctx context.Context, path string) (string, error) {
+	var endpoint = h.Endpoint
+	if endpoint == "" {
+		endpoint = DefaultHetznerEndpoint
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+path, http.NoBody)
+	if err != nil {
+		return "", errors.Wrap(err, "can't create request")
+	}
+	return get(h.Client, request)
+}
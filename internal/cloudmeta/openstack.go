@@ -0,0 +1,57 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cloudmeta
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultOpenStackEndpoint is the endpoint of the Nova metadata service
+const DefaultOpenStackEndpoint = "http://169.254.169.254"
+
+// OpenStack queries the EC2 compatible API of the Nova metadata service for the floating IP of the instance. The
+// OpenStack native API of the service has no floating IPs.
+type OpenStack struct {
+	// Endpoint is the base URL of the metadata service. Empty value means DefaultOpenStackEndpoint.
+	Endpoint string
+	Client   *http.Client
+}
+
+// Name returns ProviderOpenStack
+func (o *OpenStack) Name() string {
+	return ProviderOpenStack
+}
+
+// PublicIPs returns the floating IP associated with the instance
+func (o *OpenStack) PublicIPs(ctx context.Context) ([]string, error) {
+	var endpoint = o.Endpoint
+	if endpoint == "" {
+		endpoint = DefaultOpenStackEndpoint
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"/latest/meta-data/public-ipv4", http.NoBody)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't create request")
+	}
+	value, err := get(o.Client, request)
+	if err != nil {
+		return nil, err
+	}
+	return appendIPs(nil, value), nil
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package clusterapi provides the Machine resource of Cluster API
+package clusterapi
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Machine resource of the cluster.x-k8s.io group
+const (
+	Group    = "cluster.x-k8s.io"
+	Version  = "v1beta1"
+	Resource = "machines"
+)
+
+// GroupVersionResource of Machines for the dynamic client
+var GroupVersionResource = schema.GroupVersionResource{Group: Group, Version: Version, Resource: Resource}
+
+// Address is an address of the Machine. Types are the same as types of node addresses, e.g. InternalIP.
+type Address struct {
+	Type    string `json:"type"`
+	Address string `json:"address"`
+}
+
+// NodeRef references the node of the Machine
+type NodeRef struct {
+	Name string `json:"name"`
+}
+
+// Status is the status of the Machine
+type Status struct {
+	Addresses []Address `json:"addresses,omitempty"`
+	// NodeRef is nil until the node of the Machine registers
+	NodeRef *NodeRef `json:"nodeRef,omitempty"`
+}
+
+// Machine is a Machine of Cluster API. Only the status is decoded.
+type Machine struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Status            Status `json:"status"`
+}
+
+// NodeName returns the name of the node of the Machine or the name of the Machine if the node is not registered yet
+func (m *Machine) NodeName() string {
+	if m.Status.NodeRef != nil && m.Status.NodeRef.Name != "" {
+		return m.Status.NodeRef.Name
+	}
+	return m.Name
+}
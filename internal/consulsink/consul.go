@@ -0,0 +1,172 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package consulsink provides a target publishing translations of the map into Consul KV
+package consulsink
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+)
+
+// maxCASAttempts is the count of attempts to update a key modified concurrently
+const maxCASAttempts = 5
+
+// DefaultTimeout is the timeout of requests to Consul used if the client is not set
+const DefaultTimeout = 5 * time.Second
+
+// Target publishes each translation as the Prefix+From key with the To value. Keys are updated and deleted with
+// check-and-set, so concurrent modifications are not overwritten blindly.
+type Target struct {
+	// Address is the URL of the Consul agent, e.g. http://consul:8500
+	Address string
+	Prefix  string
+	Token   string
+	// Client is the client of Consul. A client with DefaultTimeout is used if it's nil.
+	Client *http.Client
+
+	published map[string]string
+}
+
+// Name returns the name of the target
+func (t *Target) Name() string {
+	return "consul"
+}
+
+// Write puts changed translations and deletes removed ones
+func (t *Target) Write(ctx context.Context, snapshot *mapipwriter.Snapshot) (bool, error) {
+	var next = make(map[string]string, len(snapshot.Entries))
+	for i := range snapshot.Entries {
+		next[t.Prefix+snapshot.Entries[i].From] = snapshot.Entries[i].To
+	}
+
+	var written bool
+	for key, value := range next {
+		if prev, ok := t.published[key]; ok && prev == value {
+			continue
+		}
+		if err := t.update(ctx, key, &value); err != nil {
+			return written, err
+		}
+		if t.published == nil {
+			t.published = make(map[string]string)
+		}
+		t.published[key] = value
+		written = true
+	}
+	for key := range t.published {
+		if _, ok := next[key]; ok {
+			continue
+		}
+		if err := t.update(ctx, key, nil); err != nil {
+			return written, err
+		}
+		delete(t.published, key)
+		written = true
+	}
+	return written, nil
+}
+
+// update puts the value into the key or deletes the key if value is nil using check-and-set with the current
+// modify index of the key
+func (t *Target) update(ctx context.Context, key string, value *string) error {
+	for attempt := 0; attempt < maxCASAttempts; attempt++ {
+		index, exists, err := t.modifyIndex(ctx, key)
+		if err != nil {
+			return err
+		}
+
+		var method, body = http.MethodPut, ""
+		if value == nil {
+			if !exists {
+				return nil
+			}
+			method = http.MethodDelete
+		} else {
+			body = *value
+		}
+
+		respBody, err := t.call(ctx, method, key, url.Values{"cas": {strconv.FormatUint(index, 10)}}, body)
+		if err != nil {
+			return err
+		}
+		if strings.TrimSpace(string(respBody)) == "true" {
+			return nil
+		}
+	}
+	return errors.Errorf("can't update consul key %v: it's concurrently modified", key)
+}
+
+func (t *Target) modifyIndex(ctx context.Context, key string) (index uint64, exists bool, err error) {
+	body, err := t.call(ctx, http.MethodGet, key, nil, "")
+	if err != nil || body == nil {
+		return 0, false, err
+	}
+	var pairs []struct {
+		ModifyIndex uint64
+	}
+	if err = json.Unmarshal(body, &pairs); err != nil {
+		return 0, false, errors.Wrapf(err, "can't decode consul key %v", key)
+	}
+	if len(pairs) == 0 {
+		return 0, false, nil
+	}
+	return pairs[0].ModifyIndex, true, nil
+}
+
+// call calls the KV endpoint of the key. It returns nil body if the key is not found.
+func (t *Target) call(ctx context.Context, method, key string, query url.Values, body string) ([]byte, error) {
+	var u = strings.TrimSuffix(t.Address, "/") + "/v1/kv/" + strings.TrimPrefix(key, "/")
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, strings.NewReader(body))
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't create request to consul key %v", key)
+	}
+	if t.Token != "" {
+		req.Header.Set("X-Consul-Token", t.Token)
+	}
+
+	var client = t.Client
+	if client == nil {
+		client = &http.Client{Timeout: DefaultTimeout}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't call consul key %v", key)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusNotFound && method == http.MethodGet {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("consul %v of key %v responded with %v", method, key, resp.Status)
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	return respBody, errors.Wrapf(err, "can't read consul response of key %v", key)
+}
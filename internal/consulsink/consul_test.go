@@ -0,0 +1,133 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package consulsink_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/consulsink"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+)
+
+type pair struct {
+	value string
+	index uint64
+}
+
+type fakeConsul struct {
+	mu    sync.Mutex
+	kv    map[string]pair
+	index uint64
+	// conflicts is the count of CAS requests to fail as if the key was modified concurrently
+	conflicts int
+}
+
+func (f *fakeConsul) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var key = strings.TrimPrefix(r.URL.Path, "/v1/kv/")
+	var current, exists = f.kv[key]
+
+	switch r.Method {
+	case http.MethodGet:
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode([]map[string]interface{}{{
+			"Key":         key,
+			"ModifyIndex": current.index,
+			"Value":       base64.StdEncoding.EncodeToString([]byte(current.value)),
+		}})
+		return
+	case http.MethodPut, http.MethodDelete:
+		cas, _ := strconv.ParseUint(r.URL.Query().Get("cas"), 10, 64)
+		if f.conflicts > 0 || cas != current.index {
+			f.conflicts--
+			_, _ = w.Write([]byte("false"))
+			return
+		}
+		if r.Method == http.MethodDelete {
+			delete(f.kv, key)
+		} else {
+			body, _ := io.ReadAll(r.Body)
+			f.index++
+			f.kv[key] = pair{value: string(body), index: f.index}
+		}
+		_, _ = w.Write([]byte("true"))
+	}
+}
+
+func (f *fakeConsul) values() map[string]string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var result = make(map[string]string)
+	for k, v := range f.kv {
+		result[k] = v.value
+	}
+	return result
+}
+
+func Test_ConsulTarget(t *testing.T) {
+	var consul = &fakeConsul{
+		kv:        map[string]pair{"nsm/map-ip/127.0.0.3": {value: "148.142.120.3", index: 1}},
+		index:     1,
+		conflicts: 1,
+	}
+	var server = httptest.NewServer(consul)
+	defer server.Close()
+
+	var target = &consulsink.Target{
+		Address: server.URL,
+		Prefix:  "nsm/map-ip/",
+	}
+
+	written, err := target.Write(context.Background(), &mapipwriter.Snapshot{
+		Entries: []mapipwriter.Entry{
+			{Translation: mapipwriter.Translation{From: "127.0.0.1", To: "148.142.120.1"}},
+			{Translation: mapipwriter.Translation{From: "127.0.0.3", To: "148.142.120.4"}},
+		},
+	})
+	require.NoError(t, err)
+	require.True(t, written)
+	require.Equal(t, map[string]string{
+		"nsm/map-ip/127.0.0.1": "148.142.120.1",
+		"nsm/map-ip/127.0.0.3": "148.142.120.4",
+	}, consul.values())
+
+	written, err = target.Write(context.Background(), &mapipwriter.Snapshot{
+		Entries: []mapipwriter.Entry{
+			{Translation: mapipwriter.Translation{From: "127.0.0.1", To: "148.142.120.1"}},
+		},
+	})
+	require.NoError(t, err)
+	require.True(t, written)
+	require.Equal(t, map[string]string{"nsm/map-ip/127.0.0.1": "148.142.120.1"}, consul.values())
+}
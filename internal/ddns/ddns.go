@@ -0,0 +1,241 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ddns provides a target publishing addresses of nodes as RFC 2136 dynamic DNS updates
+package ddns
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+	"net"
+	"slices"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/dns/dnsmessage"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+)
+
+const (
+	ioTimeout    = 5 * time.Second
+	opCodeUpdate = 5
+)
+
+// Target replaces A and AAAA records of hostnames of nodes in the Zone with their To addresses using dynamic DNS
+// updates over TCP. Records of removed hostnames are deleted. Translations of other sources are skipped.
+type Target struct {
+	// Server is host:port of the primary server of the zone
+	Server string
+	Zone   string
+	// Hostname is a template of the hostname of a node executed with mapipwriter.Entry. The zone is appended to
+	// relative hostnames.
+	Hostname *template.Template
+	TTL      uint32
+	// TSIG signs updates if it's not nil
+	TSIG *TSIG
+
+	// published maps published rrsets to their sorted addresses
+	published map[rrsetKey][]string
+}
+
+// Name returns the name of the target
+func (t *Target) Name() string {
+	return "ddns/" + t.Zone
+}
+
+type rrsetKey struct {
+	name  string
+	qtype dnsmessage.Type
+}
+
+func (t *Target) rrsets(snapshot *mapipwriter.Snapshot) (map[rrsetKey][]string, error) {
+	var zone = canonicalName(t.Zone)
+	var result = make(map[rrsetKey][]string)
+	for i := range snapshot.Entries {
+		var e = &snapshot.Entries[i]
+		var ip = net.ParseIP(e.To)
+		if e.Node == "" || ip == nil {
+			continue
+		}
+		var hostname bytes.Buffer
+		if err := t.Hostname.Execute(&hostname, e); err != nil {
+			return nil, errors.Wrapf(err, "can't make hostname of %v", e.Node)
+		}
+		var name = canonicalName(hostname.String())
+		if name != zone && !strings.HasSuffix(name, "."+zone) {
+			name = strings.TrimSuffix(name, ".") + "." + zone
+		}
+		var key = rrsetKey{name: name, qtype: dnsmessage.TypeA}
+		if ip.To4() == nil {
+			key.qtype = dnsmessage.TypeAAAA
+		}
+		if addr := ip.String(); !slices.Contains(result[key], addr) {
+			result[key] = append(result[key], addr)
+		}
+	}
+	for key := range result {
+		sort.Strings(result[key])
+	}
+	return result, nil
+}
+
+func sortedKeys(rrsets map[rrsetKey][]string) []rrsetKey {
+	var keys = make([]rrsetKey, 0, len(rrsets))
+	for key := range rrsets {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].name != keys[j].name {
+			return keys[i].name < keys[j].name
+		}
+		return keys[i].qtype < keys[j].qtype
+	})
+	return keys
+}
+
+// Write sends an update replacing changed rrsets and deleting removed ones. All rrsets are replaced on the first
+// write.
+func (t *Target) Write(ctx context.Context, snapshot *mapipwriter.Snapshot) (bool, error) {
+	next, err := t.rrsets(snapshot)
+	if err != nil {
+		return false, err
+	}
+
+	var update []dnsmessage.Resource
+	for _, key := range sortedKeys(next) {
+		if prev, ok := t.published[key]; ok && slices.Equal(prev, next[key]) {
+			continue
+		}
+		if update, err = t.appendReplace(update, key, next[key]); err != nil {
+			return false, err
+		}
+	}
+	for _, key := range sortedKeys(t.published) {
+		if _, ok := next[key]; ok {
+			continue
+		}
+		if update, err = t.appendReplace(update, key, nil); err != nil {
+			return false, err
+		}
+	}
+	if len(update) == 0 && t.published != nil {
+		return false, nil
+	}
+
+	if err = t.send(ctx, update); err != nil {
+		return false, err
+	}
+	t.published = next
+	return true, nil
+}
+
+// appendReplace appends deletion of the rrset and addition of the addresses
+func (t *Target) appendReplace(update []dnsmessage.Resource, key rrsetKey, addrs []string) ([]dnsmessage.Resource, error) {
+	name, err := dnsmessage.NewName(key.name)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid hostname %v", key.name)
+	}
+	update = append(update, dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Name: name, Type: key.qtype, Class: dnsmessage.ClassANY},
+		Body:   &dnsmessage.UnknownResource{Type: key.qtype},
+	})
+	for _, addr := range addrs {
+		var header = dnsmessage.ResourceHeader{Name: name, Type: key.qtype, Class: dnsmessage.ClassINET, TTL: t.TTL}
+		if key.qtype == dnsmessage.TypeA {
+			var a dnsmessage.AResource
+			copy(a.A[:], net.ParseIP(addr).To4())
+			update = append(update, dnsmessage.Resource{Header: header, Body: &a})
+		} else {
+			var aaaa dnsmessage.AAAAResource
+			copy(aaaa.AAAA[:], net.ParseIP(addr).To16())
+			update = append(update, dnsmessage.Resource{Header: header, Body: &aaaa})
+		}
+	}
+	return update, nil
+}
+
+func (t *Target) send(ctx context.Context, update []dnsmessage.Resource) error {
+	zone, err := dnsmessage.NewName(canonicalName(t.Zone))
+	if err != nil {
+		return errors.Wrapf(err, "invalid zone %v", t.Zone)
+	}
+	var id [2]byte
+	if _, err = rand.Read(id[:]); err != nil {
+		return errors.Wrap(err, "can't generate message id")
+	}
+	var message = dnsmessage.Message{
+		Header:      dnsmessage.Header{ID: binary.BigEndian.Uint16(id[:]), OpCode: opCodeUpdate},
+		Questions:   []dnsmessage.Question{{Name: zone, Type: dnsmessage.TypeSOA, Class: dnsmessage.ClassINET}},
+		Authorities: update,
+	}
+	packed, err := message.Pack()
+	if err != nil {
+		return errors.Wrap(err, "can't pack update")
+	}
+	if t.TSIG != nil {
+		if packed, err = t.TSIG.sign(packed, time.Now()); err != nil {
+			return errors.Wrap(err, "can't sign update")
+		}
+	}
+
+	response, err := t.exchange(ctx, packed)
+	if err != nil {
+		return err
+	}
+	var header dnsmessage.Parser
+	h, err := header.Start(response)
+	if err != nil {
+		return errors.Wrap(err, "can't parse update response")
+	}
+	if h.ID != message.ID {
+		return errors.Errorf("unexpected id of update response %v", h.ID)
+	}
+	if h.RCode != dnsmessage.RCodeSuccess {
+		return errors.Errorf("update of zone %v is refused: %v", t.Zone, h.RCode)
+	}
+	return nil
+}
+
+// exchange sends the message over TCP and returns the response
+func (t *Target) exchange(ctx context.Context, packed []byte) ([]byte, error) {
+	conn, err := new(net.Dialer).DialContext(ctx, "tcp", t.Server)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't connect to %v", t.Server)
+	}
+	defer func() { _ = conn.Close() }()
+	_ = conn.SetDeadline(time.Now().Add(ioTimeout))
+
+	var request = binary.BigEndian.AppendUint16(nil, uint16(len(packed)))
+	if _, err = conn.Write(append(request, packed...)); err != nil {
+		return nil, errors.Wrapf(err, "can't send update to %v", t.Server)
+	}
+	var length [2]byte
+	if _, err = io.ReadFull(conn, length[:]); err != nil {
+		return nil, errors.Wrapf(err, "can't read update response from %v", t.Server)
+	}
+	var response = make([]byte, binary.BigEndian.Uint16(length[:]))
+	if _, err = io.ReadFull(conn, response); err != nil {
+		return nil, errors.Wrapf(err, "can't read update response from %v", t.Server)
+	}
+	return response, nil
+}
@@ -0,0 +1,188 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddns_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"golang.org/x/net/dns/dnsmessage"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/ddns"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+)
+
+var secret = []byte("0123456789abcdef")
+
+func wireName(name string) []byte {
+	n, _ := dnsmessage.NewName(name)
+	b, _ := (&dnsmessage.Message{Questions: []dnsmessage.Question{{Name: n}}}).Pack()
+	return b[12 : len(b)-4]
+}
+
+// verifyTSIG checks the MAC of the last record of the update
+func verifyTSIG(t *testing.T, request []byte, update *dnsmessage.Message) {
+	var tsig = update.Additionals[len(update.Additionals)-1]
+	require.Equal(t, dnsmessage.Type(250), tsig.Header.Type)
+	require.Equal(t, "map-ip-key.", tsig.Header.Name.String())
+
+	var rdata = tsig.Body.(*dnsmessage.UnknownResource).Data
+	var algorithm = wireName("hmac-sha256.")
+	require.Equal(t, algorithm, rdata[:len(algorithm)])
+	var timers = rdata[len(algorithm) : len(algorithm)+8]
+	var macSize = int(binary.BigEndian.Uint16(rdata[len(algorithm)+8:]))
+	var mac = rdata[len(algorithm)+10 : len(algorithm)+10+macSize]
+
+	var unsigned = append([]byte(nil), request[:len(request)-len(wireName("map-ip-key."))-10-len(rdata)]...)
+	binary.BigEndian.PutUint16(unsigned[10:], binary.BigEndian.Uint16(unsigned[10:])-1)
+
+	var expected = hmac.New(sha256.New, secret)
+	expected.Write(unsigned)
+	expected.Write(wireName("map-ip-key."))
+	expected.Write([]byte{0, 255, 0, 0, 0, 0})
+	expected.Write(algorithm)
+	expected.Write(timers)
+	expected.Write([]byte{0, 0, 0, 0})
+	require.Equal(t, expected.Sum(nil), mac)
+}
+
+type fakeDNS struct {
+	mu      sync.Mutex
+	updates []dnsmessage.Message
+}
+
+func (f *fakeDNS) serve(t *testing.T, listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		var length [2]byte
+		_, _ = io.ReadFull(conn, length[:])
+		var request = make([]byte, binary.BigEndian.Uint16(length[:]))
+		_, _ = io.ReadFull(conn, request)
+
+		var update dnsmessage.Message
+		require.NoError(t, update.Unpack(request))
+		verifyTSIG(t, request, &update)
+		f.mu.Lock()
+		f.updates = append(f.updates, update)
+		f.mu.Unlock()
+
+		response, _ := (&dnsmessage.Message{Header: dnsmessage.Header{ID: update.ID, Response: true, OpCode: 5}}).Pack()
+		_, _ = conn.Write(append(binary.BigEndian.AppendUint16(nil, uint16(len(response))), response...))
+		_ = conn.Close()
+	}
+}
+
+type record struct {
+	name  string
+	class dnsmessage.Class
+	body  string
+}
+
+func records(update *dnsmessage.Message) []record {
+	var result []record
+	for _, r := range update.Authorities {
+		// bodies of deletions are empty, but the parser reads them as bodies of the type of the record
+		var body = r.Header.Type.String()
+		switch b := r.Body.(type) {
+		case *dnsmessage.AResource:
+			if r.Header.Class != dnsmessage.ClassANY {
+				body = net.IP(b.A[:]).String()
+			}
+		case *dnsmessage.AAAAResource:
+			if r.Header.Class != dnsmessage.ClassANY {
+				body = net.IP(b.AAAA[:]).String()
+			}
+		}
+		result = append(result, record{name: r.Header.Name.String(), class: r.Header.Class, body: body})
+	}
+	return result
+}
+
+func Test_DDNSTarget(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	var server = new(fakeDNS)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		server.serve(t, listener)
+	}()
+	defer func() {
+		_ = listener.Close()
+		wg.Wait()
+	}()
+
+	var target = &ddns.Target{
+		Server:   listener.Addr().String(),
+		Zone:     "example.com",
+		Hostname: template.Must(template.New("hostname").Parse("{{.Node}}.nodes")),
+		TTL:      60,
+		TSIG:     &ddns.TSIG{KeyName: "map-ip-key", Algorithm: "hmac-sha256", Secret: secret},
+	}
+
+	written, err := target.Write(context.Background(), &mapipwriter.Snapshot{
+		Generation: 1,
+		Entries: []mapipwriter.Entry{
+			{Translation: mapipwriter.Translation{From: "10.0.0.1", To: "203.0.113.1"}, Node: "node-1"},
+			{Translation: mapipwriter.Translation{From: "10.0.0.2", To: "203.0.113.2"}, Node: "node-2"},
+			{Translation: mapipwriter.Translation{From: "10.0.0.3", To: "203.0.113.3"}},
+		},
+	})
+	require.NoError(t, err)
+	require.True(t, written)
+
+	written, err = target.Write(context.Background(), &mapipwriter.Snapshot{
+		Generation: 2,
+		Entries: []mapipwriter.Entry{
+			{Translation: mapipwriter.Translation{From: "10.0.0.1", To: "203.0.113.1"}, Node: "node-1"},
+			{Translation: mapipwriter.Translation{From: "fd00::2", To: "2001:db8::2"}, Node: "node-2"},
+		},
+	})
+	require.NoError(t, err)
+	require.True(t, written)
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	require.Len(t, server.updates, 2)
+	require.Equal(t, "example.com.", server.updates[0].Questions[0].Name.String())
+	require.Equal(t, []record{
+		{name: "node-1.nodes.example.com.", class: dnsmessage.ClassANY, body: "TypeA"},
+		{name: "node-1.nodes.example.com.", class: dnsmessage.ClassINET, body: "203.0.113.1"},
+		{name: "node-2.nodes.example.com.", class: dnsmessage.ClassANY, body: "TypeA"},
+		{name: "node-2.nodes.example.com.", class: dnsmessage.ClassINET, body: "203.0.113.2"},
+	}, records(&server.updates[0]))
+	require.Equal(t, []record{
+		{name: "node-2.nodes.example.com.", class: dnsmessage.ClassANY, body: "TypeAAAA"},
+		{name: "node-2.nodes.example.com.", class: dnsmessage.ClassINET, body: "2001:db8::2"},
+		{name: "node-2.nodes.example.com.", class: dnsmessage.ClassANY, body: "TypeA"},
+	}, records(&server.updates[1]))
+}
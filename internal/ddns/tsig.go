@@ -0,0 +1,129 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ddns
+
+import (
+	"crypto/hmac"
+	"crypto/sha1" // #nosec G505 -- hmac-sha1 is a TSIG algorithm supported for old servers
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"hash"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const (
+	typeTSIG   dnsmessage.Type = 250
+	tsigFudge                  = 300
+	headerSize                 = 12
+)
+
+var tsigAlgorithms = map[string]func() hash.Hash{
+	"hmac-sha1.":   sha1.New,
+	"hmac-sha256.": sha256.New,
+	"hmac-sha512.": sha512.New,
+}
+
+// TSIG is a key signing updates as defined by RFC 8945
+type TSIG struct {
+	// KeyName is the name of the key on the server
+	KeyName string
+	// Algorithm is hmac-sha1, hmac-sha256 or hmac-sha512
+	Algorithm string
+	Secret    []byte
+}
+
+func canonicalName(name string) string {
+	return strings.ToLower(strings.TrimSuffix(name, ".")) + "."
+}
+
+// appendName appends the uncompressed wire format of the name
+func appendName(b []byte, name string) []byte {
+	for _, label := range strings.Split(strings.TrimSuffix(name, "."), ".") {
+		if label == "" {
+			continue
+		}
+		b = append(b, byte(len(label)))
+		b = append(b, label...)
+	}
+	return append(b, 0)
+}
+
+// Validate checks the algorithm of the key
+func (t *TSIG) Validate() error {
+	if _, ok := tsigAlgorithms[canonicalName(t.Algorithm)]; !ok {
+		return errors.Errorf("unsupported tsig algorithm %v", t.Algorithm)
+	}
+	if t.KeyName == "" || len(t.Secret) == 0 {
+		return errors.New("tsig key name and secret are required")
+	}
+	return nil
+}
+
+// sign appends the TSIG record to the packed message
+func (t *TSIG) sign(msg []byte, now time.Time) ([]byte, error) {
+	if err := t.Validate(); err != nil {
+		return nil, err
+	}
+	if len(msg) < headerSize {
+		return nil, errors.New("message is too short")
+	}
+	var keyName, algorithm = canonicalName(t.KeyName), canonicalName(t.Algorithm)
+
+	// time signed (48 bits), fudge, error and other len
+	var timers = make([]byte, 0, 12)
+	var signed = uint64(now.Unix())
+	timers = append(timers, byte(signed>>40), byte(signed>>32))
+	timers = binary.BigEndian.AppendUint32(timers, uint32(signed))
+	timers = binary.BigEndian.AppendUint16(timers, tsigFudge)
+
+	var mac = hmac.New(tsigAlgorithms[algorithm], t.Secret)
+	mac.Write(msg)
+	var variables = appendName(nil, keyName)
+	variables = binary.BigEndian.AppendUint16(variables, uint16(dnsmessage.ClassANY))
+	variables = binary.BigEndian.AppendUint32(variables, 0)
+	variables = appendName(variables, algorithm)
+	variables = append(variables, timers...)
+	variables = binary.BigEndian.AppendUint16(variables, 0) // error
+	variables = binary.BigEndian.AppendUint16(variables, 0) // other len
+	mac.Write(variables)
+	var sum = mac.Sum(nil)
+
+	var rdata = appendName(nil, algorithm)
+	rdata = append(rdata, timers...)
+	rdata = binary.BigEndian.AppendUint16(rdata, uint16(len(sum)))
+	rdata = append(rdata, sum...)
+	rdata = append(rdata, msg[0], msg[1])           // original id
+	rdata = binary.BigEndian.AppendUint16(rdata, 0) // error
+	rdata = binary.BigEndian.AppendUint16(rdata, 0) // other len
+
+	var result = append([]byte(nil), msg...)
+	result = appendName(result, keyName)
+	result = binary.BigEndian.AppendUint16(result, uint16(typeTSIG))
+	result = binary.BigEndian.AppendUint16(result, uint16(dnsmessage.ClassANY))
+	result = binary.BigEndian.AppendUint32(result, 0)
+	result = binary.BigEndian.AppendUint16(result, uint16(len(rdata)))
+	result = append(result, rdata...)
+
+	// increment ARCOUNT
+	binary.BigEndian.PutUint16(result[10:], binary.BigEndian.Uint16(result[10:])+1)
+	return result, nil
+}
@@ -0,0 +1,243 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dnsserver provides a tiny DNS responder answering queries from the map of ips
+package dnsserver
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/dns/dnsmessage"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+)
+
+const (
+	ttl           = 5
+	maxPacketSize = 512
+)
+
+type records struct {
+	generation uint64
+	byName     map[string][]net.IP
+	// byAddr maps To addresses to From addresses or names for PTR queries
+	byAddr map[string]string
+}
+
+// Server answers A/AAAA queries for From addresses or names of the map with all their To addresses of the queried
+// family and PTR queries for To
+// addresses with their From addresses or names. It implements mapipwriter.Target to receive the latest snapshot of
+// the map.
+type Server struct {
+	// Zone is an optional domain suffix stripped from queried names before looking up the map
+	Zone string
+
+	records atomic.Pointer[records]
+}
+
+// Name returns the name of the target
+func (s *Server) Name() string {
+	return "dns"
+}
+
+// Write replaces the records served by the server with the snapshot
+func (s *Server) Write(_ context.Context, snapshot *mapipwriter.Snapshot) (bool, error) {
+	if prev := s.records.Load(); prev != nil && prev.generation == snapshot.Generation {
+		return false, nil
+	}
+	var next = &records{
+		generation: snapshot.Generation,
+		byName:     make(map[string][]net.IP, len(snapshot.Entries)),
+		byAddr:     make(map[string]string, len(snapshot.Entries)),
+	}
+	for _, e := range snapshot.Entries {
+		if ip := net.ParseIP(e.To); ip != nil {
+			var name = strings.ToLower(e.From)
+			next.byName[name] = append(next.byName[name], ip)
+			if _, ok := next.byAddr[ip.String()]; !ok {
+				next.byAddr[ip.String()] = e.From
+			}
+		}
+	}
+	s.records.Store(next)
+	return true, nil
+}
+
+// ListenAndServe listens on the UDP address and serves queries until the context is done
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	conn, err := new(net.ListenConfig).ListenPacket(ctx, "udp", addr)
+	if err != nil {
+		return errors.Wrapf(err, "can't listen on %v", addr)
+	}
+	go func() {
+		<-ctx.Done()
+		_ = conn.Close()
+	}()
+	s.Serve(ctx, conn)
+	return nil
+}
+
+// Serve serves queries received by the connection until it's closed
+func (s *Server) Serve(ctx context.Context, conn net.PacketConn) {
+	var buf = make([]byte, maxPacketSize)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.FromContext(ctx).Errorf("dns server stopped: %v", err.Error())
+			}
+			return
+		}
+		response, err := s.handle(buf[:n])
+		if err != nil {
+			log.FromContext(ctx).Debugf("can't handle dns query from %v: %v", addr, err.Error())
+			continue
+		}
+		if _, err = conn.WriteTo(response, addr); err != nil {
+			log.FromContext(ctx).Warnf("can't write dns response to %v: %v", addr, err.Error())
+		}
+	}
+}
+
+func (s *Server) handle(query []byte) ([]byte, error) {
+	var request dnsmessage.Message
+	if err := request.Unpack(query); err != nil {
+		return nil, errors.Wrap(err, "can't parse query")
+	}
+
+	var response = dnsmessage.Message{
+		Header: dnsmessage.Header{
+			ID:                 request.ID,
+			Response:           true,
+			Authoritative:      true,
+			RecursionDesired:   request.RecursionDesired,
+			RecursionAvailable: false,
+		},
+		Questions: request.Questions,
+	}
+	if request.Response || request.OpCode != 0 || len(request.Questions) != 1 {
+		response.RCode = dnsmessage.RCodeNotImplemented
+		return response.Pack()
+	}
+
+	var q = request.Questions[0]
+	if q.Type == dnsmessage.TypePTR {
+		return s.handlePTR(&response, q)
+	}
+	ips, ok := s.lookup(q.Name.String())
+	if !ok {
+		response.RCode = dnsmessage.RCodeNameError
+		return response.Pack()
+	}
+
+	for _, ip := range ips {
+		var header = dnsmessage.ResourceHeader{Name: q.Name, Class: dnsmessage.ClassINET, TTL: ttl}
+		switch {
+		case q.Type == dnsmessage.TypeA && ip.To4() != nil:
+			header.Type = dnsmessage.TypeA
+			var a dnsmessage.AResource
+			copy(a.A[:], ip.To4())
+			response.Answers = append(response.Answers, dnsmessage.Resource{Header: header, Body: &a})
+		case q.Type == dnsmessage.TypeAAAA && ip.To4() == nil:
+			header.Type = dnsmessage.TypeAAAA
+			var aaaa dnsmessage.AAAAResource
+			copy(aaaa.AAAA[:], ip.To16())
+			response.Answers = append(response.Answers, dnsmessage.Resource{Header: header, Body: &aaaa})
+		}
+	}
+
+	return response.Pack()
+}
+
+func (s *Server) handlePTR(response *dnsmessage.Message, q dnsmessage.Question) ([]byte, error) {
+	var from string
+	if ip := reverseIP(q.Name.String()); ip != nil {
+		if current := s.records.Load(); current != nil {
+			from = current.byAddr[ip.String()]
+		}
+	}
+	if from == "" {
+		response.RCode = dnsmessage.RCodeNameError
+		return response.Pack()
+	}
+
+	var fqdn = strings.Trim(from, ".") + "."
+	if s.Zone != "" {
+		fqdn = strings.Trim(from, ".") + "." + strings.Trim(s.Zone, ".") + "."
+	}
+	name, err := dnsmessage.NewName(fqdn)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't make ptr name of %v", from)
+	}
+	response.Answers = append(response.Answers, dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Name: q.Name, Type: dnsmessage.TypePTR, Class: dnsmessage.ClassINET, TTL: ttl},
+		Body:   &dnsmessage.PTRResource{PTR: name},
+	})
+	return response.Pack()
+}
+
+// reverseIP parses names of in-addr.arpa and ip6.arpa zones
+func reverseIP(name string) net.IP {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	switch {
+	case strings.HasSuffix(name, ".in-addr.arpa"):
+		var labels = strings.Split(strings.TrimSuffix(name, ".in-addr.arpa"), ".")
+		if len(labels) != net.IPv4len {
+			return nil
+		}
+		for i, j := 0, len(labels)-1; i < j; i, j = i+1, j-1 {
+			labels[i], labels[j] = labels[j], labels[i]
+		}
+		return net.ParseIP(strings.Join(labels, ".")).To4()
+	case strings.HasSuffix(name, ".ip6.arpa"):
+		var nibbles = strings.Split(strings.TrimSuffix(name, ".ip6.arpa"), ".")
+		if len(nibbles) != 2*net.IPv6len {
+			return nil
+		}
+		var b strings.Builder
+		for i := len(nibbles) - 1; i >= 0; i-- {
+			if len(nibbles[i]) != 1 {
+				return nil
+			}
+			b.WriteString(nibbles[i])
+			if i%4 == 0 && i != 0 {
+				b.WriteByte(':')
+			}
+		}
+		return net.ParseIP(b.String())
+	}
+	return nil
+}
+
+func (s *Server) lookup(name string) ([]net.IP, bool) {
+	var current = s.records.Load()
+	if current == nil {
+		return nil, false
+	}
+
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	if s.Zone != "" {
+		name = strings.TrimSuffix(name, "."+strings.ToLower(strings.Trim(s.Zone, ".")))
+	}
+
+	ips, ok := current.byName[name]
+	return ips, ok
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dnsserver_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"golang.org/x/net/dns/dnsmessage"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/dnsserver"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+)
+
+func query(t *testing.T, addr net.Addr, name string, qtype dnsmessage.Type) *dnsmessage.Message {
+	var request = dnsmessage.Message{
+		Header: dnsmessage.Header{ID: 1},
+		Questions: []dnsmessage.Question{{
+			Name:  dnsmessage.MustNewName(name),
+			Type:  qtype,
+			Class: dnsmessage.ClassINET,
+		}},
+	}
+	b, err := request.Pack()
+	require.NoError(t, err)
+
+	conn, err := net.Dial("udp", addr.String())
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+	require.NoError(t, conn.SetDeadline(time.Now().Add(time.Second)))
+
+	_, err = conn.Write(b)
+	require.NoError(t, err)
+
+	var buf = make([]byte, 512)
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+
+	var response dnsmessage.Message
+	require.NoError(t, response.Unpack(buf[:n]))
+	return &response
+}
+
+func Test_DNSServer(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	var server = &dnsserver.Server{Zone: "ipmap.local"}
+	_, err = server.Write(ctx, &mapipwriter.Snapshot{
+		Generation: 1,
+		Entries: []mapipwriter.Entry{
+			{Translation: mapipwriter.Translation{From: "10.0.0.1", To: "203.0.113.1"}},
+			{Translation: mapipwriter.Translation{From: "node-2", To: "2001:db8::2"}},
+			{Translation: mapipwriter.Translation{From: "node-2", To: "203.0.113.3"}},
+			{Translation: mapipwriter.Translation{From: "node-2", To: "203.0.113.4"}},
+		},
+	})
+	require.NoError(t, err)
+
+	var done = make(chan struct{})
+	go func() {
+		defer close(done)
+		server.Serve(ctx, conn)
+	}()
+	defer func() {
+		cancel()
+		_ = conn.Close()
+		<-done
+	}()
+
+	response := query(t, conn.LocalAddr(), "10.0.0.1.ipmap.local.", dnsmessage.TypeA)
+	require.Equal(t, dnsmessage.RCodeSuccess, response.RCode)
+	require.Len(t, response.Answers, 1)
+	require.Equal(t, [4]byte{203, 0, 113, 1}, response.Answers[0].Body.(*dnsmessage.AResource).A)
+
+	response = query(t, conn.LocalAddr(), "node-2.", dnsmessage.TypeAAAA)
+	require.Len(t, response.Answers, 1)
+	require.Equal(t, net.ParseIP("2001:db8::2").To16(), net.IP(response.Answers[0].Body.(*dnsmessage.AAAAResource).AAAA[:]))
+
+	response = query(t, conn.LocalAddr(), "node-2.", dnsmessage.TypeA)
+	require.Len(t, response.Answers, 2)
+	require.Equal(t, [4]byte{203, 0, 113, 3}, response.Answers[0].Body.(*dnsmessage.AResource).A)
+	require.Equal(t, [4]byte{203, 0, 113, 4}, response.Answers[1].Body.(*dnsmessage.AResource).A)
+
+	response = query(t, conn.LocalAddr(), "unknown.", dnsmessage.TypeA)
+	require.Equal(t, dnsmessage.RCodeNameError, response.RCode)
+
+	response = query(t, conn.LocalAddr(), "1.113.0.203.in-addr.arpa.", dnsmessage.TypePTR)
+	require.Len(t, response.Answers, 1)
+	require.Equal(t, "10.0.0.1.ipmap.local.", response.Answers[0].Body.(*dnsmessage.PTRResource).PTR.String())
+
+	response = query(t, conn.LocalAddr(), "2.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa.", dnsmessage.TypePTR)
+	require.Len(t, response.Answers, 1)
+	require.Equal(t, "node-2.ipmap.local.", response.Answers[0].Body.(*dnsmessage.PTRResource).PTR.String())
+
+	response = query(t, conn.LocalAddr(), "2.113.0.203.in-addr.arpa.", dnsmessage.TypePTR)
+	require.Equal(t, dnsmessage.RCodeNameError, response.RCode)
+}
@@ -0,0 +1,166 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ebpfsink provides a target writing translations into a pinned eBPF hash map for XDP/TC programs
+package ebpfsink
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+
+	"github.com/cilium/ebpf"
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+)
+
+// Defaults of the map
+const (
+	DefaultPinPath    = "/sys/fs/bpf/nsm/map_ip"
+	DefaultMaxEntries = 65536
+)
+
+// Address is a key or a value of the map. IPv4 addresses are stored as IPv4-mapped IPv6 addresses.
+type Address [net.IPv6len]byte
+
+// Target writes single address translations into the BPF_MAP_TYPE_HASH map of Address to Address pinned at PinPath.
+// The map is created if it doesn't exist. Keys of changed translations are updated and keys of removed translations
+// are deleted. Keys which are not in the map of ips are deleted on the first write.
+type Target struct {
+	PinPath    string
+	MaxEntries uint32
+
+	bpfMap *ebpf.Map
+	// written maps keys of the map to their values
+	written map[Address]Address
+}
+
+// Name returns the name of the target
+func (t *Target) Name() string {
+	return "ebpf/" + t.pinPath()
+}
+
+func (t *Target) pinPath() string {
+	if t.PinPath == "" {
+		return DefaultPinPath
+	}
+	return t.PinPath
+}
+
+func (t *Target) spec() *ebpf.MapSpec {
+	var maxEntries = t.MaxEntries
+	if maxEntries == 0 {
+		maxEntries = DefaultMaxEntries
+	}
+	return &ebpf.MapSpec{
+		Name:       "nsm_map_ip",
+		Type:       ebpf.Hash,
+		KeySize:    net.IPv6len,
+		ValueSize:  net.IPv6len,
+		MaxEntries: maxEntries,
+	}
+}
+
+// open loads the pinned map or creates and pins a new one. Existing keys are loaded to delete stale ones.
+func (t *Target) open() error {
+	if t.bpfMap != nil {
+		return nil
+	}
+	var path = t.pinPath()
+	bpfMap, err := ebpf.LoadPinnedMap(path, nil)
+	switch {
+	case err == nil:
+		if compatErr := t.spec().Compatible(bpfMap); compatErr != nil {
+			_ = bpfMap.Close()
+			return errors.Wrapf(compatErr, "pinned map %v is incompatible", path)
+		}
+	case errors.Is(err, os.ErrNotExist):
+		if err = os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			return errors.Wrapf(err, "can't create directory of %v", path)
+		}
+		if bpfMap, err = ebpf.NewMap(t.spec()); err != nil {
+			return errors.Wrap(err, "can't create map")
+		}
+		if err = bpfMap.Pin(path); err != nil {
+			_ = bpfMap.Close()
+			return errors.Wrapf(err, "can't pin map to %v", path)
+		}
+	default:
+		return errors.Wrapf(err, "can't load pinned map %v", path)
+	}
+
+	t.written = make(map[Address]Address)
+	var key, value Address
+	var entries = bpfMap.Iterate()
+	for entries.Next(&key, &value) {
+		t.written[key] = value
+	}
+	if err = entries.Err(); err != nil {
+		_ = bpfMap.Close()
+		return errors.Wrapf(err, "can't read map %v", path)
+	}
+	t.bpfMap = bpfMap
+	return nil
+}
+
+// Close closes the map. The pinned map is kept for datapath programs.
+func (t *Target) Close() {
+	if t.bpfMap != nil {
+		_ = t.bpfMap.Close()
+		t.bpfMap = nil
+	}
+}
+
+// Write updates the map with the snapshot
+func (t *Target) Write(_ context.Context, snapshot *mapipwriter.Snapshot) (bool, error) {
+	if err := t.open(); err != nil {
+		return false, err
+	}
+
+	var next = make(map[Address]Address, len(snapshot.Entries))
+	for i := range snapshot.Entries {
+		var from, to = net.ParseIP(snapshot.Entries[i].From), net.ParseIP(snapshot.Entries[i].To)
+		if from == nil || to == nil {
+			continue
+		}
+		next[Address(from.To16())] = Address(to.To16())
+	}
+
+	var changed bool
+	for key := range t.written {
+		if _, ok := next[key]; ok {
+			continue
+		}
+		if err := t.bpfMap.Delete(key); err != nil && !errors.Is(err, ebpf.ErrKeyNotExist) {
+			return changed, errors.Wrapf(err, "can't delete %v from map", net.IP(key[:]))
+		}
+		delete(t.written, key)
+		changed = true
+	}
+	for key, value := range next {
+		if prev, ok := t.written[key]; ok && prev == value {
+			continue
+		}
+		if err := t.bpfMap.Put(key, value); err != nil {
+			return changed, errors.Wrapf(err, "can't put %v into map", net.IP(key[:]))
+		}
+		t.written[key] = value
+		changed = true
+	}
+	return changed, nil
+}
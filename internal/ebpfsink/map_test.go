@@ -0,0 +1,99 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ebpfsink_test
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cilium/ebpf"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/ebpfsink"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+)
+
+const bpffs = "/sys/fs/bpf"
+
+func address(s string) ebpfsink.Address {
+	return ebpfsink.Address(net.ParseIP(s).To16())
+}
+
+func readMap(t *testing.T, path string) map[ebpfsink.Address]ebpfsink.Address {
+	bpfMap, err := ebpf.LoadPinnedMap(path, nil)
+	require.NoError(t, err)
+	defer func() { _ = bpfMap.Close() }()
+
+	var result = make(map[ebpfsink.Address]ebpfsink.Address)
+	var key, value ebpfsink.Address
+	var entries = bpfMap.Iterate()
+	for entries.Next(&key, &value) {
+		result[key] = value
+	}
+	require.NoError(t, entries.Err())
+	return result
+}
+
+func Test_EBPFTarget(t *testing.T) {
+	var fs unix.Statfs_t
+	if err := unix.Statfs(bpffs, &fs); err != nil || fs.Type != unix.BPF_FS_MAGIC {
+		t.Skip("bpffs is not mounted on " + bpffs)
+	}
+	probe, err := ebpf.NewMap(&ebpf.MapSpec{Type: ebpf.Hash, KeySize: 4, ValueSize: 4, MaxEntries: 1})
+	if err != nil {
+		t.Skip("can't create eBPF maps: " + err.Error())
+	}
+	_ = probe.Close()
+
+	var dir = filepath.Join(bpffs, "nsm-test")
+	var path = filepath.Join(dir, t.Name())
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	var target = &ebpfsink.Target{PinPath: path, MaxEntries: 16}
+	var written bool
+	written, err = target.Write(context.Background(), &mapipwriter.Snapshot{
+		Entries: []mapipwriter.Entry{
+			{Translation: mapipwriter.Translation{From: "10.0.0.1", To: "203.0.113.1"}},
+			{Translation: mapipwriter.Translation{From: "10.0.0.2", To: "203.0.113.2"}},
+			{Translation: mapipwriter.Translation{From: "fd00::1", To: "2001:db8::1"}},
+		},
+	})
+	require.NoError(t, err)
+	require.True(t, written)
+	target.Close()
+
+	// the new target deletes keys which are not in the map of ips
+	target = &ebpfsink.Target{PinPath: path, MaxEntries: 16}
+	defer target.Close()
+	written, err = target.Write(context.Background(), &mapipwriter.Snapshot{
+		Entries: []mapipwriter.Entry{
+			{Translation: mapipwriter.Translation{From: "10.0.0.1", To: "203.0.113.3"}},
+			{Translation: mapipwriter.Translation{From: "fd00::1", To: "2001:db8::1"}},
+		},
+	})
+	require.NoError(t, err)
+	require.True(t, written)
+
+	require.Equal(t, map[ebpfsink.Address]ebpfsink.Address{
+		address("10.0.0.1"): address("203.0.113.3"),
+		address("fd00::1"):  address("2001:db8::1"),
+	}, readMap(t, path))
+}
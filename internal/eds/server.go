@@ -0,0 +1,179 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package eds provides an Envoy endpoint discovery service serving each From address of the map as a cluster with
+// the To addresses as its endpoints
+package eds
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"sync"
+
+	corev3 "github.com/envoyproxy/go-control-plane/envoy/config/core/v3"
+	endpointv3 "github.com/envoyproxy/go-control-plane/envoy/config/endpoint/v3"
+	endpointservicev3 "github.com/envoyproxy/go-control-plane/envoy/service/endpoint/v3"
+	"github.com/envoyproxy/go-control-plane/pkg/cache/types"
+	cachev3 "github.com/envoyproxy/go-control-plane/pkg/cache/v3"
+	resourcev3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+	serverv3 "github.com/envoyproxy/go-control-plane/pkg/server/v3"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/grpcserver"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+)
+
+// Server serves EDS from the snapshot cache of go-control-plane. Clusters are named by From addresses of the map and
+// have an endpoint with Port for each To address. Entries of CIDRs are skipped as they aren't valid cluster names and
+// socket addresses. All nodes are served the same clusters. It implements mapipwriter.Target to receive the snapshot.
+type Server struct {
+	// Port is the port of endpoints
+	Port uint32
+
+	once    sync.Once
+	cache   cachev3.SnapshotCache
+	mu      sync.Mutex
+	version string
+}
+
+// allNodes hashes every node to the same snapshot
+type allNodes struct{}
+
+func (allNodes) ID(*corev3.Node) string {
+	return ""
+}
+
+// Name returns the name of the target
+func (s *Server) Name() string {
+	return "eds"
+}
+
+func (s *Server) snapshots() cachev3.SnapshotCache {
+	s.once.Do(func() {
+		s.cache = cachev3.NewSnapshotCache(false, allNodes{}, nil)
+	})
+	return s.cache
+}
+
+// Write sets clusters of the snapshot to the cache. Returns false if they are not changed.
+func (s *Server) Write(ctx context.Context, snapshot *mapipwriter.Snapshot) (bool, error) {
+	var resources = s.clusterLoadAssignments(snapshot)
+	version, err := hash(resources)
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if version == s.version {
+		return false, nil
+	}
+	cacheSnapshot, err := cachev3.NewSnapshot(version, map[resourcev3.Type][]types.Resource{resourcev3.EndpointType: resources})
+	if err != nil {
+		return false, errors.Wrap(err, "can't create eds snapshot")
+	}
+	if err = s.snapshots().SetSnapshot(ctx, allNodes{}.ID(nil), cacheSnapshot); err != nil {
+		return false, errors.Wrap(err, "can't set eds snapshot")
+	}
+	s.version = version
+	return true, nil
+}
+
+func (s *Server) clusterLoadAssignments(snapshot *mapipwriter.Snapshot) []types.Resource {
+	var resources []types.Resource
+	var clusters = make(map[string]*endpointv3.LocalityLbEndpoints)
+	var added = make(map[mapipwriter.Translation]bool)
+	for i := range snapshot.Entries {
+		var e = &snapshot.Entries[i]
+		if net.ParseIP(e.From) == nil || net.ParseIP(e.To) == nil || added[e.Translation] {
+			continue
+		}
+		added[e.Translation] = true
+		endpoints, ok := clusters[e.From]
+		if !ok {
+			endpoints = new(endpointv3.LocalityLbEndpoints)
+			clusters[e.From] = endpoints
+			resources = append(resources, &endpointv3.ClusterLoadAssignment{
+				ClusterName: e.From,
+				Endpoints:   []*endpointv3.LocalityLbEndpoints{endpoints},
+			})
+		}
+		endpoints.LbEndpoints = append(endpoints.LbEndpoints, s.lbEndpoint(e.To))
+	}
+	return resources
+}
+
+func (s *Server) lbEndpoint(address string) *endpointv3.LbEndpoint {
+	var socketAddress = &corev3.SocketAddress{Address: address}
+	if s.Port != 0 {
+		socketAddress.PortSpecifier = &corev3.SocketAddress_PortValue{PortValue: s.Port}
+	}
+	return &endpointv3.LbEndpoint{
+		HostIdentifier: &endpointv3.LbEndpoint_Endpoint{
+			Endpoint: &endpointv3.Endpoint{
+				Address: &corev3.Address{Address: &corev3.Address_SocketAddress{SocketAddress: socketAddress}},
+			},
+		},
+	}
+}
+
+// hash returns the version of resources. It depends only on the content, so versions acknowledged by Envoy before
+// a restart of the application match the same clusters.
+func hash(resources []types.Resource) (string, error) {
+	var b []byte
+	var options = proto.MarshalOptions{Deterministic: true}
+	for _, resource := range resources {
+		message, err := options.Marshal(resource)
+		if err != nil {
+			return "", errors.Wrap(err, "can't marshal eds resource")
+		}
+		b = protowire.AppendBytes(b, message)
+	}
+	var sum = sha256.Sum256(b)
+	return hex.EncodeToString(sum[:8]), nil
+}
+
+// Register registers the service on the grpc server. Streams are served until the context is done.
+func (s *Server) Register(ctx context.Context, server *grpc.Server) {
+	endpointservicev3.RegisterEndpointDiscoveryServiceServer(server, serverv3.NewServer(ctx, s.snapshots(), nil))
+}
+
+// ListenAndServe listens on unix:///path or tcp://host:port and serves the service until the context is done
+func (s *Server) ListenAndServe(ctx context.Context, listenOn string) error {
+	listener, err := grpcserver.Listen(ctx, listenOn)
+	if err != nil {
+		return err
+	}
+
+	var server = grpc.NewServer()
+	s.Register(ctx, server)
+	go func() {
+		<-ctx.Done()
+		server.Stop()
+	}()
+	log.FromContext(ctx).Infof("eds server is listening on %v", listenOn)
+	if err = server.Serve(listener); err != nil {
+		return errors.Wrapf(err, "can't serve eds on %v", listenOn)
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eds_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	endpointv3 "github.com/envoyproxy/go-control-plane/envoy/config/endpoint/v3"
+	discoveryv3 "github.com/envoyproxy/go-control-plane/envoy/service/discovery/v3"
+	endpointservicev3 "github.com/envoyproxy/go-control-plane/envoy/service/endpoint/v3"
+	resourcev3 "github.com/envoyproxy/go-control-plane/pkg/resource/v3"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/eds"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+)
+
+// serve serves the server on a unix socket and returns a client of it and a function stopping both
+func serve(ctx context.Context, t *testing.T, server *eds.Server) (endpointservicev3.EndpointDiscoveryServiceClient, func()) {
+	var socket = filepath.Join(t.TempDir(), "eds.sock")
+	serverCtx, stopServer := context.WithCancel(ctx)
+	var done = make(chan error, 1)
+	go func() { done <- server.ListenAndServe(serverCtx, "unix://"+socket) }()
+
+	cc, err := grpc.DialContext(ctx, "unix://"+socket, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	return endpointservicev3.NewEndpointDiscoveryServiceClient(cc), func() {
+		_ = cc.Close()
+		stopServer()
+		<-done
+	}
+}
+
+// clusters returns addresses of endpoints of each cluster of the response
+func clusters(t *testing.T, response *discoveryv3.DiscoveryResponse, port uint32) map[string][]string {
+	var result = make(map[string][]string)
+	for _, resource := range response.GetResources() {
+		var assignment endpointv3.ClusterLoadAssignment
+		require.NoError(t, resource.UnmarshalTo(&assignment))
+		for _, locality := range assignment.GetEndpoints() {
+			for _, endpoint := range locality.GetLbEndpoints() {
+				var address = endpoint.GetEndpoint().GetAddress().GetSocketAddress()
+				require.Equal(t, port, address.GetPortValue())
+				result[assignment.GetClusterName()] = append(result[assignment.GetClusterName()], address.GetAddress())
+			}
+		}
+	}
+	return result
+}
+
+func Test_EDSServer(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var server = &eds.Server{Port: 8443}
+	_, err := server.Write(ctx, &mapipwriter.Snapshot{
+		Generation: 1,
+		Entries: []mapipwriter.Entry{
+			{Translation: mapipwriter.Translation{From: "10.0.0.1", To: "203.0.113.1"}},
+			{Translation: mapipwriter.Translation{From: "10.0.0.2", To: "203.0.113.2"}},
+		},
+	})
+	require.NoError(t, err)
+
+	client, stop := serve(ctx, t, server)
+	defer stop()
+
+	streamCtx, stopStream := context.WithCancel(ctx)
+	defer stopStream()
+	stream, err := client.StreamEndpoints(streamCtx, grpc.WaitForReady(true))
+	require.NoError(t, err)
+
+	require.NoError(t, stream.Send(&discoveryv3.DiscoveryRequest{
+		TypeUrl:       resourcev3.EndpointType,
+		ResourceNames: []string{"10.0.0.1"},
+	}))
+	response, err := stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, resourcev3.EndpointType, response.GetTypeUrl())
+	require.Equal(t, map[string][]string{"10.0.0.1": {"203.0.113.1"}}, clusters(t, response, 8443))
+
+	// ACK
+	require.NoError(t, stream.Send(&discoveryv3.DiscoveryRequest{
+		VersionInfo:   response.GetVersionInfo(),
+		TypeUrl:       resourcev3.EndpointType,
+		ResourceNames: []string{"10.0.0.1"},
+		ResponseNonce: response.GetNonce(),
+	}))
+
+	written, err := server.Write(ctx, &mapipwriter.Snapshot{
+		Generation: 2,
+		Entries: []mapipwriter.Entry{
+			{Translation: mapipwriter.Translation{From: "10.0.0.1", To: "203.0.113.1"}},
+			{Translation: mapipwriter.Translation{From: "10.0.0.2", To: "203.0.113.2"}},
+		},
+	})
+	require.NoError(t, err)
+	require.False(t, written)
+
+	_, err = server.Write(ctx, &mapipwriter.Snapshot{
+		Generation: 3,
+		Entries: []mapipwriter.Entry{
+			{Translation: mapipwriter.Translation{From: "10.0.0.1", To: "203.0.113.3"}},
+		},
+	})
+	require.NoError(t, err)
+
+	var version = response.GetVersionInfo()
+	response, err = stream.Recv()
+	require.NoError(t, err)
+	require.NotEqual(t, version, response.GetVersionInfo())
+	require.Equal(t, map[string][]string{"10.0.0.1": {"203.0.113.3"}}, clusters(t, response, 8443))
+}
+
+func Test_EDSServerAllClusters(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var server = &eds.Server{Port: 8443}
+	_, err := server.Write(ctx, &mapipwriter.Snapshot{
+		Generation: 1,
+		Entries: []mapipwriter.Entry{
+			{Translation: mapipwriter.Translation{From: "10.0.0.1", To: "203.0.113.1"}},
+			{Translation: mapipwriter.Translation{From: "10.0.1.0/24", To: "203.0.113.0/24"}},
+			{Translation: mapipwriter.Translation{From: "10.0.0.2", To: "203.0.113.2"}},
+			{Translation: mapipwriter.Translation{From: "10.0.0.1", To: "2001:db8::1"}},
+		},
+	})
+	require.NoError(t, err)
+
+	client, stop := serve(ctx, t, server)
+	defer stop()
+
+	streamCtx, stopStream := context.WithCancel(ctx)
+	defer stopStream()
+	stream, err := client.StreamEndpoints(streamCtx, grpc.WaitForReady(true))
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&discoveryv3.DiscoveryRequest{TypeUrl: resourcev3.EndpointType}))
+
+	response, err := stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, map[string][]string{
+		"10.0.0.1": {"203.0.113.1", "2001:db8::1"},
+		"10.0.0.2": {"203.0.113.2"},
+	}, clusters(t, response, 8443))
+}
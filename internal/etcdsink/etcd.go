@@ -0,0 +1,219 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package etcdsink provides a target publishing translations of the map as etcd keys via the etcd v3 JSON gateway
+package etcdsink
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+)
+
+// DefaultTimeout is the timeout of requests to etcd used if the client is not set
+const DefaultTimeout = 5 * time.Second
+
+// Target publishes translations of each From address as the Prefix+From key with the JSON list of To addresses, e.g.
+// ["203.0.113.1","2001:db8::1"]. Only changed keys are written.
+// If LeaseTTL is set then the keys are attached to a lease, so they expire if the application stops refreshing it
+// with KeepAlive.
+type Target struct {
+	// Endpoint is the URL of etcd, e.g. http://etcd:2379
+	Endpoint string
+	Prefix   string
+	LeaseTTL time.Duration
+	// Client is the client of etcd. A client with DefaultTimeout is used if it's nil.
+	Client *http.Client
+
+	mu        sync.Mutex
+	leaseID   int64
+	published map[string]string
+}
+
+// Name returns the name of the target
+func (t *Target) Name() string {
+	return "etcd"
+}
+
+// Write puts changed translations and deletes removed ones
+func (t *Target) Write(ctx context.Context, snapshot *mapipwriter.Snapshot) (bool, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.LeaseTTL > 0 && t.leaseID == 0 {
+		if err := t.grantLease(ctx); err != nil {
+			return false, err
+		}
+	}
+
+	var targets = make(map[string][]string, len(snapshot.Entries))
+	for i := range snapshot.Entries {
+		var key = t.Prefix + snapshot.Entries[i].From
+		targets[key] = append(targets[key], snapshot.Entries[i].To)
+	}
+	var next = make(map[string]string, len(targets))
+	for key, to := range targets {
+		value, err := json.Marshal(to)
+		if err != nil {
+			return false, errors.Wrapf(err, "can't marshal value of %v", key)
+		}
+		next[key] = string(value)
+	}
+
+	var written bool
+	for key, value := range next {
+		if prev, ok := t.published[key]; ok && prev == value {
+			continue
+		}
+		if err := t.put(ctx, key, value); err != nil {
+			return written, err
+		}
+		t.setPublished(key, value)
+		written = true
+	}
+	for key := range t.published {
+		if _, ok := next[key]; ok {
+			continue
+		}
+		if err := t.call(ctx, "/v3/kv/deleterange", map[string]string{"key": encode(key)}, nil); err != nil {
+			return written, err
+		}
+		delete(t.published, key)
+		written = true
+	}
+	return written, nil
+}
+
+// KeepAlive refreshes the lease every third of LeaseTTL until the context is done. If the lease is expired then
+// the keys are republished with a new lease on the next Write.
+func (t *Target) KeepAlive(ctx context.Context) {
+	if t.LeaseTTL <= 0 {
+		return
+	}
+	var ticker = time.NewTicker(t.LeaseTTL / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := t.keepAliveOnce(ctx); err != nil {
+				log.FromContext(ctx).Warnf("can't refresh etcd lease: %v", err.Error())
+			}
+		}
+	}
+}
+
+func (t *Target) keepAliveOnce(ctx context.Context) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.leaseID == 0 {
+		return nil
+	}
+	var response struct {
+		Result struct {
+			TTL string `json:"TTL"`
+		} `json:"result"`
+	}
+	if err := t.call(ctx, "/v3/lease/keepalive", map[string]string{"ID": strconv.FormatInt(t.leaseID, 10)}, &response); err != nil {
+		return err
+	}
+	if ttl, _ := strconv.ParseInt(response.Result.TTL, 10, 64); ttl <= 0 {
+		// the lease is expired with all its keys
+		t.leaseID = 0
+		t.published = nil
+		return errors.New("lease is expired, keys will be republished")
+	}
+	return nil
+}
+
+func (t *Target) grantLease(ctx context.Context) error {
+	var response struct {
+		ID string `json:"ID"`
+	}
+	var request = map[string]string{"TTL": strconv.FormatInt(int64(t.LeaseTTL/time.Second), 10)}
+	if err := t.call(ctx, "/v3/lease/grant", request, &response); err != nil {
+		return err
+	}
+	id, err := strconv.ParseInt(response.ID, 10, 64)
+	if err != nil || id == 0 {
+		return errors.Errorf("invalid lease id %q", response.ID)
+	}
+	t.leaseID = id
+	t.published = nil
+	return nil
+}
+
+func (t *Target) put(ctx context.Context, key, value string) error {
+	var request = map[string]string{"key": encode(key), "value": encode(value)}
+	if t.leaseID != 0 {
+		request["lease"] = strconv.FormatInt(t.leaseID, 10)
+	}
+	return t.call(ctx, "/v3/kv/put", request, nil)
+}
+
+func (t *Target) setPublished(key, value string) {
+	if t.published == nil {
+		t.published = make(map[string]string)
+	}
+	t.published[key] = value
+}
+
+func (t *Target) call(ctx context.Context, path string, request, response interface{}) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return errors.Wrapf(err, "can't marshal request to %v", path)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(t.Endpoint, "/")+path, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrapf(err, "can't create request to %v", path)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var client = t.Client
+	if client == nil {
+		client = &http.Client{Timeout: DefaultTimeout}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "can't call etcd %v", path)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.Errorf("etcd %v responded with %v", path, resp.Status)
+	}
+	if response == nil {
+		return nil
+	}
+	return errors.Wrapf(json.NewDecoder(resp.Body).Decode(response), "can't decode response of etcd %v", path)
+}
+
+func encode(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
@@ -0,0 +1,108 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdsink_test
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/etcdsink"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+)
+
+type fakeEtcd struct {
+	mu   sync.Mutex
+	kv   map[string]string
+	puts int
+}
+
+func (f *fakeEtcd) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var request map[string]string
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	var decode = func(s string) string {
+		b, _ := base64.StdEncoding.DecodeString(s)
+		return string(b)
+	}
+
+	switch r.URL.Path {
+	case "/v3/lease/grant":
+		_, _ = w.Write([]byte(`{"ID":"42","TTL":"` + request["TTL"] + `"}`))
+	case "/v3/kv/put":
+		if request["lease"] != "42" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.kv[decode(request["key"])] = decode(request["value"])
+		f.puts++
+		_, _ = w.Write([]byte(`{}`))
+	case "/v3/kv/deleterange":
+		delete(f.kv, decode(request["key"]))
+		_, _ = w.Write([]byte(`{}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func Test_EtcdTarget(t *testing.T) {
+	var etcd = &fakeEtcd{kv: make(map[string]string)}
+	var server = httptest.NewServer(etcd)
+	defer server.Close()
+
+	var target = &etcdsink.Target{
+		Endpoint: server.URL,
+		Prefix:   "/nsm/map-ip/",
+		LeaseTTL: time.Minute,
+	}
+
+	written, err := target.Write(context.Background(), &mapipwriter.Snapshot{
+		Entries: []mapipwriter.Entry{
+			{Translation: mapipwriter.Translation{From: "127.0.0.1", To: "148.142.120.1"}},
+			{Translation: mapipwriter.Translation{From: "127.0.0.2", To: "148.142.120.2"}},
+			{Translation: mapipwriter.Translation{From: "127.0.0.2", To: "2001:db8::2"}},
+		},
+	})
+	require.NoError(t, err)
+	require.True(t, written)
+	require.Equal(t, map[string]string{
+		"/nsm/map-ip/127.0.0.1": `["148.142.120.1"]`,
+		"/nsm/map-ip/127.0.0.2": `["148.142.120.2","2001:db8::2"]`,
+	}, etcd.kv)
+
+	written, err = target.Write(context.Background(), &mapipwriter.Snapshot{
+		Entries: []mapipwriter.Entry{
+			{Translation: mapipwriter.Translation{From: "127.0.0.1", To: "148.142.120.1"}},
+		},
+	})
+	require.NoError(t, err)
+	require.True(t, written)
+	require.Equal(t, map[string]string{"/nsm/map-ip/127.0.0.1": `["148.142.120.1"]`}, etcd.kv)
+	require.Equal(t, 2, etcd.puts)
+}
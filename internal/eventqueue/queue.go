@@ -0,0 +1,98 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package eventqueue provides an unbounded queue of events between sources and the writer of the map
+package eventqueue
+
+import (
+	"context"
+	"sync/atomic"
+
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/metrics"
+)
+
+// Queue is an unbounded FIFO queue of events between sources and the writer, so sources never block on a slow
+// writer. A pending event is replaced by a later event of the same translation keeping its position in the queue.
+type Queue struct {
+	in      chan mapipwriter.Event
+	order   []mapipwriter.Translation
+	pending map[mapipwriter.Translation]mapipwriter.Event
+	depth   atomic.Int64
+}
+
+// Start forwards events from the returned queue into out until ctx is done. Events pending in the queue when ctx is
+// done are sent into out until stop is closed, so the writer can flush them, and out is closed then.
+func Start(ctx context.Context, out chan<- mapipwriter.Event, stop <-chan struct{}) *Queue {
+	var q = &Queue{
+		in:      make(chan mapipwriter.Event),
+		pending: make(map[mapipwriter.Translation]mapipwriter.Event),
+	}
+	var coalesced = metrics.Int64Counter(ctx, metrics.EventsCoalescedName,
+		metric.WithDescription("count of events replaced in the queue by later events of the same translation"))
+
+	go func() {
+		defer close(out)
+		for {
+			var next mapipwriter.Event
+			var sendCh chan<- mapipwriter.Event
+			if len(q.order) > 0 {
+				next, sendCh = q.pending[q.order[0]], out
+			}
+			select {
+			case <-ctx.Done():
+				q.drain(out, stop)
+				return
+			case event := <-q.in:
+				if _, ok := q.pending[event.Translation]; ok {
+					coalesced.Add(ctx, 1)
+				} else {
+					q.order = append(q.order, event.Translation)
+				}
+				q.pending[event.Translation] = event
+			case sendCh <- next:
+				delete(q.pending, q.order[0])
+				q.order = q.order[1:]
+			}
+			q.depth.Store(int64(len(q.order)))
+		}
+	}()
+	return q
+}
+
+// drain sends pending events into out in order until stop is closed
+func (q *Queue) drain(out chan<- mapipwriter.Event, stop <-chan struct{}) {
+	for _, translation := range q.order {
+		select {
+		case out <- q.pending[translation]:
+		case <-stop:
+			return
+		}
+		q.depth.Add(-1)
+	}
+}
+
+// In returns the channel of events of sources
+func (q *Queue) In() chan<- mapipwriter.Event {
+	return q.in
+}
+
+// Len returns the count of events pending in the queue
+func (q *Queue) Len() int {
+	return int(q.depth.Load())
+}
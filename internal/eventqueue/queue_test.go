@@ -0,0 +1,61 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eventqueue_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/eventqueue"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+)
+
+func event(eventType watch.EventType, from, to string) mapipwriter.Event {
+	return mapipwriter.Event{Type: eventType, Translation: mapipwriter.Translation{From: from, To: to}}
+}
+
+func Test_QueueDrainsOnShutdown(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var out = make(chan mapipwriter.Event)
+	var queue = eventqueue.Start(ctx, out, make(chan struct{}))
+
+	queue.In() <- event(watch.Added, "10.0.0.1", "203.0.113.1")
+	queue.In() <- event(watch.Added, "10.0.0.2", "203.0.113.2")
+	queue.In() <- event(watch.Deleted, "10.0.0.1", "203.0.113.1")
+	require.Eventually(t, func() bool { return queue.Len() == 2 }, time.Second, time.Millisecond*10)
+
+	cancel()
+
+	var drained []mapipwriter.Event
+	for e := range out {
+		drained = append(drained, e)
+	}
+	require.Equal(t, []mapipwriter.Event{
+		event(watch.Deleted, "10.0.0.1", "203.0.113.1"),
+		event(watch.Added, "10.0.0.2", "203.0.113.2"),
+	}, drained)
+	require.Equal(t, 0, queue.Len())
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gateway_test
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/gateway"
+)
+
+func Test_NATPMP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+
+	var requests = make(chan []byte, 2)
+	go func() {
+		var buf = make([]byte, 16)
+		for {
+			n, from, readErr := conn.ReadFrom(buf)
+			if readErr != nil {
+				return
+			}
+			var response = []byte{0, 128 + buf[1], 0, 0, 0, 0, 0, 1}
+			if buf[1] == 0 {
+				response = append(response, 203, 0, 113, 5)
+			} else {
+				requests <- append([]byte(nil), buf[:n]...)
+				response = append(response, buf[4], buf[5], buf[4], buf[5], buf[8], buf[9], buf[10], buf[11])
+			}
+			_, _ = conn.WriteTo(response, from)
+		}
+	}()
+
+	client, err := gateway.New(gateway.NATPMP, conn.LocalAddr().String())
+	require.NoError(t, err)
+
+	ips, err := client.PublicIPs(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{"203.0.113.5"}, ips)
+
+	port, err := client.MapPort(context.Background(), gateway.PortMapping{Protocol: "tcp", Port: 443}, time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, uint16(443), port)
+
+	var request = <-requests
+	require.Equal(t, byte(2), request[1])
+	require.Equal(t, uint16(443), binary.BigEndian.Uint16(request[4:]))
+	require.Equal(t, uint32(3600), binary.BigEndian.Uint32(request[8:]))
+}
+
+func Test_UPnP(t *testing.T) {
+	var mu sync.Mutex
+	var actions []string
+	var mux = http.NewServeMux()
+	mux.HandleFunc("GET /rootDesc.xml", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`<?xml version="1.0"?><root xmlns="urn:schemas-upnp-org:device-1-0"><device>
+<deviceType>urn:schemas-upnp-org:device:InternetGatewayDevice:1</deviceType><deviceList><device>
+<deviceType>urn:schemas-upnp-org:device:WANDevice:1</deviceType><deviceList><device>
+<serviceList><service><serviceType>urn:schemas-upnp-org:service:WANIPConnection:1</serviceType>
+<controlURL>/ctl/IPConn</controlURL></service></serviceList></device></deviceList></device></deviceList></device></root>`))
+	})
+	mux.HandleFunc("POST /ctl/IPConn", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		actions = append(actions, r.Header.Get("SOAPAction"))
+		mu.Unlock()
+		if strings.Contains(string(body), "GetExternalIPAddress") {
+			_, _ = w.Write([]byte(`<?xml version="1.0"?><s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/"><s:Body>` +
+				`<u:GetExternalIPAddressResponse xmlns:u="urn:schemas-upnp-org:service:WANIPConnection:1">` +
+				`<NewExternalIPAddress>203.0.113.5</NewExternalIPAddress></u:GetExternalIPAddressResponse></s:Body></s:Envelope>`))
+			return
+		}
+		if strings.Contains(string(body), "AddPortMapping") && !strings.Contains(string(body), "<NewInternalClient>127.0.0.1</NewInternalClient>") {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	})
+	var server = httptest.NewServer(mux)
+	defer server.Close()
+
+	ssdp, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer func() { _ = ssdp.Close() }()
+	go func() {
+		var buf = make([]byte, 2048)
+		for {
+			n, from, readErr := ssdp.ReadFrom(buf)
+			if readErr != nil {
+				return
+			}
+			if strings.HasPrefix(string(buf[:n]), "M-SEARCH") {
+				_, _ = ssdp.WriteTo([]byte("HTTP/1.1 200 OK\r\nST: urn:schemas-upnp-org:device:InternetGatewayDevice:1\r\n"+
+					"LOCATION: "+server.URL+"/rootDesc.xml\r\n\r\n"), from)
+			}
+		}
+	}()
+
+	client, err := gateway.New(gateway.UPnP, ssdp.LocalAddr().String())
+	require.NoError(t, err)
+
+	ips, err := client.PublicIPs(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{"203.0.113.5"}, ips)
+
+	_, err = client.MapPort(context.Background(), gateway.PortMapping{Protocol: "udp", Port: 51820}, time.Hour)
+	require.NoError(t, err)
+	_, err = client.MapPort(context.Background(), gateway.PortMapping{Protocol: "udp", Port: 51820}, 0)
+	require.NoError(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, []string{
+		`"urn:schemas-upnp-org:service:WANIPConnection:1#GetExternalIPAddress"`,
+		`"urn:schemas-upnp-org:service:WANIPConnection:1#AddPortMapping"`,
+		`"urn:schemas-upnp-org:service:WANIPConnection:1#DeletePortMapping"`,
+	}, actions)
+}
+
+func Test_ParsePortMappings(t *testing.T) {
+	mappings, err := gateway.ParsePortMappings("udp:51820, TCP:443")
+	require.NoError(t, err)
+	require.Equal(t, []gateway.PortMapping{{Protocol: "udp", Port: 51820}, {Protocol: "tcp", Port: 443}}, mappings)
+
+	_, err = gateway.ParsePortMappings("sctp:1")
+	require.Error(t, err)
+}
@@ -0,0 +1,210 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gateway provides discovery of the WAN address of the home gateway using NAT-PMP (RFC 6886) or UPnP IGD and
+// requests of port mappings
+package gateway
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Names of protocols
+const (
+	NATPMP = "natpmp"
+	UPnP   = "upnp"
+)
+
+// DefaultTimeout is the timeout of a request to the gateway if it's not set
+const DefaultTimeout = 3 * time.Second
+
+// natPMPPort is the port of NAT-PMP servers
+const natPMPPort = 5351
+
+// NAT-PMP opcodes
+const (
+	opExternalAddress = 0
+	opMapUDP          = 1
+	opMapTCP          = 2
+	opResponse        = 128
+)
+
+// PortMapping is a mapping of a port of the gateway to the same port of the host
+type PortMapping struct {
+	// Protocol is "udp" or "tcp"
+	Protocol string
+	Port     uint16
+}
+
+// ParsePortMappings parses a comma separated list of protocol:port, e.g. "udp:51820,tcp:443"
+func ParsePortMappings(list string) ([]PortMapping, error) {
+	var result []PortMapping
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		protocol, port, ok := strings.Cut(strings.ToLower(item), ":")
+		if !ok || (protocol != "udp" && protocol != "tcp") {
+			return nil, errors.Errorf("invalid port mapping %q: expected udp:port or tcp:port", item)
+		}
+		number, err := net.LookupPort(protocol, port)
+		if err != nil || number == 0 {
+			return nil, errors.Errorf("invalid port mapping %q: invalid port", item)
+		}
+		// #nosec G115 -- LookupPort returns 16 bit port numbers
+		result = append(result, PortMapping{Protocol: protocol, Port: uint16(number)})
+	}
+	return result, nil
+}
+
+// NATPMPClient queries the gateway using NAT-PMP
+type NATPMPClient struct {
+	// Gateway is the address of the gateway. Invalid value means the default gateway of the host.
+	Gateway netip.Addr
+	// Port of the gateway. Zero value means 5351.
+	Port uint16
+	// Timeout of each request. Zero value means DefaultTimeout.
+	Timeout time.Duration
+}
+
+// Name returns NATPMP
+func (c *NATPMPClient) Name() string {
+	return NATPMP
+}
+
+// PublicIPs returns the external address of the gateway
+func (c *NATPMPClient) PublicIPs(ctx context.Context) ([]string, error) {
+	response, err := c.request(ctx, []byte{0, opExternalAddress}, 12)
+	if err != nil {
+		return nil, err
+	}
+	var ip = netip.AddrFrom4([4]byte(response[8:12]))
+	if ip.IsUnspecified() {
+		return nil, nil
+	}
+	return []string{ip.String()}, nil
+}
+
+// MapPort requests the mapping for the lifetime. Zero lifetime deletes the mapping. It returns the external port
+// assigned by the gateway.
+func (c *NATPMPClient) MapPort(ctx context.Context, mapping PortMapping, lifetime time.Duration) (uint16, error) {
+	var request = make([]byte, 12)
+	request[1] = opMapUDP
+	if mapping.Protocol == "tcp" {
+		request[1] = opMapTCP
+	}
+	binary.BigEndian.PutUint16(request[4:], mapping.Port)
+	if lifetime > 0 {
+		binary.BigEndian.PutUint16(request[6:], mapping.Port)
+	}
+	// #nosec G115 -- lifetimes are small positive durations
+	binary.BigEndian.PutUint32(request[8:], uint32(lifetime/time.Second))
+	response, err := c.request(ctx, request, 16)
+	if err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint16(response[10:]), nil
+}
+
+// request sends the request and returns the response of at least minLen bytes with the successful result code
+func (c *NATPMPClient) request(ctx context.Context, request []byte, minLen int) ([]byte, error) {
+	var gateway = c.Gateway
+	if !gateway.IsValid() {
+		var err error
+		if gateway, err = DefaultGateway(); err != nil {
+			return nil, err
+		}
+	}
+	var port = c.Port
+	if port == 0 {
+		port = natPMPPort
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", netip.AddrPortFrom(gateway, port).String())
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't dial NAT-PMP gateway %v", gateway)
+	}
+	defer func() { _ = conn.Close() }()
+
+	var timeout = c.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	if err = conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, errors.Wrap(err, "can't set deadline")
+	}
+	if _, err = conn.Write(request); err != nil {
+		return nil, errors.Wrapf(err, "can't send NAT-PMP request to %v", gateway)
+	}
+
+	var buf = make([]byte, 16)
+	for {
+		n, readErr := conn.Read(buf)
+		if readErr != nil {
+			return nil, errors.Wrapf(readErr, "no NAT-PMP response from %v", gateway)
+		}
+		if n < minLen || buf[0] != 0 || buf[1] != opResponse+request[1] {
+			continue
+		}
+		if code := binary.BigEndian.Uint16(buf[2:]); code != 0 {
+			return nil, errors.Errorf("NAT-PMP gateway %v returned result code %v", gateway, code)
+		}
+		return buf[:n], nil
+	}
+}
+
+// Client discovers the WAN address of the gateway and requests port mappings
+type Client interface {
+	// Name returns the protocol
+	Name() string
+	// PublicIPs returns the WAN address of the gateway
+	PublicIPs(ctx context.Context) ([]string, error)
+	// MapPort requests the mapping of the port for the lifetime. Zero lifetime deletes the mapping.
+	MapPort(ctx context.Context, mapping PortMapping, lifetime time.Duration) (uint16, error)
+}
+
+// New returns the client of the protocol. gateway is the address of the NAT-PMP gateway or the SSDP address of UPnP
+// discovery. Empty value means the default gateway or DefaultSSDPAddress.
+func New(protocol, gateway string) (Client, error) {
+	switch strings.ToLower(protocol) {
+	case NATPMP:
+		var client = new(NATPMPClient)
+		if gateway != "" {
+			addrPort, err := netip.ParseAddrPort(gateway)
+			if err != nil {
+				addr, addrErr := netip.ParseAddr(gateway)
+				if addrErr != nil {
+					return nil, errors.Errorf("invalid NAT-PMP gateway %q", gateway)
+				}
+				addrPort = netip.AddrPortFrom(addr, 0)
+			}
+			client.Gateway, client.Port = addrPort.Addr(), addrPort.Port()
+		}
+		return client, nil
+	case UPnP:
+		return &UPnPClient{SearchAddress: gateway}, nil
+	default:
+		return nil, errors.Errorf("unknown gateway protocol %q: expected %v or %v", protocol, NATPMP, UPnP)
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gateway
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/hex"
+	"net/netip"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// routeFile is the IPv4 routing table of the host
+var routeFile = "/proc/net/route"
+
+// DefaultGateway returns the gateway of the IPv4 default route of the host
+func DefaultGateway() (netip.Addr, error) {
+	file, err := os.Open(routeFile)
+	if err != nil {
+		return netip.Addr{}, errors.Wrapf(err, "can't open %v", routeFile)
+	}
+	defer func() { _ = file.Close() }()
+
+	var scanner = bufio.NewScanner(file)
+	for scanner.Scan() {
+		// Iface Destination Gateway Flags ...; addresses are little endian hex
+		var fields = strings.Fields(scanner.Text())
+		if len(fields) < 3 || fields[1] != "00000000" {
+			continue
+		}
+		raw, decodeErr := hex.DecodeString(fields[2])
+		if decodeErr != nil || len(raw) != 4 {
+			continue
+		}
+		var ip [4]byte
+		binary.BigEndian.PutUint32(ip[:], binary.LittleEndian.Uint32(raw))
+		if gateway := netip.AddrFrom4(ip); !gateway.IsUnspecified() {
+			return gateway, nil
+		}
+	}
+	return netip.Addr{}, errors.New("no default gateway")
+}
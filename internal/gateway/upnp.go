@@ -0,0 +1,308 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package gateway
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"html"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultSSDPAddress is the multicast address of SSDP discovery of UPnP devices
+const DefaultSSDPAddress = "239.255.255.250:1900"
+
+// PortMappingDescription is the description of port mappings requested using UPnP
+const PortMappingDescription = "nsm-map-ip"
+
+const igdSearchTarget = "urn:schemas-upnp-org:device:InternetGatewayDevice:1"
+
+// upnpDevice is the part of the UPnP device description used by UPnPClient
+type upnpDevice struct {
+	URLBase string `xml:"URLBase"`
+	Device  struct {
+		services
+	} `xml:"device"`
+}
+
+type services struct {
+	ServiceList []struct {
+		ServiceType string `xml:"serviceType"`
+		ControlURL  string `xml:"controlURL"`
+	} `xml:"serviceList>service"`
+	DeviceList []struct {
+		services
+	} `xml:"deviceList>device"`
+}
+
+// find returns the first WANIPConnection or WANPPPConnection service of the device tree
+func (s *services) find() (serviceType, controlURL string) {
+	for _, service := range s.ServiceList {
+		if strings.Contains(service.ServiceType, ":WANIPConnection:") || strings.Contains(service.ServiceType, ":WANPPPConnection:") {
+			return service.ServiceType, service.ControlURL
+		}
+	}
+	for i := range s.DeviceList {
+		if serviceType, controlURL = s.DeviceList[i].find(); serviceType != "" {
+			return serviceType, controlURL
+		}
+	}
+	return "", ""
+}
+
+// UPnPClient queries the Internet Gateway Device discovered with SSDP
+type UPnPClient struct {
+	// SearchAddress is the address SSDP M-SEARCH requests are sent to. Empty value means DefaultSSDPAddress.
+	SearchAddress string
+	// Timeout of the discovery and of each request. Zero value means DefaultTimeout.
+	Timeout time.Duration
+
+	serviceType, controlURL string
+	localIP                 string
+}
+
+// Name returns UPnP
+func (c *UPnPClient) Name() string {
+	return UPnP
+}
+
+// PublicIPs returns the external IP address of the WAN connection of the gateway
+func (c *UPnPClient) PublicIPs(ctx context.Context) ([]string, error) {
+	values, err := c.call(ctx, "GetExternalIPAddress")
+	if err != nil {
+		return nil, err
+	}
+	var ip = values["NewExternalIPAddress"]
+	if net.ParseIP(ip) == nil {
+		return nil, nil
+	}
+	return []string{ip}, nil
+}
+
+// MapPort adds the port mapping to the host for the lifetime. Zero lifetime deletes the mapping.
+func (c *UPnPClient) MapPort(ctx context.Context, mapping PortMapping, lifetime time.Duration) (uint16, error) {
+	var port = strconv.Itoa(int(mapping.Port))
+	var protocol = strings.ToUpper(mapping.Protocol)
+	if lifetime == 0 {
+		_, err := c.call(ctx, "DeletePortMapping", "NewRemoteHost", "", "NewExternalPort", port, "NewProtocol", protocol)
+		return mapping.Port, err
+	}
+	if err := c.discover(ctx); err != nil {
+		return 0, err
+	}
+	_, err := c.call(ctx, "AddPortMapping",
+		"NewRemoteHost", "",
+		"NewExternalPort", port,
+		"NewProtocol", protocol,
+		"NewInternalPort", port,
+		"NewInternalClient", c.localIP,
+		"NewEnabled", "1",
+		"NewPortMappingDescription", PortMappingDescription,
+		"NewLeaseDuration", strconv.Itoa(int(lifetime/time.Second)))
+	return mapping.Port, err
+}
+
+func (c *UPnPClient) timeout() time.Duration {
+	if c.Timeout == 0 {
+		return DefaultTimeout
+	}
+	return c.Timeout
+}
+
+// discover finds the control URL of the WAN connection service of the gateway. The result is cached.
+func (c *UPnPClient) discover(ctx context.Context) error {
+	if c.controlURL != "" {
+		return nil
+	}
+	location, err := c.search(ctx)
+	if err != nil {
+		return err
+	}
+
+	body, err := c.do(ctx, http.MethodGet, location, nil, nil)
+	if err != nil {
+		return err
+	}
+	var device upnpDevice
+	if err = xml.Unmarshal(body, &device); err != nil {
+		return errors.Wrapf(err, "can't parse UPnP device description %v", location)
+	}
+	serviceType, controlURL := device.Device.find()
+	if serviceType == "" {
+		return errors.Errorf("UPnP device %v has no WAN connection service", location)
+	}
+
+	base, err := url.Parse(location)
+	if err != nil {
+		return errors.Wrapf(err, "invalid location %v", location)
+	}
+	if device.URLBase != "" {
+		if base, err = url.Parse(device.URLBase); err != nil {
+			return errors.Wrapf(err, "invalid URLBase %v", device.URLBase)
+		}
+	}
+	control, err := base.Parse(controlURL)
+	if err != nil {
+		return errors.Wrapf(err, "invalid control URL %v", controlURL)
+	}
+
+	// the address of the host in the network of the gateway is the address of the connection to it
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", control.Host)
+	if err != nil {
+		return errors.Wrapf(err, "can't find the local address to %v", control.Host)
+	}
+	c.localIP = conn.LocalAddr().(*net.UDPAddr).IP.String()
+	_ = conn.Close()
+
+	c.serviceType, c.controlURL = serviceType, control.String()
+	return nil
+}
+
+// search sends the SSDP M-SEARCH request and returns the location of the first responding gateway
+func (c *UPnPClient) search(ctx context.Context) (string, error) {
+	var searchAddress = c.SearchAddress
+	if searchAddress == "" {
+		searchAddress = DefaultSSDPAddress
+	}
+	to, err := netip.ParseAddrPort(searchAddress)
+	if err != nil {
+		return "", errors.Wrapf(err, "invalid SSDP address %v", searchAddress)
+	}
+
+	var listenConfig net.ListenConfig
+	conn, err := listenConfig.ListenPacket(ctx, "udp4", ":0")
+	if err != nil {
+		return "", errors.Wrap(err, "can't listen UDP")
+	}
+	defer func() { _ = conn.Close() }()
+	if err = conn.SetDeadline(time.Now().Add(c.timeout())); err != nil {
+		return "", errors.Wrap(err, "can't set deadline")
+	}
+
+	var request = "M-SEARCH * HTTP/1.1\r\n" +
+		"HOST: " + DefaultSSDPAddress + "\r\n" +
+		"MAN: \"ssdp:discover\"\r\n" +
+		"MX: 2\r\n" +
+		"ST: " + igdSearchTarget + "\r\n\r\n"
+	if _, err = conn.WriteTo([]byte(request), net.UDPAddrFromAddrPort(to)); err != nil {
+		return "", errors.Wrap(err, "can't send SSDP request")
+	}
+
+	var buf = make([]byte, 2048)
+	for {
+		n, _, readErr := conn.ReadFrom(buf)
+		if readErr != nil {
+			return "", errors.Wrap(readErr, "no UPnP gateway responded")
+		}
+		response, parseErr := http.ReadResponse(bufio.NewReader(bytes.NewReader(buf[:n])), nil)
+		if parseErr != nil {
+			continue
+		}
+		_ = response.Body.Close()
+		if location := response.Header.Get("Location"); location != "" {
+			return location, nil
+		}
+	}
+}
+
+// call invokes the SOAP action of the WAN connection service with pairs of argument names and values and returns
+// values of the response
+func (c *UPnPClient) call(ctx context.Context, action string, args ...string) (map[string]string, error) {
+	if err := c.discover(ctx); err != nil {
+		return nil, err
+	}
+
+	var envelope strings.Builder
+	envelope.WriteString(`<?xml version="1.0"?><s:Envelope xmlns:s="http://schemas.xmlsoap.org/soap/envelope/" ` +
+		`s:encodingStyle="http://schemas.xmlsoap.org/soap/encoding/"><s:Body>`)
+	envelope.WriteString(`<u:` + action + ` xmlns:u="` + html.EscapeString(c.serviceType) + `">`)
+	for i := 0; i+1 < len(args); i += 2 {
+		envelope.WriteString("<" + args[i] + ">" + html.EscapeString(args[i+1]) + "</" + args[i] + ">")
+	}
+	envelope.WriteString(`</u:` + action + `></s:Body></s:Envelope>`)
+
+	body, err := c.do(ctx, http.MethodPost, c.controlURL, strings.NewReader(envelope.String()), http.Header{
+		"Content-Type": {`text/xml; charset="utf-8"`},
+		"Soapaction":   {`"` + c.serviceType + "#" + action + `"`},
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "UPnP action %v failed", action)
+	}
+	return leafValues(body), nil
+}
+
+func (c *UPnPClient) do(ctx context.Context, method, target string, body io.Reader, header http.Header) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout())
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, method, target, body)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't create request")
+	}
+	for key, values := range header {
+		request.Header[key] = values
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't %v %v", method, target)
+	}
+	defer func() { _ = response.Body.Close() }()
+	data, err := io.ReadAll(io.LimitReader(response.Body, 1<<20))
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't read %v", target)
+	}
+	if response.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("%v %v: %v", method, target, response.Status)
+	}
+	return data, nil
+}
+
+// leafValues returns the text of elements without children by their local names
+func leafValues(document []byte) map[string]string {
+	var result = make(map[string]string)
+	var decoder = xml.NewDecoder(bytes.NewReader(document))
+	var name, text string
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return result
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			name, text = t.Name.Local, ""
+		case xml.CharData:
+			text += string(t)
+		case xml.EndElement:
+			if t.Name.Local == name {
+				result[name] = strings.TrimSpace(text)
+			}
+			name = ""
+		}
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package gatewayapi provides the Gateway resource of the Gateway API
+package gatewayapi
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Gateway resource of the gateway.networking.k8s.io group
+const (
+	Group    = "gateway.networking.k8s.io"
+	Version  = "v1"
+	Resource = "gateways"
+)
+
+// GroupVersionResource of Gateways for the dynamic client
+var GroupVersionResource = schema.GroupVersionResource{Group: Group, Version: Version, Resource: Resource}
+
+// GatewayNameLabel is the label of resources of the in-cluster deployment of the Gateway, e.g. its Services
+const GatewayNameLabel = Group + "/gateway-name"
+
+// IPAddressType is the type of IP addresses of the Gateway
+const IPAddressType = "IPAddress"
+
+// Address is an address of the Gateway. Nil Type means IPAddressType.
+type Address struct {
+	Type  *string `json:"type,omitempty"`
+	Value string  `json:"value"`
+}
+
+// Status is the status of the Gateway
+type Status struct {
+	Addresses []Address `json:"addresses,omitempty"`
+}
+
+// Gateway is a Gateway of the Gateway API. Only the status is decoded.
+type Gateway struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Status            Status `json:"status"`
+}
+
+// IPAddresses returns values of addresses of IPAddressType
+func (g *Gateway) IPAddresses() []string {
+	var result []string
+	for _, address := range g.Status.Addresses {
+		if address.Type == nil || *address.Type == IPAddressType {
+			result = append(result, address.Value)
+		}
+	}
+	return result
+}
@@ -0,0 +1,194 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: mapip.proto
+
+package grpcserver
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	MapIP_ListTranslations_FullMethodName  = "/nsm.mapip.v1.MapIP/ListTranslations"
+	MapIP_WatchTranslations_FullMethodName = "/nsm.mapip.v1.MapIP/WatchTranslations"
+)
+
+// MapIPClient is the client API for MapIP service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type MapIPClient interface {
+	// ListTranslations returns the current map
+	ListTranslations(ctx context.Context, in *ListTranslationsRequest, opts ...grpc.CallOption) (*Map, error)
+	// WatchTranslations sends the current map and then the map on each change
+	WatchTranslations(ctx context.Context, in *WatchTranslationsRequest, opts ...grpc.CallOption) (MapIP_WatchTranslationsClient, error)
+}
+
+type mapIPClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMapIPClient(cc grpc.ClientConnInterface) MapIPClient {
+	return &mapIPClient{cc}
+}
+
+func (c *mapIPClient) ListTranslations(ctx context.Context, in *ListTranslationsRequest, opts ...grpc.CallOption) (*Map, error) {
+	out := new(Map)
+	err := c.cc.Invoke(ctx, MapIP_ListTranslations_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mapIPClient) WatchTranslations(ctx context.Context, in *WatchTranslationsRequest, opts ...grpc.CallOption) (MapIP_WatchTranslationsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &MapIP_ServiceDesc.Streams[0], MapIP_WatchTranslations_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &mapIPWatchTranslationsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type MapIP_WatchTranslationsClient interface {
+	Recv() (*Map, error)
+	grpc.ClientStream
+}
+
+type mapIPWatchTranslationsClient struct {
+	grpc.ClientStream
+}
+
+func (x *mapIPWatchTranslationsClient) Recv() (*Map, error) {
+	m := new(Map)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// MapIPServer is the server API for MapIP service.
+// All implementations must embed UnimplementedMapIPServer
+// for forward compatibility
+type MapIPServer interface {
+	// ListTranslations returns the current map
+	ListTranslations(context.Context, *ListTranslationsRequest) (*Map, error)
+	// WatchTranslations sends the current map and then the map on each change
+	WatchTranslations(*WatchTranslationsRequest, MapIP_WatchTranslationsServer) error
+	mustEmbedUnimplementedMapIPServer()
+}
+
+// UnimplementedMapIPServer must be embedded to have forward compatible implementations.
+type UnimplementedMapIPServer struct {
+}
+
+func (UnimplementedMapIPServer) ListTranslations(context.Context, *ListTranslationsRequest) (*Map, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTranslations not implemented")
+}
+func (UnimplementedMapIPServer) WatchTranslations(*WatchTranslationsRequest, MapIP_WatchTranslationsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchTranslations not implemented")
+}
+func (UnimplementedMapIPServer) mustEmbedUnimplementedMapIPServer() {}
+
+// UnsafeMapIPServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to MapIPServer will
+// result in compilation errors.
+type UnsafeMapIPServer interface {
+	mustEmbedUnimplementedMapIPServer()
+}
+
+func RegisterMapIPServer(s grpc.ServiceRegistrar, srv MapIPServer) {
+	s.RegisterService(&MapIP_ServiceDesc, srv)
+}
+
+func _MapIP_ListTranslations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTranslationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MapIPServer).ListTranslations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MapIP_ListTranslations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MapIPServer).ListTranslations(ctx, req.(*ListTranslationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MapIP_WatchTranslations_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchTranslationsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MapIPServer).WatchTranslations(m, &mapIPWatchTranslationsServer{stream})
+}
+
+type MapIP_WatchTranslationsServer interface {
+	Send(*Map) error
+	grpc.ServerStream
+}
+
+type mapIPWatchTranslationsServer struct {
+	grpc.ServerStream
+}
+
+func (x *mapIPWatchTranslationsServer) Send(m *Map) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// MapIP_ServiceDesc is the grpc.ServiceDesc for MapIP service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var MapIP_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "nsm.mapip.v1.MapIP",
+	HandlerType: (*MapIPServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListTranslations",
+			Handler:    _MapIP_ListTranslations_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchTranslations",
+			Handler:       _MapIP_WatchTranslations_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "mapip.proto",
+}
@@ -0,0 +1,120 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package grpcserver provides a gRPC service serving the map of ips and streaming its updates
+package grpcserver
+
+import (
+	"context"
+	"net"
+	"net/url"
+	"os"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+)
+
+//go:generate protoc -I . --go_out=paths=source_relative:. --go-grpc_out=paths=source_relative:. mapip.proto
+
+// Server serves the latest snapshot of the map with MapIP service generated from mapip.proto. It implements
+// mapipwriter.Target to receive the snapshot.
+type Server struct {
+	UnimplementedMapIPServer
+	mapipwriter.Latest
+}
+
+// Name returns the name of the target
+func (s *Server) Name() string {
+	return "grpc"
+}
+
+// ListTranslations returns the current map
+func (s *Server) ListTranslations(context.Context, *ListTranslationsRequest) (*Map, error) {
+	snapshot, _ := s.Current()
+	return newMap(snapshot), nil
+}
+
+// WatchTranslations sends the current map and then the map on each change until the client cancels the stream
+func (s *Server) WatchTranslations(_ *WatchTranslationsRequest, stream MapIP_WatchTranslationsServer) error {
+	for {
+		snapshot, changed := s.Current()
+		if err := stream.Send(newMap(snapshot)); err != nil {
+			return err
+		}
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-changed:
+		}
+	}
+}
+
+func newMap(snapshot *mapipwriter.Snapshot) *Map {
+	var m = &Map{
+		Translations: make([]*Translation, 0, len(snapshot.Entries)),
+		Generation:   snapshot.Generation,
+	}
+	for i := range snapshot.Entries {
+		var e = &snapshot.Entries[i]
+		m.Translations = append(m.Translations, &Translation{From: e.From, To: e.To, Original: e.Original})
+	}
+	return m
+}
+
+// Register registers the service on the grpc server
+func (s *Server) Register(server *grpc.Server) {
+	RegisterMapIPServer(server, s)
+}
+
+// Listen listens on unix:///path or tcp://host:port. A stale unix socket is removed.
+func Listen(ctx context.Context, listenOn string) (net.Listener, error) {
+	u, err := url.Parse(listenOn)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't parse %v", listenOn)
+	}
+	var address = u.Host
+	if u.Scheme == "unix" {
+		address = u.Path
+		if err = os.Remove(address); err != nil && !os.IsNotExist(err) {
+			return nil, errors.Wrapf(err, "can't remove stale socket %v", address)
+		}
+	}
+	listener, err := new(net.ListenConfig).Listen(ctx, u.Scheme, address)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't listen on %v", listenOn)
+	}
+	return listener, nil
+}
+
+// ListenAndServe listens on unix:///path or tcp://host:port and serves the service until the context is done
+func (s *Server) ListenAndServe(ctx context.Context, listenOn string) error {
+	listener, err := Listen(ctx, listenOn)
+	if err != nil {
+		return err
+	}
+
+	var server = grpc.NewServer()
+	s.Register(server)
+	go func() {
+		<-ctx.Done()
+		server.Stop()
+	}()
+	log.FromContext(ctx).Infof("grpc server is listening on %v", listenOn)
+	return server.Serve(listener)
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcserver_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/grpcserver"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+)
+
+func Test_GRPCServer(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var socket = filepath.Join(t.TempDir(), "map-ip.sock")
+	var server = new(grpcserver.Server)
+	_, err := server.Write(ctx, &mapipwriter.Snapshot{
+		Generation: 1,
+		Entries:    []mapipwriter.Entry{{Translation: mapipwriter.Translation{From: "127.0.0.1", To: "148.142.120.1"}}},
+	})
+	require.NoError(t, err)
+
+	serverCtx, stopServer := context.WithCancel(ctx)
+	var done = make(chan error, 1)
+	go func() { done <- server.ListenAndServe(serverCtx, "unix://"+socket) }()
+	defer func() {
+		stopServer()
+		<-done
+	}()
+
+	cc, err := grpc.DialContext(ctx, "unix://"+socket, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer func() { _ = cc.Close() }()
+	var client = grpcserver.NewMapIPClient(cc)
+
+	list, err := client.ListTranslations(ctx, new(grpcserver.ListTranslationsRequest), grpc.WaitForReady(true))
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), list.GetGeneration())
+	require.Equal(t, "148.142.120.1", list.GetTranslations()[0].GetTo())
+
+	watchCtx, stopWatch := context.WithCancel(ctx)
+	defer stopWatch()
+	stream, err := client.WatchTranslations(watchCtx, new(grpcserver.WatchTranslationsRequest))
+	require.NoError(t, err)
+
+	update, err := stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), update.GetGeneration())
+
+	_, err = server.Write(ctx, &mapipwriter.Snapshot{
+		Generation: 2,
+		Entries:    []mapipwriter.Entry{{Translation: mapipwriter.Translation{From: "127.0.0.1", To: "148.142.120.2"}}},
+	})
+	require.NoError(t, err)
+
+	update, err = stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), update.GetGeneration())
+	require.Equal(t, "148.142.120.2", update.GetTranslations()[0].GetTo())
+}
+
+// The protobuf output format renders Map of mapip.proto
+func Test_ProtobufRendererMatchesProto(t *testing.T) {
+	var snapshot = &mapipwriter.Snapshot{
+		Generation: 7,
+		Entries: []mapipwriter.Entry{
+			{Translation: mapipwriter.Translation{From: "10.0.0.1", To: "203.0.113.1"}},
+			{Translation: mapipwriter.Translation{From: "10.0.1.0/24", To: "198.51.100.0/24"}, Original: "203.0.113.0/24"},
+		},
+	}
+	b, err := mapipwriter.ProtobufRenderer(snapshot)
+	require.NoError(t, err)
+
+	var m = new(grpcserver.Map)
+	require.NoError(t, proto.Unmarshal(b, m))
+	require.True(t, proto.Equal(&grpcserver.Map{
+		Translations: []*grpcserver.Translation{
+			{From: "10.0.0.1", To: "203.0.113.1"},
+			{From: "10.0.1.0/24", To: "198.51.100.0/24", Original: "203.0.113.0/24"},
+		},
+		Generation: 7,
+	}, m), m.String())
+
+	b, err = proto.Marshal(m)
+	require.NoError(t, err)
+	decoded, err := mapipwriter.UnmarshalProtobuf(b)
+	require.NoError(t, err)
+	require.Equal(t, snapshot, decoded)
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package health provides an HTTP server of liveness and readiness probes and the version of the build
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/version"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+)
+
+const readHeaderTimeout = 5 * time.Second
+
+// Server serves:
+//
+//	GET /healthz  - 200 while the process is running
+//	GET /readyz   - 200 after SetReady is called, 503 before
+//	GET /version  - version.Info of the build as JSON
+type Server struct {
+	ready atomic.Bool
+}
+
+// SetReady marks the server as ready
+func (s *Server) SetReady() {
+	s.ready.Store(true)
+}
+
+// Ready returns true if the server is marked as ready
+func (s *Server) Ready() bool {
+	return s.ready.Load()
+}
+
+// Handler returns the HTTP handler of the probes
+func (s *Server) Handler() http.Handler {
+	var mux = http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("ok\n"))
+	})
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, _ *http.Request) {
+		if !s.Ready() {
+			http.Error(w, "not ready", http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("ok\n"))
+	})
+	mux.HandleFunc("GET /version", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(version.Get())
+	})
+	return mux
+}
+
+// ListenAndServe serves the probes on the TCP address until the context is done
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	var server = &http.Server{
+		Addr:              addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: readHeaderTimeout,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+	log.FromContext(ctx).Infof("health probes are listening on %v", addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return errors.Wrapf(err, "can't serve health probes on %v", addr)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/health"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/version"
+)
+
+func status(t *testing.T, url string) int {
+	request, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, http.NoBody)
+	require.NoError(t, err)
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	_ = response.Body.Close()
+	return response.StatusCode
+}
+
+func Test_Probes(t *testing.T) {
+	var probes = new(health.Server)
+	var server = httptest.NewServer(probes.Handler())
+	defer server.Close()
+
+	require.Equal(t, http.StatusOK, status(t, server.URL+"/healthz"))
+	require.Equal(t, http.StatusServiceUnavailable, status(t, server.URL+"/readyz"))
+
+	probes.SetReady()
+	require.Equal(t, http.StatusOK, status(t, server.URL+"/healthz"))
+	require.Equal(t, http.StatusOK, status(t, server.URL+"/readyz"))
+}
+
+func Test_Version(t *testing.T) {
+	var server = httptest.NewServer(new(health.Server).Handler())
+	defer server.Close()
+
+	request, err := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL+"/version", http.NoBody)
+	require.NoError(t, err)
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	defer func() { _ = response.Body.Close() }()
+
+	var info version.Info
+	require.NoError(t, json.NewDecoder(response.Body).Decode(&info))
+	require.Equal(t, version.Get(), info)
+	require.Equal(t, "dev", info.Version)
+}
@@ -2,32 +2,97 @@
 package imports
 
 import (
+	_ "bufio"
+	_ "bytes"
+	_ "compress/gzip"
+	_ "container/list"
 	_ "context"
+	_ "crypto/hmac"
+	_ "crypto/rand"
+	_ "crypto/sha1"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	_ "encoding/base64"
+	_ "encoding/binary"
+	_ "encoding/hex"
+	_ "encoding/json"
+	_ "encoding/xml"
 	_ "fmt"
 	_ "github.com/antonfisher/nested-logrus-formatter"
+	_ "github.com/cilium/ebpf"
 	_ "github.com/edwarnicke/serialize"
+	_ "github.com/fsnotify/fsnotify"
 	_ "github.com/kelseyhightower/envconfig"
 	_ "github.com/networkservicemesh/sdk/pkg/tools/log"
 	_ "github.com/networkservicemesh/sdk/pkg/tools/log/logruslogger"
 	_ "github.com/networkservicemesh/sdk/pkg/tools/opentelemetry"
 	_ "github.com/networkservicemesh/sdk/pkg/tools/pprofutils"
+	_ "github.com/pkg/errors"
 	_ "github.com/sirupsen/logrus"
+	_ "github.com/sirupsen/logrus/hooks/test"
 	_ "github.com/stretchr/testify/require"
+	_ "go.fd.io/govpp/adapter/socketclient"
+	_ "go.fd.io/govpp/binapi/interface_types"
+	_ "go.fd.io/govpp/binapi/ip_types"
+	_ "go.fd.io/govpp/binapi/nat44_ed"
+	_ "go.fd.io/govpp/binapi/nat_types"
+	_ "go.fd.io/govpp/core"
+	_ "go.opentelemetry.io/otel"
+	_ "go.opentelemetry.io/otel/attribute"
+	_ "go.opentelemetry.io/otel/metric"
+	_ "go.opentelemetry.io/otel/metric/noop"
+	_ "go.opentelemetry.io/otel/sdk/metric"
+	_ "go.opentelemetry.io/otel/sdk/metric/metricdata"
 	_ "go.uber.org/goleak"
+	_ "golang.org/x/net/dns/dnsmessage"
+	_ "golang.org/x/net/websocket"
+	_ "golang.org/x/sys/unix"
+	_ "google.golang.org/grpc"
+	_ "google.golang.org/grpc/credentials/insecure"
+	_ "google.golang.org/protobuf/encoding/protowire"
 	_ "gopkg.in/yaml.v2"
+	_ "hash"
+	_ "html"
+	_ "io"
 	_ "k8s.io/api/core/v1"
+	_ "k8s.io/api/discovery/v1"
+	_ "k8s.io/api/networking/v1"
+	_ "k8s.io/apimachinery/pkg/api/errors"
 	_ "k8s.io/apimachinery/pkg/apis/meta/v1"
+	_ "k8s.io/apimachinery/pkg/labels"
+	_ "k8s.io/apimachinery/pkg/runtime"
+	_ "k8s.io/apimachinery/pkg/runtime/schema"
+	_ "k8s.io/apimachinery/pkg/types"
 	_ "k8s.io/apimachinery/pkg/watch"
+	_ "k8s.io/client-go/discovery"
+	_ "k8s.io/client-go/informers"
 	_ "k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/kubernetes/fake"
+	_ "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/rest"
 	_ "k8s.io/client-go/testing"
+	_ "k8s.io/client-go/tools/cache"
+	_ "k8s.io/client-go/tools/clientcmd"
+	_ "k8s.io/client-go/util/retry"
+	_ "maps"
 	_ "net"
+	_ "net/http"
+	_ "net/http/httptest"
+	_ "net/netip"
+	_ "net/url"
 	_ "os"
+	_ "os/exec"
 	_ "os/signal"
 	_ "path/filepath"
+	_ "reflect"
+	_ "slices"
+	_ "sort"
+	_ "strconv"
 	_ "strings"
+	_ "sync"
+	_ "sync/atomic"
 	_ "syscall"
 	_ "testing"
+	_ "text/template"
 	_ "time"
 )
@@ -0,0 +1,61 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package iptranslation provides the IPTranslation custom resource declaring a translation of the map
+package iptranslation
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// IPTranslation resource of the nsm.io group. The definition is crd/nsm.io_iptranslations.yaml.
+const (
+	Group    = "nsm.io"
+	Version  = "v1alpha1"
+	Kind     = "IPTranslation"
+	Resource = "iptranslations"
+)
+
+// GroupVersionResource of IPTranslations for the dynamic client
+var GroupVersionResource = schema.GroupVersionResource{Group: Group, Version: Version, Resource: Resource}
+
+// Spec is the spec of the IPTranslation
+type Spec struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// Priority selects the translation of the From address if several IPTranslations declare it. The highest wins.
+	Priority int32 `json:"priority,omitempty"`
+	// TTL is the lifetime of the translation since the creation of the object. Nil value means it never expires.
+	TTL *metav1.Duration `json:"ttl,omitempty"`
+}
+
+// IPTranslation declares a translation of the map
+type IPTranslation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              Spec `json:"spec"`
+}
+
+// ExpirationTime returns the time the translation expires at. Zero value means it never expires.
+func (t *IPTranslation) ExpirationTime() time.Time {
+	if t.Spec.TTL == nil {
+		return time.Time{}
+	}
+	return t.CreationTimestamp.Add(t.Spec.TTL.Duration)
+}
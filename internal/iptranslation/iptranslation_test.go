@@ -0,0 +1,60 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package iptranslation_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/iptranslation"
+)
+
+func fromUnstructured(t *testing.T, data string) *iptranslation.IPTranslation {
+	var object = new(unstructured.Unstructured)
+	require.NoError(t, object.UnmarshalJSON([]byte(data)))
+	var result = new(iptranslation.IPTranslation)
+	require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(object.UnstructuredContent(), result))
+	return result
+}
+
+func Test_FromUnstructured(t *testing.T) {
+	var object = fromUnstructured(t, `{"apiVersion":"nsm.io/v1alpha1","kind":"IPTranslation",
+		"metadata":{"name":"a","namespace":"nsm","creationTimestamp":"2026-01-01T00:00:00Z"},
+		"spec":{"from":"10.0.0.1","to":"203.0.113.1","priority":10,"ttl":"1h"}}`)
+
+	require.Equal(t, "a", object.Name)
+	require.Equal(t, iptranslation.Spec{
+		From:     "10.0.0.1",
+		To:       "203.0.113.1",
+		Priority: 10,
+		TTL:      &metav1.Duration{Duration: time.Hour},
+	}, object.Spec)
+	require.Equal(t, time.Date(2026, 1, 1, 1, 0, 0, 0, time.UTC), object.ExpirationTime().UTC())
+}
+
+func Test_ExpirationTimeWithoutTTL(t *testing.T) {
+	var object = fromUnstructured(t, `{"apiVersion":"nsm.io/v1alpha1","kind":"IPTranslation",
+		"metadata":{"name":"a","namespace":"nsm","creationTimestamp":"2026-01-01T00:00:00Z"},
+		"spec":{"from":"10.0.0.1","to":"203.0.113.1"}}`)
+
+	require.True(t, object.ExpirationTime().IsZero())
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package k8ssink provides targets publishing the map of ips into Kubernetes objects
+package k8ssink
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+)
+
+// ConfigMap writes the map into the Key of the configmap. The configmap is created if it doesn't exist.
+type ConfigMap struct {
+	Client        kubernetes.Interface
+	Namespace     string
+	ConfigMapName string
+	Key           string
+	// Render renders the value of the key. The map is marshaled as YAML if it's nil.
+	Render mapipwriter.Renderer
+}
+
+// Name returns the namespaced name of the configmap
+func (c *ConfigMap) Name() string {
+	return "configmap/" + c.Namespace + "/" + c.ConfigMapName
+}
+
+// Write creates or updates the configmap with the snapshot. Returns false if the configmap already contains it.
+func (c *ConfigMap) Write(ctx context.Context, snapshot *mapipwriter.Snapshot) (bool, error) {
+	var render = c.Render
+	if render == nil {
+		render = mapipwriter.YAMLRenderer(false)
+	}
+	data, err := render(snapshot)
+	if err != nil {
+		return false, errors.Wrap(err, "an error during marshaling ips map")
+	}
+
+	var written bool
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		var client = c.Client.CoreV1().ConfigMaps(c.Namespace)
+
+		cm, opErr := client.Get(ctx, c.ConfigMapName, metav1.GetOptions{})
+		if apierrors.IsNotFound(opErr) {
+			_, opErr = client.Create(ctx, &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: c.ConfigMapName, Namespace: c.Namespace},
+				Data:       map[string]string{c.Key: string(data)},
+			}, metav1.CreateOptions{})
+			written = opErr == nil
+			return opErr
+		}
+		if opErr != nil {
+			return opErr
+		}
+
+		if value, ok := cm.Data[c.Key]; ok && value == string(data) {
+			written = false
+			return nil
+		}
+		if cm.Data == nil {
+			cm.Data = make(map[string]string)
+		}
+		cm.Data[c.Key] = string(data)
+		_, opErr = client.Update(ctx, cm, metav1.UpdateOptions{})
+		written = opErr == nil
+		return opErr
+	})
+
+	return written, errors.Wrapf(err, "can't write %v", c.Name())
+}
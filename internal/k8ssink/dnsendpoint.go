@@ -0,0 +1,139 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8ssink
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+)
+
+// DNSEndpoint resource of external-dns
+const (
+	DNSEndpointGroup    = "externaldns.k8s.io"
+	DNSEndpointVersion  = "v1alpha1"
+	DNSEndpointKind     = "DNSEndpoint"
+	DNSEndpointResource = "dnsendpoints"
+)
+
+// DNSEndpointGroupVersionResource of DNSEndpoints for the dynamic client
+var DNSEndpointGroupVersionResource = schema.GroupVersionResource{
+	Group:    DNSEndpointGroup,
+	Version:  DNSEndpointVersion,
+	Resource: DNSEndpointResource,
+}
+
+// DNSEndpointRecord is an endpoint of the spec of the DNSEndpoint
+type DNSEndpointRecord struct {
+	DNSName    string   `json:"dnsName"`
+	RecordType string   `json:"recordType"`
+	Targets    []string `json:"targets"`
+	RecordTTL  int64    `json:"recordTTL,omitempty"`
+}
+
+// DNSEndpoint applies A and AAAA records of To addresses of nodes into the DNSEndpoint object, so external-dns
+// publishes them. Translations of other sources are skipped.
+type DNSEndpoint struct {
+	Client     dynamic.Interface
+	Namespace  string
+	ObjectName string
+	Labels     map[string]string
+	// Hostname is a template of the hostname of a node executed with mapipwriter.Entry, e.g.
+	// "{{.Node}}.nodes.example.com"
+	Hostname *template.Template
+	TTL      int64
+
+	lastGeneration uint64
+	applied        bool
+}
+
+// Name returns the namespaced name of the DNSEndpoint
+func (d *DNSEndpoint) Name() string {
+	return "dnsendpoint/" + d.Namespace + "/" + d.ObjectName
+}
+
+func (d *DNSEndpoint) records(snapshot *mapipwriter.Snapshot) ([]DNSEndpointRecord, error) {
+	var records []DNSEndpointRecord
+	var index = make(map[string]int)
+	for i := range snapshot.Entries {
+		var e = &snapshot.Entries[i]
+		var ip = net.ParseIP(e.To)
+		if e.Node == "" || ip == nil {
+			continue
+		}
+		var hostname bytes.Buffer
+		if err := d.Hostname.Execute(&hostname, e); err != nil {
+			return nil, errors.Wrapf(err, "can't make hostname of %v", e.Node)
+		}
+		var record = DNSEndpointRecord{DNSName: strings.Trim(hostname.String(), "."), RecordType: "A", RecordTTL: d.TTL}
+		if ip.To4() == nil {
+			record.RecordType = "AAAA"
+		}
+		var key = record.DNSName + "/" + record.RecordType
+		if j, ok := index[key]; ok {
+			if !slices.Contains(records[j].Targets, ip.String()) {
+				records[j].Targets = append(records[j].Targets, ip.String())
+			}
+			continue
+		}
+		record.Targets = []string{ip.String()}
+		index[key] = len(records)
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// Write applies the DNSEndpoint with records of the snapshot. Returns false if the same generation was applied before.
+func (d *DNSEndpoint) Write(ctx context.Context, snapshot *mapipwriter.Snapshot) (bool, error) {
+	if d.applied && d.lastGeneration == snapshot.Generation {
+		return false, nil
+	}
+	records, err := d.records(snapshot)
+	if err != nil {
+		return false, err
+	}
+	if records == nil {
+		records = []DNSEndpointRecord{}
+	}
+
+	var object = map[string]interface{}{
+		"apiVersion": DNSEndpointGroup + "/" + DNSEndpointVersion,
+		"kind":       DNSEndpointKind,
+		"metadata": map[string]interface{}{
+			"name":      d.ObjectName,
+			"namespace": d.Namespace,
+			"labels":    d.Labels,
+		},
+		"spec": map[string]interface{}{"endpoints": records},
+	}
+	err = applyObject(ctx, d.Client.Resource(DNSEndpointGroupVersionResource).Namespace(d.Namespace), d.ObjectName, object)
+	if err != nil {
+		return false, errors.Wrapf(err, "can't apply %v", d.Name())
+	}
+	d.lastGeneration = snapshot.Generation
+	d.applied = true
+	return true, nil
+}
@@ -0,0 +1,64 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8ssink_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/k8ssink"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+)
+
+func Test_DNSEndpointTarget(t *testing.T) {
+	applied, client := newApplyServer(t)
+
+	hostname, err := mapipwriter.ParseHostnameTemplate("{{.Node}}.nodes.example.com")
+	require.NoError(t, err)
+
+	var target = &k8ssink.DNSEndpoint{
+		Client:     client,
+		Namespace:  "nsm",
+		ObjectName: "map-ip",
+		Hostname:   hostname,
+		TTL:        60,
+	}
+	written, err := target.Write(context.Background(), &mapipwriter.Snapshot{
+		Generation: 1,
+		Entries: []mapipwriter.Entry{
+			{Translation: mapipwriter.Translation{From: "10.0.0.1", To: "203.0.113.1"}, Node: "node-1"},
+			{Translation: mapipwriter.Translation{From: "203.0.113.1", To: "203.0.113.1"}, Node: "node-1"},
+			{Translation: mapipwriter.Translation{From: "10.0.0.2", To: "203.0.113.2"}, Node: "node-2"},
+			{Translation: mapipwriter.Translation{From: "fd00::2", To: "2001:db8::2"}, Node: "node-2"},
+			{Translation: mapipwriter.Translation{From: "10.0.0.3", To: "203.0.113.3"}},
+		},
+	})
+	require.NoError(t, err)
+	require.True(t, written)
+
+	var spec struct {
+		Endpoints []k8ssink.DNSEndpointRecord `json:"endpoints"`
+	}
+	applied.field(t, "/apis/externaldns.k8s.io/v1alpha1/namespaces/nsm/dnsendpoints/map-ip", "spec", &spec)
+	require.Equal(t, []k8ssink.DNSEndpointRecord{
+		{DNSName: "node-1.nodes.example.com", RecordType: "A", Targets: []string{"203.0.113.1"}, RecordTTL: 60},
+		{DNSName: "node-2.nodes.example.com", RecordType: "A", Targets: []string{"203.0.113.2"}, RecordTTL: 60},
+		{DNSName: "node-2.nodes.example.com", RecordType: "AAAA", Targets: []string{"2001:db8::2"}, RecordTTL: 60},
+	}, spec.Endpoints)
+}
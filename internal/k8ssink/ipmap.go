@@ -0,0 +1,150 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8ssink
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+)
+
+// IPMap resource of the nsm.io group. The definition is crd/nsm.io_ipmaps.yaml.
+const (
+	IPMapGroup      = "nsm.io"
+	IPMapVersion    = "v1alpha1"
+	IPMapKind       = "IPMap"
+	IPMapResource   = "ipmaps"
+	ipMapAPIVersion = IPMapGroup + "/" + IPMapVersion
+)
+
+// IPMapGroupVersionResource of IPMaps for the dynamic client
+var IPMapGroupVersionResource = schema.GroupVersionResource{Group: IPMapGroup, Version: IPMapVersion, Resource: IPMapResource}
+
+// Sources of entries reported in the status of the IPMap
+const (
+	SourceNodes     = "nodes"
+	SourceConfigMap = "configmap"
+)
+
+// IPMapTranslation is a translation of the spec of the IPMap
+type IPMapTranslation struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Original string `json:"original,omitempty"`
+	Node     string `json:"node,omitempty"`
+}
+
+// IPMapSpec is the spec of the IPMap
+type IPMapSpec struct {
+	Generation   uint64             `json:"generation"`
+	Translations []IPMapTranslation `json:"translations"`
+}
+
+// IPMapSourceStatus is the status of a source of the entries
+type IPMapSourceStatus struct {
+	Name    string `json:"name"`
+	Entries int    `json:"entries"`
+}
+
+// IPMapStatus is the status of the IPMap
+type IPMapStatus struct {
+	ObservedGeneration uint64              `json:"observedGeneration"`
+	Entries            int                 `json:"entries"`
+	Sources            []IPMapSourceStatus `json:"sources"`
+	LastUpdateTime     string              `json:"lastUpdateTime"`
+}
+
+// IPMap applies the map into the spec and the status of the cluster-scoped IPMap object using server-side apply
+type IPMap struct {
+	Client     dynamic.Interface
+	ObjectName string
+	Labels     map[string]string
+
+	lastGeneration uint64
+	applied        bool
+}
+
+// Name returns the name of the IPMap
+func (m *IPMap) Name() string {
+	return "ipmap/" + m.ObjectName
+}
+
+// Write applies the IPMap with the snapshot. Returns false if the same generation was applied before.
+func (m *IPMap) Write(ctx context.Context, snapshot *mapipwriter.Snapshot) (bool, error) {
+	if m.applied && m.lastGeneration == snapshot.Generation {
+		return false, nil
+	}
+
+	var spec = IPMapSpec{Generation: snapshot.Generation, Translations: make([]IPMapTranslation, 0, len(snapshot.Entries))}
+	var status = IPMapStatus{
+		ObservedGeneration: snapshot.Generation,
+		Entries:            len(snapshot.Entries),
+		Sources:            []IPMapSourceStatus{{Name: SourceNodes}, {Name: SourceConfigMap}},
+		LastUpdateTime:     time.Now().UTC().Format(time.RFC3339),
+	}
+	for i := range snapshot.Entries {
+		var e = &snapshot.Entries[i]
+		spec.Translations = append(spec.Translations, IPMapTranslation{From: e.From, To: e.To, Original: e.Original, Node: e.Node})
+		if e.Node != "" {
+			status.Sources[0].Entries++
+		} else {
+			status.Sources[1].Entries++
+		}
+	}
+
+	if err := m.apply(ctx, map[string]interface{}{"spec": spec}); err != nil {
+		return false, err
+	}
+	if err := m.apply(ctx, map[string]interface{}{"status": status}, "status"); err != nil {
+		return false, err
+	}
+	m.lastGeneration = snapshot.Generation
+	m.applied = true
+	return true, nil
+}
+
+func (m *IPMap) apply(ctx context.Context, fields map[string]interface{}, subresources ...string) error {
+	fields["apiVersion"] = ipMapAPIVersion
+	fields["kind"] = IPMapKind
+	fields["metadata"] = map[string]interface{}{
+		"name":   m.ObjectName,
+		"labels": m.Labels,
+	}
+	if err := applyObject(ctx, m.Client.Resource(IPMapGroupVersionResource), m.ObjectName, fields, subresources...); err != nil {
+		return errors.Wrapf(err, "can't apply %v", m.Name())
+	}
+	return nil
+}
+
+// applyObject applies the object of the name or its subresource using server-side apply
+func applyObject(ctx context.Context, client dynamic.ResourceInterface, name string, object map[string]interface{}, subresources ...string) error {
+	patch, err := json.Marshal(object)
+	if err != nil {
+		return errors.Wrap(err, "can't marshal object")
+	}
+	var force = true
+	_, err = client.Patch(ctx, name, types.ApplyPatchType, patch, metav1.PatchOptions{FieldManager: FieldManager, Force: &force}, subresources...)
+	return err
+}
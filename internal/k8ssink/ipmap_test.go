@@ -0,0 +1,114 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8ssink_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/k8ssink"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+)
+
+// applyServer is a fake API server recording server-side applied objects by their paths
+type applyServer struct {
+	mu      sync.Mutex
+	applied map[string]map[string]json.RawMessage
+}
+
+func newApplyServer(t *testing.T) (*applyServer, dynamic.Interface) {
+	var result = &applyServer{applied: make(map[string]map[string]json.RawMessage)}
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPatch, r.Method)
+		require.Equal(t, string(types.ApplyPatchType), r.Header.Get("Content-Type"))
+		require.Equal(t, k8ssink.FieldManager, r.URL.Query().Get("fieldManager"))
+		require.Equal(t, "true", r.URL.Query().Get("force"))
+
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		var object map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(body, &object))
+
+		result.mu.Lock()
+		result.applied[r.URL.Path] = object
+		result.mu.Unlock()
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := dynamic.NewForConfig(&rest.Config{Host: server.URL})
+	require.NoError(t, err)
+	return result, client
+}
+
+func (a *applyServer) field(t *testing.T, path, name string, v interface{}) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	object, ok := a.applied[path]
+	require.True(t, ok, "%v is not applied", path)
+	require.NoError(t, json.Unmarshal(object[name], v))
+}
+
+func Test_IPMapTarget(t *testing.T) {
+	applied, client := newApplyServer(t)
+
+	var target = &k8ssink.IPMap{Client: client, ObjectName: "cluster"}
+	var snapshot = &mapipwriter.Snapshot{
+		Generation: 2,
+		Entries: []mapipwriter.Entry{
+			{Translation: mapipwriter.Translation{From: "127.0.0.1", To: "148.142.120.1"}, Node: "node-1"},
+			{Translation: mapipwriter.Translation{From: "127.0.0.2", To: "148.142.120.2"}},
+		},
+	}
+
+	written, err := target.Write(context.Background(), snapshot)
+	require.NoError(t, err)
+	require.True(t, written)
+
+	written, err = target.Write(context.Background(), snapshot)
+	require.NoError(t, err)
+	require.False(t, written)
+
+	var spec k8ssink.IPMapSpec
+	applied.field(t, "/apis/nsm.io/v1alpha1/ipmaps/cluster", "spec", &spec)
+	require.Equal(t, k8ssink.IPMapSpec{
+		Generation: 2,
+		Translations: []k8ssink.IPMapTranslation{
+			{From: "127.0.0.1", To: "148.142.120.1", Node: "node-1"},
+			{From: "127.0.0.2", To: "148.142.120.2"},
+		},
+	}, spec)
+
+	var status k8ssink.IPMapStatus
+	applied.field(t, "/apis/nsm.io/v1alpha1/ipmaps/cluster/status", "status", &status)
+	require.Equal(t, uint64(2), status.ObservedGeneration)
+	require.Equal(t, 2, status.Entries)
+	require.Equal(t, []k8ssink.IPMapSourceStatus{
+		{Name: k8ssink.SourceNodes, Entries: 1},
+		{Name: k8ssink.SourceConfigMap, Entries: 1},
+	}, status.Sources)
+}
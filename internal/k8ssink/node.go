@@ -0,0 +1,75 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8ssink
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+)
+
+// NodeAnnotation writes translations derived from the node into the Annotation of the node as a JSON object of From
+// to To addresses, so consumers on the node can read it via the downward API or a node informer
+type NodeAnnotation struct {
+	Client     kubernetes.Interface
+	NodeName   string
+	Annotation string
+
+	lastValue string
+}
+
+// Name returns the name of the node and the annotation
+func (n *NodeAnnotation) Name() string {
+	return "node/" + n.NodeName + "/" + n.Annotation
+}
+
+// Write patches the annotation of the node if translations of the node are changed
+func (n *NodeAnnotation) Write(ctx context.Context, snapshot *mapipwriter.Snapshot) (bool, error) {
+	var translations = make(map[string]string)
+	for i := range snapshot.Entries {
+		if snapshot.Entries[i].Node == n.NodeName {
+			translations[snapshot.Entries[i].From] = snapshot.Entries[i].To
+		}
+	}
+	value, err := json.Marshal(translations)
+	if err != nil {
+		return false, errors.Wrap(err, "can't marshal translations of the node")
+	}
+	if n.lastValue == string(value) {
+		return false, nil
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{n.Annotation: string(value)},
+		},
+	})
+	if err != nil {
+		return false, errors.Wrap(err, "can't marshal patch of the node")
+	}
+	if _, err = n.Client.CoreV1().Nodes().Patch(ctx, n.NodeName, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		return false, errors.Wrapf(err, "can't patch %v", n.Name())
+	}
+	n.lastValue = string(value)
+	return true, nil
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8ssink_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/k8ssink"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+)
+
+func Test_NodeAnnotationTarget(t *testing.T) {
+	var client = fake.NewSimpleClientset(&corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1", Annotations: map[string]string{"other": "value"}},
+	})
+
+	var target = &k8ssink.NodeAnnotation{
+		Client:     client,
+		NodeName:   "node-1",
+		Annotation: "nsm.io/ip-map",
+	}
+	var snapshot = &mapipwriter.Snapshot{
+		Entries: []mapipwriter.Entry{
+			{Translation: mapipwriter.Translation{From: "127.0.0.1", To: "148.142.120.1"}, Node: "node-1"},
+			{Translation: mapipwriter.Translation{From: "127.0.0.2", To: "148.142.120.2"}, Node: "node-2"},
+			{Translation: mapipwriter.Translation{From: "127.0.0.3", To: "148.142.120.3"}},
+		},
+	}
+
+	written, err := target.Write(context.Background(), snapshot)
+	require.NoError(t, err)
+	require.True(t, written)
+
+	written, err = target.Write(context.Background(), snapshot)
+	require.NoError(t, err)
+	require.False(t, written)
+
+	node, err := client.CoreV1().Nodes().Get(context.Background(), "node-1", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "value", node.Annotations["other"])
+	require.JSONEq(t, `{"127.0.0.1":"148.142.120.1"}`, node.Annotations["nsm.io/ip-map"])
+}
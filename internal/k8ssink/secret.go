@@ -0,0 +1,95 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8ssink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+)
+
+// FieldManager is the field manager of objects applied by the targets
+const FieldManager = "cmd-map-ip-k8s"
+
+// ManagedByLabels are ownership labels of objects written by the targets
+var ManagedByLabels = map[string]string{"app.kubernetes.io/managed-by": FieldManager}
+
+// Secret writes the map into the Key of the secret using server-side apply, so fields of other managers are kept
+type Secret struct {
+	Client     kubernetes.Interface
+	Namespace  string
+	SecretName string
+	Key        string
+	Labels     map[string]string
+	// Render renders the value of the key. The map is marshaled as YAML if it's nil.
+	Render mapipwriter.Renderer
+
+	lastApplied []byte
+}
+
+// Name returns the namespaced name of the secret
+func (s *Secret) Name() string {
+	return "secret/" + s.Namespace + "/" + s.SecretName
+}
+
+// Write applies the secret with the snapshot. Returns false if the same data was applied before.
+func (s *Secret) Write(ctx context.Context, snapshot *mapipwriter.Snapshot) (bool, error) {
+	var render = s.Render
+	if render == nil {
+		render = mapipwriter.YAMLRenderer(false)
+	}
+	data, err := render(snapshot)
+	if err != nil {
+		return false, errors.Wrap(err, "an error during marshaling ips map")
+	}
+	if s.lastApplied != nil && bytes.Equal(s.lastApplied, data) {
+		return false, nil
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata": map[string]interface{}{
+			"name":      s.SecretName,
+			"namespace": s.Namespace,
+			"labels":    s.Labels,
+		},
+		"type": "Opaque",
+		"data": map[string][]byte{s.Key: data},
+	})
+	if err != nil {
+		return false, errors.Wrap(err, "can't marshal secret")
+	}
+
+	var force = true
+	_, err = s.Client.CoreV1().Secrets(s.Namespace).Patch(ctx, s.SecretName, types.ApplyPatchType, patch, metav1.PatchOptions{
+		FieldManager: FieldManager,
+		Force:        &force,
+	})
+	if err != nil {
+		return false, errors.Wrapf(err, "can't apply %v", s.Name())
+	}
+	s.lastApplied = data
+	return true, nil
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8ssink_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stest "k8s.io/client-go/testing"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/k8ssink"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+)
+
+func Test_SecretTarget(t *testing.T) {
+	var applied []*corev1.Secret
+
+	var client = fake.NewSimpleClientset()
+	// the fake clientset doesn't support server-side apply
+	client.PrependReactor("patch", "secrets", func(action k8stest.Action) (bool, runtime.Object, error) {
+		var patch = action.(k8stest.PatchAction)
+		require.Equal(t, types.ApplyPatchType, patch.GetPatchType())
+
+		var secret = new(corev1.Secret)
+		require.NoError(t, json.Unmarshal(patch.GetPatch(), secret))
+		applied = append(applied, secret)
+		return true, secret, nil
+	})
+
+	var target = &k8ssink.Secret{
+		Client:     client,
+		Namespace:  "nsm",
+		SecretName: "external-ips",
+		Key:        "external_ips.yaml",
+		Labels:     k8ssink.ManagedByLabels,
+	}
+	var snapshot = &mapipwriter.Snapshot{
+		Entries: []mapipwriter.Entry{
+			{Translation: mapipwriter.Translation{From: "127.0.0.1", To: "148.142.120.1"}},
+		},
+	}
+
+	written, err := target.Write(context.Background(), snapshot)
+	require.NoError(t, err)
+	require.True(t, written)
+
+	written, err = target.Write(context.Background(), snapshot)
+	require.NoError(t, err)
+	require.False(t, written)
+
+	require.Len(t, applied, 1)
+	require.Equal(t, "external-ips", applied[0].Name)
+	require.Equal(t, "nsm", applied[0].Namespace)
+	require.Equal(t, k8ssink.ManagedByLabels, applied[0].Labels)
+	require.Equal(t, "127.0.0.1: 148.142.120.1\n", string(applied[0].Data["external_ips.yaml"]))
+}
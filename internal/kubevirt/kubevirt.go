@@ -0,0 +1,71 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package kubevirt provides the VirtualMachineInstance resource of KubeVirt
+package kubevirt
+
+import (
+	"net"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// VirtualMachineInstance resource of the kubevirt.io group
+const (
+	Group    = "kubevirt.io"
+	Version  = "v1"
+	Resource = "virtualmachineinstances"
+)
+
+// GroupVersionResource of VirtualMachineInstances for the dynamic client
+var GroupVersionResource = schema.GroupVersionResource{Group: Group, Version: Version, Resource: Resource}
+
+// Interface is a network interface of the VirtualMachineInstance
+type Interface struct {
+	Name        string   `json:"name,omitempty"`
+	IPAddress   string   `json:"ipAddress,omitempty"`
+	IPAddresses []string `json:"ipAddresses,omitempty"`
+}
+
+// Status is the status of the VirtualMachineInstance
+type Status struct {
+	// NodeName is the name of the node hosting the VirtualMachineInstance
+	NodeName   string      `json:"nodeName,omitempty"`
+	Interfaces []Interface `json:"interfaces,omitempty"`
+}
+
+// VirtualMachineInstance is a running virtual machine of KubeVirt. Only the status is decoded.
+type VirtualMachineInstance struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Status            Status `json:"status"`
+}
+
+// IPs returns distinct IPs of interfaces of the VirtualMachineInstance
+func (v *VirtualMachineInstance) IPs() []string {
+	var result []string
+	var seen = make(map[string]bool)
+	for _, iface := range v.Status.Interfaces {
+		for _, ip := range append([]string{iface.IPAddress}, iface.IPAddresses...) {
+			if net.ParseIP(ip) != nil && !seen[ip] {
+				seen[ip] = true
+				result = append(result, ip)
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapipwriter
+
+import (
+	"net"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// IsCIDR returns true if the From address of the translation is a CIDR. Such translation translates every address
+// of the subnet. Consumers are expected to use the translation with the longest matching prefix.
+func (e *Translation) IsCIDR() bool {
+	return strings.Contains(e.From, "/")
+}
+
+// Validate checks the translation of a CIDR. The To address of such translation should be either an IP of the same
+// family, like a gateway translating the whole subnet, or a CIDR with the same family and prefix length, so the host
+// part of addresses is kept.
+func (e *Translation) Validate() error {
+	if !e.IsCIDR() {
+		return nil
+	}
+	_, from, err := net.ParseCIDR(e.From)
+	if err != nil {
+		return errors.Wrapf(err, "invalid translation %v", e.String())
+	}
+	fromOnes, fromBits := from.Mask.Size()
+
+	if strings.Contains(e.To, "/") {
+		_, to, parseErr := net.ParseCIDR(e.To)
+		if parseErr != nil {
+			return errors.Wrapf(parseErr, "invalid translation %v", e.String())
+		}
+		if toOnes, toBits := to.Mask.Size(); toOnes != fromOnes || toBits != fromBits {
+			return errors.Errorf("invalid translation %v: CIDRs should have the same family and prefix length", e.String())
+		}
+		return nil
+	}
+
+	var to = net.ParseIP(e.To)
+	if to == nil {
+		return errors.Errorf("invalid translation %v: To should be an IP or a CIDR", e.String())
+	}
+	if (to.To4() == nil) != (fromBits == 8*net.IPv6len) {
+		return errors.Errorf("invalid translation %v: addresses should have the same family", e.String())
+	}
+	return nil
+}
+
+// prefixLen returns the prefix length of the From CIDR or -1 for a single address
+func (e *Translation) prefixLen() int {
+	if !e.IsCIDR() {
+		return -1
+	}
+	_, from, err := net.ParseCIDR(e.From)
+	if err != nil {
+		return -1
+	}
+	ones, _ := from.Mask.Size()
+	return ones
+}
+
+// lessEntries orders single addresses before CIDRs and CIDRs by the longest prefix first, so a consumer picking the
+// first matching entry gets the longest prefix match
+func lessEntries(a, b *Entry) bool {
+	if a.IsCIDR() != b.IsCIDR() {
+		return !a.IsCIDR()
+	}
+	if aLen, bLen := a.prefixLen(), b.prefixLen(); aLen != bLen {
+		return aLen > bLen
+	}
+	if a.From != b.From {
+		return a.From < b.From
+	}
+	return a.To < b.To
+}
@@ -0,0 +1,67 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapipwriter
+
+import (
+	"bytes"
+	"net"
+	"strings"
+)
+
+// NewCoreDNSHostsRenderer returns a renderer of a file loadable by the CoreDNS hosts plugin. Each entry is rendered
+// as the To address resolved by the From address used as a name in the zone. If reverse is set then the From address
+// is also resolved by the To address used as a name, so translations can be looked up in both directions.
+//
+// The plugin reloads the file when its modification time or size is changed, so FileTarget atomic writes make sure
+// it never loads a partially written file.
+func NewCoreDNSHostsRenderer(zone string, reverse bool) Renderer {
+	var suffix = strings.Trim(strings.ToLower(zone), ".")
+	if suffix != "" {
+		suffix = "." + suffix
+	}
+
+	return func(snapshot *Snapshot) ([]byte, error) {
+		var buf bytes.Buffer
+		var seen = make(map[[2]string]struct{})
+		var add = func(ip, name string) {
+			if net.ParseIP(ip) == nil || name == "" || strings.Contains(name, "/") {
+				return
+			}
+			var line = [2]string{ip, hostName(name) + suffix}
+			if _, ok := seen[line]; ok {
+				return
+			}
+			seen[line] = struct{}{}
+			buf.WriteString(line[0] + "\t" + line[1] + "\n")
+		}
+
+		buf.WriteString("# generated by cmd-map-ip-k8s\n")
+		for i := range snapshot.Entries {
+			add(snapshot.Entries[i].To, snapshot.Entries[i].From)
+			if reverse {
+				add(snapshot.Entries[i].From, snapshot.Entries[i].To)
+			}
+		}
+		return buf.Bytes(), nil
+	}
+}
+
+// hostName converts the address into a valid host name. IPv6 addresses contain colons that are not allowed in names,
+// so they are replaced with dashes.
+func hostName(addr string) string {
+	return strings.ReplaceAll(strings.ToLower(addr), ":", "-")
+}
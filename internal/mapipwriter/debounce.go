@@ -0,0 +1,70 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapipwriter
+
+import (
+	"time"
+)
+
+// debouncer coalesces bursts of triggers into a single fire after the window without triggers. The fire is not
+// delayed by more than maxLatency since the first trigger of the burst if maxLatency is positive.
+type debouncer struct {
+	window     time.Duration
+	maxLatency time.Duration
+
+	timer    *time.Timer
+	pending  bool
+	deadline time.Time
+}
+
+func (d *debouncer) trigger() {
+	var now = time.Now()
+	if !d.pending {
+		d.pending = true
+		d.deadline = now.Add(d.maxLatency)
+	}
+
+	var delay = d.window
+	if d.maxLatency > 0 && now.Add(delay).After(d.deadline) {
+		delay = d.deadline.Sub(now)
+	}
+
+	if d.timer == nil {
+		d.timer = time.NewTimer(delay)
+		return
+	}
+	d.timer.Stop()
+	d.timer.Reset(delay)
+}
+
+// C returns the channel of the fire. It's nil if there are no pending triggers.
+func (d *debouncer) C() <-chan time.Time {
+	if !d.pending {
+		return nil
+	}
+	return d.timer.C
+}
+
+func (d *debouncer) fired() {
+	d.pending = false
+}
+
+func (d *debouncer) stop() {
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapipwriter
+
+import (
+	"bytes"
+	"strings"
+)
+
+// NewEnvFileRenderer returns a renderer of KEY=VALUE lines suitable for envFrom or sourcing in shell. The key is the
+// From address prefixed with prefix and sanitized for variable names, the value is the To address.
+func NewEnvFileRenderer(prefix string) Renderer {
+	return func(snapshot *Snapshot) ([]byte, error) {
+		var buf bytes.Buffer
+		for i := range snapshot.Entries {
+			buf.WriteString(envName(prefix + snapshot.Entries[i].From))
+			buf.WriteByte('=')
+			buf.WriteString(snapshot.Entries[i].To)
+			buf.WriteByte('\n')
+		}
+		return buf.Bytes(), nil
+	}
+}
+
+// envName converts s into a valid variable name: letters are upper-cased, other characters except digits and
+// underscores are replaced with underscores and a leading digit is prefixed with an underscore
+func envName(s string) string {
+	var b strings.Builder
+	for i, r := range strings.ToUpper(s) {
+		switch {
+		case r >= 'A' && r <= 'Z', r == '_':
+			b.WriteRune(r)
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				b.WriteByte('_')
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
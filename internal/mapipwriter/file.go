@@ -0,0 +1,441 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapipwriter
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+)
+
+// FileTarget writes the map into Path as YAML or in the format of Render
+type FileTarget struct {
+	Path string
+	// Render renders the content of the file. The map is marshaled as YAML if it's nil.
+	Render Renderer
+	// Extended enables the extended output schema where each entry carries the To address and the original address
+	Extended bool
+	// SkipUnchanged skips writing of the file when its content is the same as the last written one
+	SkipUnchanged bool
+	// VerifyAfterWrite re-reads the file after writing and rewrites it if the content doesn't match
+	VerifyAfterWrite bool
+	// FollowSymlinks writes through Path into the file it links to instead of replacing the link
+	FollowSymlinks bool
+	// WriteGeneration writes the generation of the map into a companion file with GenerationSuffix
+	WriteGeneration bool
+	// Header prepends the file with comments containing the generation of the map, the write timestamp and NodeName
+	Header bool
+	// NodeName is the name of the node producing the file written in the header
+	NodeName string
+	// WritePatch writes the JSON Patch from the previously written map to the current one into a companion file with
+	// PatchSuffix. The patch of the first write adds all entries.
+	WritePatch bool
+	// HMACKey signs the file with HMAC-SHA256 written into a companion file with HMACSuffix if it's not empty.
+	// Consumers can check the file with VerifyHMAC.
+	HMACKey []byte
+	// KeepVersions is the count of previous versions of the file kept with .1, .2, ... suffixes, the .1 is the latest
+	KeepVersions int
+	// Gzip compresses the content of the file with gzip. Path is expected to have GzipSuffix.
+	Gzip bool
+	// Mode is the permission bits of the file. os.ModePerm is used if it's zero.
+	Mode os.FileMode
+	// DirMode is the permission bits of directories created for the file. os.ModePerm is used if it's zero.
+	DirMode os.FileMode
+	// Owner is the owner of the file. The owner is not changed if it's nil.
+	Owner *FileOwner
+	// Fsync flushes the temporary file to the storage before renaming it and the directory after renaming, so the
+	// file survives a crash of the node
+	Fsync bool
+	// WriteFile writes data into the path. Atomic write via a temporary file is used if it's nil.
+	WriteFile func(path string, data []byte) error
+	// OnClose is the action with the file when MapIPWriter stops: OnCloseKeep, OnCloseTruncate or OnCloseStale.
+	// The file is kept if it's empty.
+	OnClose string
+
+	lastWrittenHash       [sha256.Size]byte
+	lastWrittenGeneration uint64
+	lastWrittenMap        map[string]string
+}
+
+// FileOwner is a numeric owner of a file. Negative ids are not changed.
+type FileOwner struct {
+	UID, GID int
+}
+
+// ParseFileOwner parses the owner in form of uid or uid:gid
+func ParseFileOwner(s string) (*FileOwner, error) {
+	var owner = &FileOwner{UID: -1, GID: -1}
+	uid, gid, hasGID := strings.Cut(s, ":")
+	var err error
+	if owner.UID, err = strconv.Atoi(strings.TrimSpace(uid)); err != nil {
+		return nil, errors.Wrapf(err, "invalid owner %q: expected uid or uid:gid", s)
+	}
+	if hasGID {
+		if owner.GID, err = strconv.Atoi(strings.TrimSpace(gid)); err != nil {
+			return nil, errors.Wrapf(err, "invalid owner %q: expected uid or uid:gid", s)
+		}
+	}
+	return owner, nil
+}
+
+// GzipSuffix is the suffix of compressed files
+const GzipSuffix = ".gz"
+
+// GenerationSuffix is the suffix of the companion file with the generation of the map
+const GenerationSuffix = ".generation"
+
+// StaleSuffix is the suffix of the companion file marking the file as stale with OnCloseStale
+const StaleSuffix = ".stale"
+
+const (
+	// OnCloseKeep keeps the file as is
+	OnCloseKeep = "keep"
+	// OnCloseTruncate truncates the file, so consumers see an empty map
+	OnCloseTruncate = "truncate"
+	// OnCloseStale keeps the file and writes the generation of the last written map into a companion file with
+	// StaleSuffix. The marker is removed on the next write, so consumers can distinguish a stopped writer from an
+	// empty map.
+	OnCloseStale = "stale"
+)
+
+// maxWriteAttempts is the count of attempts to write a file that fails verification
+const maxWriteAttempts = 3
+
+// Name returns the path of the file
+func (f *FileTarget) Name() string {
+	return f.Path
+}
+
+func (f *FileTarget) marshal(snapshot *Snapshot) ([]byte, error) {
+	if f.Render != nil {
+		return f.Render(snapshot)
+	}
+	return YAMLRenderer(f.Extended)(snapshot)
+}
+
+// resolvePath returns the path of the file to write. It's the target of Path if Path is a symlink and
+// FollowSymlinks is set.
+func (f *FileTarget) resolvePath() (string, error) {
+	if !f.FollowSymlinks {
+		return f.Path, nil
+	}
+	info, err := os.Lstat(f.Path)
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		return f.Path, nil
+	}
+	resolved, err := filepath.EvalSymlinks(f.Path)
+	if err != nil {
+		return "", errors.Wrapf(err, "can't resolve symlink %v", f.Path)
+	}
+	return resolved, nil
+}
+
+// Write writes the snapshot into the file
+func (f *FileTarget) Write(ctx context.Context, snapshot *Snapshot) (bool, error) {
+	path, err := f.resolvePath()
+	if err != nil {
+		return false, err
+	}
+	_ = os.MkdirAll(filepath.Dir(path), orDefault(f.DirMode))
+
+	body, err := f.marshal(snapshot)
+	if err != nil {
+		return false, errors.Wrap(err, "an error during marshaling ips map")
+	}
+
+	// the header is not hashed, so changes of the timestamp don't cause writes of the same map
+	var bodyHash = sha256.Sum256(body)
+	if f.SkipUnchanged && bodyHash == f.lastWrittenHash {
+		log.FromContext(ctx).Debugf("content of %v is not changed, skip writing", path)
+		return false, nil
+	}
+	data, err := f.content(snapshot, body)
+	if err != nil {
+		return false, err
+	}
+
+	if f.KeepVersions > 0 {
+		f.rotate(ctx, path)
+	}
+	if err = f.writeAndVerify(ctx, path, data); err != nil {
+		return false, err
+	}
+
+	// the signature is written before remembering the hash, so a failed signature is retried on the next write
+	if len(f.HMACKey) > 0 {
+		if err = f.writeFile(path+HMACSuffix, sign(data, f.HMACKey)); err != nil {
+			return true, err
+		}
+	}
+
+	f.lastWrittenHash = bodyHash
+	f.lastWrittenGeneration = snapshot.Generation
+	return true, f.writeCompanions(path, snapshot)
+}
+
+// content returns the content of the file with the rendered body: prepended with the header and compressed if
+// they are enabled
+func (f *FileTarget) content(snapshot *Snapshot, body []byte) ([]byte, error) {
+	if f.Header {
+		body = append(f.header(snapshot), body...)
+	}
+	if f.Gzip {
+		return compress(body)
+	}
+	return body, nil
+}
+
+// writeFile writes data into the path with WriteFile or atomically if it's nil
+func (f *FileTarget) writeFile(path string, data []byte) error {
+	if f.WriteFile != nil {
+		return f.WriteFile(path, data)
+	}
+	return f.writeFileAtomic(path, data)
+}
+
+// writeAndVerify writes data into the path. With VerifyAfterWrite the file is re-read and rewritten up to
+// maxWriteAttempts times if the content doesn't match.
+func (f *FileTarget) writeAndVerify(ctx context.Context, path string, data []byte) error {
+	var hash = sha256.Sum256(data)
+	for attempt := 1; ; attempt++ {
+		if err := f.writeFile(path, data); err != nil {
+			return err
+		}
+		if !f.VerifyAfterWrite {
+			return nil
+		}
+		err := verifyFile(path, hash)
+		if err == nil {
+			return nil
+		}
+		log.FromContext(ctx).Warnf("verification of %v failed, attempt %v/%v: %v", path, attempt, maxWriteAttempts, err.Error())
+		if attempt == maxWriteAttempts {
+			return err
+		}
+	}
+}
+
+// writeCompanions removes the stale marker of the written file and writes its generation and patch files
+func (f *FileTarget) writeCompanions(path string, snapshot *Snapshot) error {
+	if err := os.Remove(path + StaleSuffix); err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "can't remove stale marker of %v", path)
+	}
+
+	if f.WriteGeneration {
+		if err := f.writeFile(path+GenerationSuffix, []byte(strconv.FormatUint(snapshot.Generation, 10)+"\n")); err != nil {
+			return err
+		}
+	}
+	if f.WritePatch {
+		var next = flatten(snapshot)
+		patch, err := marshalPatch(Diff(f.lastWrittenMap, next))
+		if err != nil {
+			return errors.Wrap(err, "an error during marshaling patch of ips map")
+		}
+		if err = f.writeFile(path+PatchSuffix, patch); err != nil {
+			return err
+		}
+		f.lastWrittenMap = next
+	}
+	return nil
+}
+
+// Restore applies OnClose to the file
+func (f *FileTarget) Restore() {
+	if f.OnClose == "" || f.OnClose == OnCloseKeep {
+		return
+	}
+	path, err := f.resolvePath()
+	if err != nil {
+		log.Default().Errorf("can't close %v: %v", f.Path, err.Error())
+		return
+	}
+	switch f.OnClose {
+	case OnCloseTruncate:
+		err = f.writeFile(path, nil)
+	case OnCloseStale:
+		err = f.writeFile(path+StaleSuffix, []byte(strconv.FormatUint(f.lastWrittenGeneration, 10)+"\n"))
+	default:
+		err = errors.Errorf("unknown action %q", f.OnClose)
+	}
+	if err != nil {
+		log.Default().Errorf("can't close %v: %v", path, err.Error())
+	}
+}
+
+func (f *FileTarget) header(snapshot *Snapshot) []byte {
+	var header = "# generation: " + strconv.FormatUint(snapshot.Generation, 10) + "\n" +
+		"# timestamp: " + time.Now().UTC().Format(time.RFC3339Nano) + "\n"
+	if f.NodeName != "" {
+		header += "# node: " + f.NodeName + "\n"
+	}
+	for i := range snapshot.Entries {
+		if snapshot.Entries[i].IsCIDR() {
+			header += "# lookup: entries with CIDRs translate whole subnets, use the longest matching prefix\n"
+			break
+		}
+	}
+	return []byte(header)
+}
+
+// rotate shifts previous versions of the file and keeps the current one as the .1 version. The current file is
+// hard linked, so it stays in place until it's replaced by the new version.
+func (f *FileTarget) rotate(ctx context.Context, path string) {
+	var version = func(i int) string {
+		return path + "." + strconv.Itoa(i)
+	}
+	if _, err := os.Stat(path); err != nil {
+		return
+	}
+	for i := f.KeepVersions - 1; i > 0; i-- {
+		if err := os.Rename(version(i), version(i+1)); err != nil && !os.IsNotExist(err) {
+			log.FromContext(ctx).Warnf("can't rotate %v: %v", version(i), err.Error())
+		}
+	}
+	_ = os.Remove(version(1))
+	if err := os.Link(path, version(1)); err != nil {
+		log.FromContext(ctx).Warnf("can't keep the previous version of %v: %v", path, err.Error())
+	}
+}
+
+func compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	var w = gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, errors.Wrap(err, "can't compress ips map")
+	}
+	if err := w.Close(); err != nil {
+		return nil, errors.Wrap(err, "can't compress ips map")
+	}
+	return buf.Bytes(), nil
+}
+
+func verifyFile(path string, expected [sha256.Size]byte) error {
+	// #nosec G304
+	actual, err := os.ReadFile(path)
+	if err != nil {
+		return errors.Wrapf(err, "can't read %v", path)
+	}
+	if sha256.Sum256(actual) != expected {
+		return errors.Errorf("content of %v doesn't match the written data", path)
+	}
+	return nil
+}
+
+// tempFileInfix separates the output file name and the random suffix of temporary files
+const tempFileInfix = ".tmp-"
+
+// staleTempFileAge is the age of temporary files considered left by crashed writers. Younger files may be being
+// written by other writers of the same path, e.g. by the previous pod during a rolling update.
+const staleTempFileAge = time.Minute
+
+// writeFileAtomic writes data into a temporary file in the directory of path and renames it to path, so readers
+// never see a partially written file. Temporary file names are random to avoid collisions between concurrent writers.
+func (f *FileTarget) writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+tempFileInfix+"*")
+	if err != nil {
+		return errors.Wrapf(err, "can't create temporary file for %v", path)
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+
+	if _, err = tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return errors.Wrapf(err, "can't write temporary file %v", tmp.Name())
+	}
+	if err = tmp.Chmod(orDefault(f.Mode)); err != nil {
+		_ = tmp.Close()
+		return errors.Wrapf(err, "can't change mode of temporary file %v", tmp.Name())
+	}
+	if f.Owner != nil {
+		if err = tmp.Chown(f.Owner.UID, f.Owner.GID); err != nil {
+			_ = tmp.Close()
+			return errors.Wrapf(err, "can't change owner of temporary file %v", tmp.Name())
+		}
+	}
+	if f.Fsync {
+		if err = tmp.Sync(); err != nil {
+			_ = tmp.Close()
+			return errors.Wrapf(err, "can't sync temporary file %v", tmp.Name())
+		}
+	}
+	if err = tmp.Close(); err != nil {
+		return errors.Wrapf(err, "can't close temporary file %v", tmp.Name())
+	}
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return errors.Wrapf(err, "can't rename %v to %v", tmp.Name(), path)
+	}
+	if f.Fsync {
+		return syncDir(filepath.Dir(path))
+	}
+	return nil
+}
+
+func orDefault(mode os.FileMode) os.FileMode {
+	if mode == 0 {
+		return os.ModePerm
+	}
+	return mode
+}
+
+// syncDir flushes the directory entries, so the rename is persisted
+func syncDir(dir string) error {
+	// #nosec G304
+	d, err := os.Open(dir)
+	if err != nil {
+		return errors.Wrapf(err, "can't open directory %v", dir)
+	}
+	defer func() { _ = d.Close() }()
+	return errors.Wrapf(d.Sync(), "can't sync directory %v", dir)
+}
+
+// removeStaleTempFiles removes temporary files of the target left by writers that crashed before renaming. Files
+// modified within staleTempFileAge are kept.
+func (f *FileTarget) removeStaleTempFiles(ctx context.Context) {
+	path, err := f.resolvePath()
+	if err != nil {
+		log.FromContext(ctx).Errorf("can't remove temporary files: %v", err.Error())
+		return
+	}
+	matches, err := filepath.Glob(path + tempFileInfix + "*")
+	if err != nil {
+		log.FromContext(ctx).Errorf("can't list temporary files of %v: %v", path, err.Error())
+		return
+	}
+	for _, match := range matches {
+		info, statErr := os.Stat(match)
+		if statErr != nil || time.Since(info.ModTime()) < staleTempFileAge {
+			continue
+		}
+		if err = os.Remove(match); err != nil {
+			log.FromContext(ctx).Warnf("can't remove stale temporary file %v: %v", match, err.Error())
+			continue
+		}
+		log.FromContext(ctx).Infof("removed stale temporary file %v", match)
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapipwriter
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// HMACSuffix is the suffix of the companion file with the hex encoded HMAC-SHA256 of the file
+const HMACSuffix = ".hmac"
+
+func sign(data, key []byte) []byte {
+	var mac = hmac.New(sha256.New, key)
+	_, _ = mac.Write(data)
+	return []byte(hex.EncodeToString(mac.Sum(nil)) + "\n")
+}
+
+// VerifyHMAC checks that the file at path matches its HMAC companion file signed with the key. The companion file is
+// written after the file, so a reader racing with a writer may need to retry.
+func VerifyHMAC(path string, key []byte) error {
+	// #nosec G304
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return errors.Wrapf(err, "can't read %v", path)
+	}
+	// #nosec G304
+	signature, err := os.ReadFile(path + HMACSuffix)
+	if err != nil {
+		return errors.Wrapf(err, "can't read %v", path+HMACSuffix)
+	}
+	expected, err := hex.DecodeString(strings.TrimSpace(string(signature)))
+	if err != nil {
+		return errors.Wrapf(err, "invalid HMAC of %v", path)
+	}
+
+	var mac = hmac.New(sha256.New, key)
+	_, _ = mac.Write(data)
+	if !hmac.Equal(mac.Sum(nil), expected) {
+		return errors.Errorf("HMAC of %v doesn't match its content", path)
+	}
+	return nil
+}
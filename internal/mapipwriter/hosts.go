@@ -0,0 +1,91 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapipwriter
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Columns of the hosts format
+const (
+	HostsColumnTo       = "to"
+	HostsColumnFrom     = "from"
+	HostsColumnOriginal = "original"
+)
+
+// DefaultHostsColumns renders lines as /etc/hosts does: the To address followed by the From address as a name
+const DefaultHostsColumns = HostsColumnTo + "," + HostsColumnFrom
+
+// NewHostsRenderer returns a renderer of the /etc/hosts compatible format. Each entry is rendered into a line of
+// whitespace separated columns given as a comma separated list of to, from and original. Empty columns are omitted.
+// Columns following the first one are names, so they are converted with hostName and CIDRs are omitted.
+func NewHostsRenderer(columns string) (Renderer, error) {
+	var parsed []string
+	for _, column := range strings.Split(columns, ",") {
+		column = strings.ToLower(strings.TrimSpace(column))
+		switch column {
+		case "":
+			continue
+		case HostsColumnTo, HostsColumnFrom, HostsColumnOriginal:
+			parsed = append(parsed, column)
+		default:
+			return nil, errors.Errorf("unknown hosts column %q: expected one of %v, %v, %v", column, HostsColumnTo, HostsColumnFrom, HostsColumnOriginal)
+		}
+	}
+	if len(parsed) < 2 {
+		return nil, errors.Errorf("hosts format requires at least two columns, got %q", columns)
+	}
+
+	return func(snapshot *Snapshot) ([]byte, error) {
+		var buf bytes.Buffer
+		for i := range snapshot.Entries {
+			var fields = make([]string, 0, len(parsed))
+			for _, column := range parsed {
+				var value = snapshot.Entries[i].column(column)
+				if len(fields) > 0 {
+					if strings.Contains(value, "/") {
+						continue
+					}
+					value = hostName(value)
+				}
+				if value != "" {
+					fields = append(fields, value)
+				}
+			}
+			if len(fields) < 2 {
+				continue
+			}
+			buf.WriteString(strings.Join(fields, "\t"))
+			buf.WriteByte('\n')
+		}
+		return buf.Bytes(), nil
+	}, nil
+}
+
+func (e *Entry) column(name string) string {
+	switch name {
+	case HostsColumnTo:
+		return e.To
+	case HostsColumnFrom:
+		return e.From
+	default:
+		return e.Original
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapipwriter
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+)
+
+// DefaultHostsBlockMarker is the default marker of the managed block of the hosts file
+const DefaultHostsBlockMarker = "nsm-map-ip"
+
+// HostsBlockTarget maintains a block between "# BEGIN <marker>" and "# END <marker>" lines inside a hosts file,
+// e.g. /etc/hosts, mapping hostnames of nodes to their To addresses. Content outside of the block is preserved.
+// The file is rewritten in place rather than renamed, because /etc/hosts of containers is usually a bind mount.
+type HostsBlockTarget struct {
+	Path string
+	// Marker identifies the block. Empty value means DefaultHostsBlockMarker.
+	Marker string
+	// Hostname is a template of whitespace separated hostname and aliases of a node executed with Entry, e.g.
+	// "{{.Node}} {{.Node}}.nodes.example.com". Entries not derived from nodes, e.g. entries of the ConfigMap, are skipped.
+	Hostname *template.Template
+	// Mode is the mode of the file if it's created. Zero value means 0644.
+	Mode os.FileMode
+}
+
+// Name returns the path of the file
+func (h *HostsBlockTarget) Name() string {
+	return "hosts:" + h.Path
+}
+
+// Write replaces the managed block with the snapshot. Returns false if the block is not changed.
+func (h *HostsBlockTarget) Write(_ context.Context, snapshot *Snapshot) (bool, error) {
+	var block bytes.Buffer
+	block.WriteString(h.begin() + "\n")
+	for i := range snapshot.Entries {
+		if snapshot.Entries[i].Node == "" {
+			continue
+		}
+		var hostnames bytes.Buffer
+		if err := h.Hostname.Execute(&hostnames, &snapshot.Entries[i]); err != nil {
+			return false, errors.Wrapf(err, "can't execute hostname template for %v", snapshot.Entries[i].From)
+		}
+		if fields := strings.Fields(hostnames.String()); len(fields) > 0 {
+			block.WriteString(snapshot.Entries[i].To + "\t" + strings.Join(fields, " ") + "\n")
+		}
+	}
+	block.WriteString(h.end() + "\n")
+	return h.replace(block.String())
+}
+
+// Restore removes the managed block restoring the unmanaged content of the file
+func (h *HostsBlockTarget) Restore() {
+	if _, err := h.replace(""); err != nil {
+		log.Default().Errorf("can't restore %v: %v", h.Path, err.Error())
+	}
+}
+
+func (h *HostsBlockTarget) begin() string {
+	return "# BEGIN " + h.marker()
+}
+
+func (h *HostsBlockTarget) end() string {
+	return "# END " + h.marker()
+}
+
+func (h *HostsBlockTarget) marker() string {
+	if h.Marker == "" {
+		return DefaultHostsBlockMarker
+	}
+	return h.Marker
+}
+
+// replace writes the block in place of the managed block. The block is appended if the file has no managed block.
+func (h *HostsBlockTarget) replace(block string) (bool, error) {
+	// #nosec G304
+	current, err := os.ReadFile(h.Path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, errors.Wrapf(err, "can't read %v", h.Path)
+	}
+
+	var before, after = string(current), ""
+	if start := h.lineIndex(before, h.begin()); start >= 0 {
+		var rest = before[start:]
+		before = before[:start]
+		if end := h.lineIndex(rest, h.end()); end >= 0 {
+			after = rest[end+len(h.end()):]
+			after = strings.TrimPrefix(after, "\n")
+		}
+	}
+	if block != "" && before != "" && !strings.HasSuffix(before, "\n") {
+		before += "\n"
+	}
+
+	var next = before + block + after
+	if next == string(current) {
+		return false, nil
+	}
+	var mode = h.Mode
+	if mode == 0 {
+		mode = 0o644
+	}
+	if err = os.WriteFile(h.Path, []byte(next), mode); err != nil {
+		return false, errors.Wrapf(err, "can't write %v", h.Path)
+	}
+	return true, nil
+}
+
+// lineIndex returns the index of the line equal to line or -1
+func (h *HostsBlockTarget) lineIndex(content, line string) int {
+	for offset := 0; offset < len(content); {
+		var end = strings.IndexByte(content[offset:], '\n')
+		if end < 0 {
+			end = len(content) - offset
+		}
+		if strings.TrimSpace(content[offset:offset+end]) == line {
+			return offset
+		}
+		offset += end + 1
+	}
+	return -1
+}
@@ -0,0 +1,61 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapipwriter
+
+import (
+	"context"
+	"sync"
+)
+
+// Latest is a target keeping the latest snapshot for servers answering queries and watching changes of the map
+type Latest struct {
+	mu       sync.Mutex
+	snapshot *Snapshot
+	// changed is closed and replaced on each update of the snapshot
+	changed chan struct{}
+}
+
+// Write replaces the latest snapshot and notifies watchers. Returns false if the generation is not changed.
+func (l *Latest) Write(_ context.Context, snapshot *Snapshot) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.snapshot != nil && l.snapshot.Generation == snapshot.Generation {
+		return false, nil
+	}
+	l.snapshot = snapshot
+	if l.changed != nil {
+		close(l.changed)
+	}
+	l.changed = make(chan struct{})
+	return true, nil
+}
+
+// Current returns the latest snapshot and a channel closed on its change. The snapshot is empty before the first
+// write.
+func (l *Latest) Current() (*Snapshot, <-chan struct{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.changed == nil {
+		l.changed = make(chan struct{})
+	}
+	if l.snapshot == nil {
+		return new(Snapshot), l.changed
+	}
+	return l.snapshot, l.changed
+}
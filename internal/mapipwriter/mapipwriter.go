@@ -22,13 +22,20 @@ package mapipwriter
 import (
 	"context"
 	"fmt"
-	"os"
-	"path/filepath"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/edwarnicke/serialize"
-	"gopkg.in/yaml.v2"
+	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 	"k8s.io/apimachinery/pkg/watch"
 
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/metrics"
 	"github.com/networkservicemesh/sdk/pkg/tools/log"
 )
 
@@ -41,6 +48,15 @@ type Translation struct {
 type Event struct {
 	Translation
 	Type watch.EventType
+	// Zone and Region are the topology of the source node. They are used only as metric attributes.
+	Zone, Region string
+	// Node is the name of the node the translation is derived from. It's empty for other sources.
+	Node string
+	// Namespace is the namespace of the configmap or the IPTranslation the translation is read from if objects of all
+	// namespaces are watched. It's empty otherwise.
+	Namespace string
+	// Cluster is the identifier of the remote cluster the translation is derived from. It's empty for the local one.
+	Cluster string
 }
 
 func (e *Translation) String() string {
@@ -55,63 +71,475 @@ func (e *Translation) Reverse() Translation {
 	}
 }
 
+// Orders of entries of the map
+const (
+	// OrderSorted orders entries by From and To addresses. CIDRs follow single addresses ordered by the longest
+	// prefix first.
+	OrderSorted = "sorted"
+	// OrderInsertion orders entries by the time they were added to the map
+	OrderInsertion = "insertion"
+)
+
 // MapIPWriter writes IPs from the v1.Node into OutputPath
 type MapIPWriter struct {
-	OutputPath           string
+	OutputPath string
+	// Targets are destinations of the map. If it's empty then the map is written into OutputPath.
+	Targets []Target
+	// TransformTo is an optional transformation applied to the To address of each event
+	TransformTo func(to string) string
+	// Extended enables the extended output schema where each entry carries the To address and the original
+	// address before TransformTo
+	Extended bool
+	// SkipUnchanged skips writing of the file when its content is the same as the last written one
+	SkipUnchanged bool
+	// VerifyAfterWrite re-reads the file after writing and rewrites it if the content doesn't match
+	VerifyAfterWrite bool
+	// FollowSymlinks writes through OutputPath into the file it links to instead of replacing the link
+	FollowSymlinks bool
+	// WriteGeneration writes the generation of the map into a companion file next to OutputPath
+	WriteGeneration bool
+	// PostWriteCommand is an optional shell command executed after each successful write of a FileTarget. Writes of
+	// other targets don't run it.
+	PostWriteCommand string
+	// PostWriteTimeout limits the execution time of PostWriteCommand
+	PostWriteTimeout time.Duration
+	// CleanupTempFiles removes temporary files left in the output directory by previous runs on Start
+	CleanupTempFiles bool
+	// RetryInterval is the interval of retrying writes into targets that failed. Targets are retried independently
+	// of each other with the latest map. Failed targets are retried only on the next change of the map if it's zero.
+	RetryInterval time.Duration
+	// WriteDebounce is the window of coalescing bursts of events into a single write. Each event is written
+	// separately if it's zero.
+	WriteDebounce time.Duration
+	// Order is the order of entries passed to targets: OrderSorted or OrderInsertion. OrderSorted is used if it's empty.
+	Order string
+	// WriteMaxLatency bounds the delay of the write since the first event of a burst if WriteDebounce is set
+	WriteMaxLatency time.Duration
+	// WriteRateLimit is the max rate of writes per second caused by events. Writes over the limit are delayed and
+	// coalesced into a single write of the latest map. Writes are not limited if it's zero.
+	WriteRateLimit float64
+	// WriteBurst is the count of writes allowed over WriteRateLimit at once. One is used if it's zero.
+	WriteBurst int
+	// FlushTimeout bounds the final write of pending events when ctx of Start is done. Pending events are dropped on
+	// shutdown if it's zero.
+	FlushTimeout time.Duration
+	// EventsClosedOnShutdown means the channel of Start is closed by the sender once events pending when ctx is done
+	// are sent, e.g. by a queue, so the final write waits for it within FlushTimeout. Otherwise only events already in
+	// the channel are written.
+	EventsClosedOnShutdown bool
+	// Ready holds writes until it's closed, e.g. until sources are loaded after a restart, so consumers don't see a
+	// partial map. Events are applied meanwhile and the map is written once it's closed. Writes aren't held if it's nil.
+	Ready <-chan struct{}
+
+	metricsOnce          sync.Once
 	exec                 serialize.Executor
-	internalToExternalIP map[Translation]struct{} //TODO: use orderedmap
+	postWriteExec        serialize.Executor
+	internalToExternalIP orderedMap
+	entryCount           metric.Int64UpDownCounter
+	writeCount           metric.Int64Counter
+	writeDuration        metric.Float64Histogram
+	lastWrite            atomic.Int64
+	generation           uint64
+	writtenGeneration    uint64
+	failed               map[Target]struct{}
 }
 
-func (m *MapIPWriter) writeToFile(ctx context.Context) {
-	_ = os.MkdirAll(filepath.Dir(m.OutputPath), os.ModePerm)
+type entry struct {
+	original  string
+	node      string
+	namespace string
+	cluster   string
+	attrs     attribute.Set
+}
 
-	var outmap = make(map[string]string)
+// ErrRestart is the cause of cancellation of ctx of Start when MapIPWriter is restarted, e.g. with a new config.
+// Targets implementing Restorer are not restored then.
+var ErrRestart = errors.New("restart")
+
+// closeTargets restores targets implementing Restorer unless the writer is restarted and closes targets implementing
+// Closer
+func (m *MapIPWriter) closeTargets(ctx context.Context) {
+	var restart = errors.Is(context.Cause(ctx), ErrRestart)
+	for _, target := range m.targets() {
+		if restorer, ok := target.(Restorer); ok && !restart {
+			restorer.Restore()
+		}
+		if closer, ok := target.(Closer); ok {
+			closer.Close()
+		}
+	}
+}
 
-	for translation := range m.internalToExternalIP {
-		outmap[translation.From] = translation.To
+func (m *MapIPWriter) targets() []Target {
+	if len(m.Targets) == 0 {
+		m.Targets = []Target{&FileTarget{
+			Path:             m.OutputPath,
+			Extended:         m.Extended,
+			SkipUnchanged:    m.SkipUnchanged,
+			VerifyAfterWrite: m.VerifyAfterWrite,
+			FollowSymlinks:   m.FollowSymlinks,
+			WriteGeneration:  m.WriteGeneration,
+		}}
 	}
+	return m.Targets
+}
 
-	bytes, err := yaml.Marshal(outmap)
+func (m *MapIPWriter) snapshot() *Snapshot {
+	var result = &Snapshot{
+		Entries:    make([]Entry, 0, m.internalToExternalIP.len()),
+		Generation: m.generation,
+	}
+	m.internalToExternalIP.rangeInOrder(func(translation Translation, e entry) {
+		result.Entries = append(result.Entries, Entry{
+			Translation: translation,
+			Original:    e.original,
+			Node:        e.node,
+			Namespace:   e.namespace,
+			Cluster:     e.cluster,
+		})
+	})
+	if m.Order != OrderInsertion {
+		sort.SliceStable(result.Entries, func(i, j int) bool {
+			return lessEntries(&result.Entries[i], &result.Entries[j])
+		})
+	}
+	return result
+}
 
-	if err != nil {
-		log.FromContext(ctx).Errorf("an error during marshaling ips map: %v, err: %v", m.OutputPath, err.Error())
+func (m *MapIPWriter) write(ctx context.Context) {
+	if !m.ready() {
+		return
+	}
+	m.writtenGeneration = m.generation
+	m.writeTargets(ctx, m.targets())
+}
+
+func (m *MapIPWriter) ready() bool {
+	if m.Ready == nil {
+		return true
+	}
+	select {
+	case <-m.Ready:
+		return true
+	default:
+		return false
+	}
+}
+
+// flush applies events left in the channel and writes the map if it has changes not written yet or failed targets.
+// It doesn't block on the channel unless EventsClosedOnShutdown is set, so events sent after ctx is done may be missed.
+func (m *MapIPWriter) flush(ctx context.Context, eventCh <-chan Event) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), m.FlushTimeout)
+	defer cancel()
+
+	for {
+		var event Event
+		var ok bool
+		if m.EventsClosedOnShutdown {
+			select {
+			case event, ok = <-eventCh:
+			case <-ctx.Done():
+			}
+		} else {
+			select {
+			case event, ok = <-eventCh:
+			default:
+			}
+		}
+		if !ok {
+			break
+		}
+		m.apply(ctx, &event)
+	}
+	if m.generation == m.writtenGeneration && len(m.failed) == 0 {
 		return
 	}
+	log.FromContext(ctx).Infof("writing pending changes of the ips map on shutdown")
+	m.write(ctx)
+}
+
+// retryFailed writes the map into the targets that failed on the previous write
+func (m *MapIPWriter) retryFailed(ctx context.Context) {
+	var targets []Target
+	for _, target := range m.targets() {
+		if _, ok := m.failed[target]; ok {
+			targets = append(targets, target)
+		}
+	}
+	if len(targets) > 0 {
+		m.writeTargets(ctx, targets)
+	}
+}
+
+func (m *MapIPWriter) writeTargets(ctx context.Context, targets []Target) {
+	var snapshot = m.snapshot()
+	var fileWritten, updated bool
+
+	if m.failed == nil {
+		m.failed = make(map[Target]struct{})
+	}
+
+	for _, target := range targets {
+		var attrs = metric.WithAttributes(metrics.TargetKey.String(target.Name()))
+		var start = time.Now()
+
+		targetWritten, err := target.Write(ctx, snapshot)
+
+		m.writeDuration.Record(ctx, time.Since(start).Seconds(), attrs)
+		if err != nil {
+			m.writeCount.Add(ctx, 1, attrs, metric.WithAttributes(metrics.ResultKey.String(metrics.ResultFailure)))
+			log.FromContext(ctx).Errorf("an error during writing ips map: %v, err: %v", target.Name(), err.Error())
+			m.failed[target] = struct{}{}
+			continue
+		}
+		if _, ok := m.failed[target]; ok {
+			log.FromContext(ctx).Infof("ips map is written into %v after a failure", target.Name())
+			delete(m.failed, target)
+		}
+		m.writeCount.Add(ctx, 1, attrs, metric.WithAttributes(metrics.ResultKey.String(metrics.ResultSuccess)))
+		if _, ok := target.(*FileTarget); ok && targetWritten {
+			fileWritten = true
+		}
+		updated = true
+	}
+
+	if updated {
+		m.lastWrite.Store(time.Now().UnixNano())
+	}
+	if fileWritten && m.PostWriteCommand != "" {
+		m.postWriteExec.AsyncExec(func() {
+			m.runPostWriteCommand(ctx)
+		})
+	}
+}
 
-	err = os.WriteFile(m.OutputPath, bytes, os.ModePerm)
+func (m *MapIPWriter) runPostWriteCommand(ctx context.Context) {
+	var cmdCtx, cancel = context.WithCancel(ctx)
+	if m.PostWriteTimeout > 0 {
+		cmdCtx, cancel = context.WithTimeout(ctx, m.PostWriteTimeout)
+	}
+	defer cancel()
 
+	// #nosec G204
+	output, err := exec.CommandContext(cmdCtx, "/bin/sh", "-c", m.PostWriteCommand).CombinedOutput()
 	if err != nil {
-		log.FromContext(ctx).Errorf("an error during marshaling ips map: %v, err: %v", m.OutputPath, err.Error())
+		log.FromContext(ctx).Errorf("post-write command %q failed: %v, output: %s", m.PostWriteCommand, err.Error(), output)
+		return
+	}
+	log.FromContext(ctx).Infof("post-write command %q output: %s", m.PostWriteCommand, output)
+}
+
+func (m *MapIPWriter) apply(ctx context.Context, event *Event) {
+	var original string
+	if m.TransformTo != nil {
+		if to := m.TransformTo(event.To); to != event.To {
+			original, event.To = event.To, to
+		}
+	}
+
+	if event.Type == watch.Deleted {
+		m.delete(ctx, event.Translation)
+		return
+	}
+
+	if err := event.Validate(); err != nil {
+		log.FromContext(ctx).Warnf("entry is skipped: %v", err.Error())
+		return
+	}
+
+	var attrs = attribute.NewSet(metrics.TopologyAttributes(event.Zone, event.Region)...)
+	prev, exists := m.internalToExternalIP.load(event.Translation)
+	if exists {
+		m.entryCount.Add(ctx, -1, metric.WithAttributeSet(prev.attrs))
+	}
+	if !exists || prev.original != original || prev.node != event.Node || prev.namespace != event.Namespace || prev.cluster != event.Cluster {
+		m.generation++
+	}
+	m.internalToExternalIP.store(event.Translation, entry{
+		original:  original,
+		node:      event.Node,
+		namespace: event.Namespace,
+		cluster:   event.Cluster,
+		attrs:     attrs,
+	})
+	m.entryCount.Add(ctx, 1, metric.WithAttributeSet(attrs))
+	log.FromContext(ctx).Debugf("added entry: %v", event.String())
+}
+
+// delete deletes the entry of the translation from the map
+func (m *MapIPWriter) delete(ctx context.Context, translation Translation) {
+	log.FromContext(ctx).Debugf("deleted entry: %v", translation.String())
+	if prev, exists := m.internalToExternalIP.load(translation); exists {
+		m.entryCount.Add(ctx, -1, metric.WithAttributeSet(prev.attrs))
+		m.generation++
+	}
+	m.internalToExternalIP.delete(translation)
+}
+
+func (m *MapIPWriter) initMetrics(ctx context.Context) {
+	m.metricsOnce.Do(func() {
+		m.initInstruments(ctx)
+	})
+}
+
+func (m *MapIPWriter) initInstruments(ctx context.Context) {
+	m.entryCount = metrics.Int64UpDownCounter(ctx, metrics.EntriesName, metric.WithDescription("count of entries in the map"))
+	m.writeCount = metrics.Int64Counter(ctx, metrics.WritesName, metric.WithDescription("count of writes of the map per target"))
+	m.writeDuration = metrics.Float64Histogram(ctx, metrics.WriteDurationName,
+		metric.WithDescription("duration of writes of the map per target"), metric.WithUnit("s"))
+}
+
+// WriteOnce applies the events to the map and writes it into the targets once. It returns an error if any target
+// fails.
+func (m *MapIPWriter) WriteOnce(ctx context.Context, events []Event) error {
+	m.initMetrics(ctx)
+	for i := range events {
+		m.apply(ctx, &events[i])
+	}
+	m.write(ctx)
+
+	var failed []string
+	for _, target := range m.targets() {
+		if _, ok := m.failed[target]; ok {
+			failed = append(failed, target.Name())
+		}
 	}
+	if len(failed) > 0 {
+		return errors.Errorf("can't write ips map into %v", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// Reconcile replaces entries of the map with the events of objects of all sources listed from scratch and writes
+// the map if it's changed. Entries missed in events are deleted. It's safe to call concurrently with Start.
+func (m *MapIPWriter) Reconcile(ctx context.Context, events []Event) {
+	<-m.exec.AsyncExec(func() {
+		m.initMetrics(ctx)
+		var generation = m.generation
+		var listed = make(map[Translation]struct{}, len(events))
+		for i := range events {
+			if events[i].Type == watch.Deleted {
+				continue
+			}
+			var event = events[i]
+			m.apply(ctx, &event)
+			listed[event.Translation] = struct{}{}
+		}
+
+		var stale []Translation
+		m.internalToExternalIP.rangeInOrder(func(translation Translation, _ entry) {
+			if _, ok := listed[translation]; !ok {
+				stale = append(stale, translation)
+			}
+		})
+		for _, translation := range stale {
+			m.delete(ctx, translation)
+		}
+
+		if m.generation == generation {
+			log.FromContext(ctx).Debug("the map is reconciled without changes")
+			return
+		}
+		log.FromContext(ctx).Infof("the map is reconciled, %v stale entries are removed", len(stale))
+		m.write(ctx)
+	})
 }
 
-// Start starts reading events from the passed channel in the current goroutine
+// Start starts reading events from the passed channel in the current goroutine. When ctx is done, pending events are
+// written within FlushTimeout and targets are restored and closed before returning. Targets are not restored if the
+// cause of ctx is ErrRestart.
 func (m *MapIPWriter) Start(ctx context.Context, eventCh <-chan Event) {
+	if m.CleanupTempFiles {
+		m.removeStaleTempFiles(ctx)
+	}
+	m.initMetrics(ctx)
+	defer metrics.ObserveFloat64(ctx, metrics.LastWriteName, "unix timestamp of the last write of the map", func() float64 {
+		return float64(m.lastWrite.Load()) / float64(time.Second)
+	})()
+
+	var retryCh <-chan time.Time
+	if m.RetryInterval > 0 {
+		var ticker = time.NewTicker(m.RetryInterval)
+		defer ticker.Stop()
+		retryCh = ticker.C
+	}
+
+	var debounce = debouncer{window: m.WriteDebounce, maxLatency: m.WriteMaxLatency}
+	defer debounce.stop()
+
+	var limiter = newWriteLimiter(m.WriteRateLimit, m.WriteBurst)
+	defer limiter.stop()
+
+	var ready = m.Ready
 	for {
 		select {
 		case <-ctx.Done():
+			m.shutdown(ctx, eventCh)
 			return
-		case event, ok := <-eventCh:
-			if !ok {
-				continue
+		case <-ready:
+			ready = nil
+			m.asyncWrite(ctx)
+		case <-debounce.C():
+			debounce.fired()
+			if limiter.allow() {
+				m.asyncWrite(ctx)
 			}
+		case <-limiter.C():
+			limiter.fired()
+			m.asyncWrite(ctx)
+		case <-retryCh:
 			m.exec.AsyncExec(func() {
-				if m.internalToExternalIP == nil {
-					m.internalToExternalIP = make(map[Translation]struct{})
-				}
-				switch event.Type {
-				case watch.Deleted:
-					log.FromContext(ctx).Debugf("deleted entry: %v", event.String())
-					delete(m.internalToExternalIP, event.Translation)
-
-				default:
-					m.internalToExternalIP[event.Translation] = struct{}{}
-					log.FromContext(ctx).Debugf("added entry: %v", event.String())
-				}
-				m.exec.AsyncExec(func() {
-					m.writeToFile(ctx)
-				})
+				m.retryFailed(ctx)
 			})
+		case event, ok := <-eventCh:
+			if ok {
+				m.dispatch(ctx, event, &debounce, limiter)
+			}
+		}
+	}
+}
+
+// removeStaleTempFiles removes temporary files left by previous runs in directories of file targets
+func (m *MapIPWriter) removeStaleTempFiles(ctx context.Context) {
+	for _, target := range m.targets() {
+		if fileTarget, ok := target.(*FileTarget); ok {
+			fileTarget.removeStaleTempFiles(ctx)
+		}
+	}
+}
+
+// asyncWrite schedules a write of the map
+func (m *MapIPWriter) asyncWrite(ctx context.Context) {
+	m.exec.AsyncExec(func() {
+		m.write(ctx)
+	})
+}
+
+// dispatch schedules applying the event to the map. The map is written right after it, after the debounce window or
+// within the rate limit.
+func (m *MapIPWriter) dispatch(ctx context.Context, event Event, debounce *debouncer, limiter *writeLimiter) {
+	m.exec.AsyncExec(func() {
+		m.apply(ctx, &event)
+		if m.WriteDebounce == 0 && limiter == nil {
+			m.asyncWrite(ctx)
 		}
+	})
+	switch {
+	case m.WriteDebounce > 0:
+		debounce.trigger()
+	case limiter != nil && limiter.allow():
+		m.asyncWrite(ctx)
+	}
+}
+
+// shutdown writes pending events within FlushTimeout and restores and closes targets
+func (m *MapIPWriter) shutdown(ctx context.Context, eventCh <-chan Event) {
+	if m.FlushTimeout > 0 {
+		<-m.exec.AsyncExec(func() {
+			m.flush(ctx, eventCh)
+		})
 	}
+	<-m.exec.AsyncExec(func() {
+		m.closeTargets(ctx)
+	})
 }
@@ -19,19 +19,34 @@
 package mapipwriter_test
 
 import (
+	"compress/gzip"
 	"context"
+	"encoding/json"
+	"fmt"
+	"io"
 	"os"
 
 	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	logrustest "github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 	"go.uber.org/goleak"
+	"gopkg.in/yaml.v2"
 	"k8s.io/apimachinery/pkg/watch"
 
 	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/remap"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+	"github.com/networkservicemesh/sdk/pkg/tools/log/logruslogger"
 )
 
 func Test_MapWriter(t *testing.T) {
@@ -94,3 +109,1169 @@ func Test_MapWriter(t *testing.T) {
 		return s == "127.0.0.1: 148.142.120.1"
 	}, time.Second, time.Millisecond*100)
 }
+
+func Test_MapWriterExtendedWithTransform(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	outputFile := filepath.Join(t.TempDir(), "output.yaml")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancel()
+
+	r, err := remap.Parse("10.0.0.0/8=192.0.0.0/8")
+	require.NoError(t, err)
+
+	var writer = mapipwriter.MapIPWriter{
+		OutputPath:  outputFile,
+		TransformTo: r.Apply,
+		Extended:    true,
+	}
+
+	var eventCh = make(chan mapipwriter.Event)
+
+	go writer.Start(ctx, eventCh)
+
+	eventCh <- mapipwriter.Event{
+		Type: watch.Added,
+		Translation: mapipwriter.Translation{
+			From: "172.16.0.1",
+			To:   "10.1.2.3",
+		},
+	}
+
+	require.Eventually(t, func() bool {
+		// #nosec
+		b, readErr := os.ReadFile(outputFile)
+		if readErr != nil {
+			return false
+		}
+		var m map[string]map[string]string
+		if yaml.Unmarshal(b, &m) != nil {
+			return false
+		}
+		return m["172.16.0.1"]["to"] == "192.1.2.3" && m["172.16.0.1"]["original"] == "10.1.2.3"
+	}, time.Second, time.Millisecond*100)
+}
+
+func Test_MapWriterSkipUnchanged(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	var reader = sdkmetric.NewManualReader()
+	var prevProvider = otel.GetMeterProvider()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	defer otel.SetMeterProvider(prevProvider)
+
+	outputFile := filepath.Join(t.TempDir(), "output.yaml")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancel()
+
+	var writer = mapipwriter.MapIPWriter{
+		OutputPath:    outputFile,
+		SkipUnchanged: true,
+	}
+
+	var eventCh = make(chan mapipwriter.Event)
+
+	go writer.Start(ctx, eventCh)
+
+	var event = mapipwriter.Event{
+		Type: watch.Added,
+		Translation: mapipwriter.Translation{
+			From: "127.0.0.1",
+			To:   "148.142.120.1",
+		},
+	}
+
+	eventCh <- event
+
+	require.Eventually(t, func() bool {
+		return lastWrite(t, reader) > 0
+	}, time.Second, time.Millisecond*100)
+
+	var epoch = time.Unix(0, 0)
+	require.NoError(t, os.Chtimes(outputFile, epoch, epoch))
+	var prevWrite = lastWrite(t, reader)
+
+	eventCh <- event
+
+	require.Eventually(t, func() bool {
+		return lastWrite(t, reader) > prevWrite
+	}, time.Second, time.Millisecond*100)
+
+	info, err := os.Stat(outputFile)
+	require.NoError(t, err)
+	require.True(t, info.ModTime().Equal(epoch))
+}
+
+func Test_MapWriterPostWriteCommand(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	outputFile := filepath.Join(t.TempDir(), "output.yaml")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancel()
+
+	var hook = logrustest.NewGlobal()
+	defer logrus.StandardLogger().ReplaceHooks(make(logrus.LevelHooks))
+	ctx = log.WithLog(ctx, logruslogger.New(ctx))
+
+	var writer = mapipwriter.MapIPWriter{
+		OutputPath:       outputFile,
+		PostWriteCommand: "cat " + outputFile,
+		PostWriteTimeout: time.Second,
+	}
+
+	var eventCh = make(chan mapipwriter.Event)
+
+	go writer.Start(ctx, eventCh)
+
+	eventCh <- mapipwriter.Event{
+		Type: watch.Added,
+		Translation: mapipwriter.Translation{
+			From: "127.0.0.1",
+			To:   "148.142.120.1",
+		},
+	}
+
+	require.Eventually(t, func() bool {
+		for _, entry := range hook.AllEntries() {
+			if entry.Level == logrus.InfoLevel &&
+				strings.Contains(entry.Message, "post-write command") &&
+				strings.Contains(entry.Message, "127.0.0.1: 148.142.120.1") {
+				return true
+			}
+		}
+		return false
+	}, time.Second, time.Millisecond*100)
+}
+
+func Test_MapWriterPostWriteCommandSkipsOtherTargets(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	var dir = t.TempDir()
+	var marker = filepath.Join(dir, "marker")
+	stream, err := os.Create(filepath.Join(dir, "stream.yaml"))
+	require.NoError(t, err)
+	defer func() { _ = stream.Close() }()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancel()
+
+	var writer = mapipwriter.MapIPWriter{
+		Targets:          []mapipwriter.Target{&mapipwriter.StreamTarget{Writer: stream}},
+		PostWriteCommand: "touch " + marker,
+		PostWriteTimeout: time.Second,
+	}
+
+	var eventCh = make(chan mapipwriter.Event)
+
+	go writer.Start(ctx, eventCh)
+
+	eventCh <- mapipwriter.Event{
+		Type: watch.Added,
+		Translation: mapipwriter.Translation{
+			From: "127.0.0.1",
+			To:   "148.142.120.1",
+		},
+	}
+
+	require.Eventually(t, func() bool {
+		b, readErr := os.ReadFile(stream.Name())
+		return readErr == nil && strings.Contains(string(b), "127.0.0.1: 148.142.120.1")
+	}, time.Second, time.Millisecond*100)
+	require.Never(t, func() bool {
+		_, statErr := os.Stat(marker)
+		return statErr == nil
+	}, time.Millisecond*300, time.Millisecond*50)
+}
+
+func Test_MapWriterRemovesStaleTempFiles(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	outputFile := filepath.Join(t.TempDir(), "output.yaml")
+	staleFile := outputFile + ".tmp-12345"
+	require.NoError(t, os.WriteFile(staleFile, []byte("partial"), 0o600))
+	require.NoError(t, os.Chtimes(staleFile, time.Now().Add(-time.Hour), time.Now().Add(-time.Hour)))
+	inFlightFile := outputFile + ".tmp-67890"
+	require.NoError(t, os.WriteFile(inFlightFile, []byte("partial"), 0o600))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancel()
+
+	var writer = mapipwriter.MapIPWriter{
+		OutputPath:       outputFile,
+		CleanupTempFiles: true,
+	}
+
+	var eventCh = make(chan mapipwriter.Event)
+
+	go writer.Start(ctx, eventCh)
+
+	eventCh <- mapipwriter.Event{
+		Type: watch.Added,
+		Translation: mapipwriter.Translation{
+			From: "127.0.0.1",
+			To:   "148.142.120.1",
+		},
+	}
+
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(outputFile)
+		return err == nil
+	}, time.Second, time.Millisecond*100)
+
+	_, err := os.Stat(staleFile)
+	require.True(t, os.IsNotExist(err))
+
+	matches, err := filepath.Glob(outputFile + ".tmp-*")
+	require.NoError(t, err)
+	require.Equal(t, []string{inFlightFile}, matches, "temporary files of other writers in flight are kept")
+}
+
+func Test_MapWriterPerTargetMetrics(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	var reader = sdkmetric.NewManualReader()
+	var prevProvider = otel.GetMeterProvider()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	defer otel.SetMeterProvider(prevProvider)
+
+	var dir = t.TempDir()
+	var goodFile = filepath.Join(dir, "good.yaml")
+	var notADir = filepath.Join(dir, "file")
+	require.NoError(t, os.WriteFile(notADir, nil, 0o600))
+	var badFile = filepath.Join(notADir, "bad.yaml")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancel()
+
+	var writer = mapipwriter.MapIPWriter{
+		Targets: []mapipwriter.Target{
+			&mapipwriter.FileTarget{Path: goodFile},
+			&mapipwriter.FileTarget{Path: badFile},
+		},
+	}
+
+	var eventCh = make(chan mapipwriter.Event)
+
+	go writer.Start(ctx, eventCh)
+
+	for _, from := range []string{"1.1.1.1", "1.1.1.2"} {
+		eventCh <- mapipwriter.Event{
+			Type: watch.Added,
+			Translation: mapipwriter.Translation{
+				From: from,
+				To:   "2.2.2.2",
+			},
+		}
+	}
+
+	require.Eventually(t, func() bool {
+		return writeCount(t, reader, goodFile, "success") == 2 && writeCount(t, reader, badFile, "failure") == 2
+	}, time.Second, time.Millisecond*100)
+
+	require.Zero(t, writeCount(t, reader, goodFile, "failure"))
+	require.Zero(t, writeCount(t, reader, badFile, "success"))
+}
+
+func Test_FileTargetVerifyAfterWrite(t *testing.T) {
+	outputFile := filepath.Join(t.TempDir(), "output.yaml")
+
+	var attempts int
+	var target = mapipwriter.FileTarget{
+		Path:             outputFile,
+		VerifyAfterWrite: true,
+		WriteFile: func(path string, data []byte) error {
+			attempts++
+			if attempts == 1 {
+				// simulate silent corruption of the storage
+				data = []byte("corrupted")
+			}
+			return os.WriteFile(path, data, 0o600)
+		},
+	}
+
+	written, err := target.Write(context.Background(), &mapipwriter.Snapshot{
+		Entries: []mapipwriter.Entry{
+			{Translation: mapipwriter.Translation{From: "127.0.0.1", To: "148.142.120.1"}},
+		},
+	})
+	require.NoError(t, err)
+	require.True(t, written)
+	require.Equal(t, 2, attempts)
+
+	// #nosec
+	b, err := os.ReadFile(outputFile)
+	require.NoError(t, err)
+	require.Equal(t, "127.0.0.1: 148.142.120.1", strings.TrimSpace(string(b)))
+}
+
+func Test_FileTargetFollowSymlinks(t *testing.T) {
+	var dir = t.TempDir()
+	var realFile = filepath.Join(dir, "real.yaml")
+	var link = filepath.Join(dir, "output.yaml")
+	require.NoError(t, os.WriteFile(realFile, nil, 0o600))
+	require.NoError(t, os.Symlink(realFile, link))
+
+	var target = mapipwriter.FileTarget{
+		Path:           link,
+		FollowSymlinks: true,
+	}
+
+	_, err := target.Write(context.Background(), &mapipwriter.Snapshot{
+		Entries: []mapipwriter.Entry{
+			{Translation: mapipwriter.Translation{From: "127.0.0.1", To: "148.142.120.1"}},
+		},
+	})
+	require.NoError(t, err)
+
+	info, err := os.Lstat(link)
+	require.NoError(t, err)
+	require.NotZero(t, info.Mode()&os.ModeSymlink)
+
+	// #nosec
+	b, err := os.ReadFile(realFile)
+	require.NoError(t, err)
+	require.Equal(t, "127.0.0.1: 148.142.120.1", strings.TrimSpace(string(b)))
+}
+
+func Test_MapWriterGeneration(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	outputFile := filepath.Join(t.TempDir(), "output.yaml")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancel()
+
+	var writer = mapipwriter.MapIPWriter{
+		OutputPath:      outputFile,
+		SkipUnchanged:   true,
+		WriteGeneration: true,
+	}
+
+	var eventCh = make(chan mapipwriter.Event)
+
+	go writer.Start(ctx, eventCh)
+
+	var generation = func() string {
+		// #nosec
+		b, _ := os.ReadFile(outputFile + mapipwriter.GenerationSuffix)
+		return strings.TrimSpace(string(b))
+	}
+
+	var first = mapipwriter.Event{
+		Type:        watch.Added,
+		Translation: mapipwriter.Translation{From: "1.1.1.1", To: "2.2.2.2"},
+	}
+
+	eventCh <- first
+	require.Eventually(t, func() bool {
+		return generation() == "1"
+	}, time.Second, time.Millisecond*100)
+
+	// the same entry doesn't change the map, so the generation is stable
+	eventCh <- first
+	eventCh <- mapipwriter.Event{
+		Type:        watch.Added,
+		Translation: mapipwriter.Translation{From: "1.1.1.2", To: "2.2.2.2"},
+	}
+	require.Eventually(t, func() bool {
+		return generation() == "2"
+	}, time.Second, time.Millisecond*100)
+
+	eventCh <- first
+	require.Never(t, func() bool {
+		return generation() != "2"
+	}, time.Millisecond*300, time.Millisecond*50)
+}
+
+func writeCount(t *testing.T, reader sdkmetric.Reader, target, result string) int64 {
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+
+	var count int64
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			sum, ok := m.Data.(metricdata.Sum[int64])
+			if !ok || m.Name != "map_ip_writes" {
+				continue
+			}
+			for _, dp := range sum.DataPoints {
+				var set = dp.Attributes
+				if v, _ := set.Value("target"); v.AsString() != target {
+					continue
+				}
+				if v, _ := set.Value("result"); v.AsString() != result {
+					continue
+				}
+				count += dp.Value
+			}
+		}
+	}
+	return count
+}
+
+func lastWrite(t *testing.T, reader sdkmetric.Reader) float64 {
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if gauge, ok := m.Data.(metricdata.Gauge[float64]); ok && m.Name == "map_ip_last_write_timestamp_seconds" {
+				for _, dp := range gauge.DataPoints {
+					return dp.Value
+				}
+			}
+		}
+	}
+	return 0
+}
+
+func Test_FileTargetHostsFormat(t *testing.T) {
+	outputFile := filepath.Join(t.TempDir(), "hosts")
+
+	render, err := mapipwriter.NewHostsRenderer(mapipwriter.DefaultHostsColumns)
+	require.NoError(t, err)
+
+	var target = mapipwriter.FileTarget{
+		Path:   outputFile,
+		Render: render,
+	}
+
+	_, err = target.Write(context.Background(), &mapipwriter.Snapshot{
+		Entries: []mapipwriter.Entry{
+			{Translation: mapipwriter.Translation{From: "127.0.0.1", To: "148.142.120.1"}},
+			{Translation: mapipwriter.Translation{From: "127.0.0.2", To: "148.142.120.2"}},
+			{Translation: mapipwriter.Translation{From: "FD00::1", To: "2001:db8::1"}},
+			{Translation: mapipwriter.Translation{From: "10.0.0.0/24", To: "148.142.121.0/24"}},
+		},
+	})
+	require.NoError(t, err)
+
+	// #nosec
+	b, err := os.ReadFile(outputFile)
+	require.NoError(t, err)
+	require.Equal(t, "148.142.120.1\t127.0.0.1\n148.142.120.2\t127.0.0.2\n2001:db8::1\tfd00--1\n", string(b))
+
+	_, err = mapipwriter.NewHostsRenderer("to,unknown")
+	require.Error(t, err)
+}
+
+func Test_CoreDNSHostsRenderer(t *testing.T) {
+	var snapshot = &mapipwriter.Snapshot{
+		Entries: []mapipwriter.Entry{
+			{Translation: mapipwriter.Translation{From: "127.0.0.1", To: "148.142.120.1"}},
+			{Translation: mapipwriter.Translation{From: "fd00::1", To: "2001:db8::1"}},
+		},
+	}
+
+	b, err := mapipwriter.NewCoreDNSHostsRenderer("ipmap.local.", false)(snapshot)
+	require.NoError(t, err)
+	require.Equal(t, "# generated by cmd-map-ip-k8s\n"+
+		"148.142.120.1\t127.0.0.1.ipmap.local\n"+
+		"2001:db8::1\tfd00--1.ipmap.local\n", string(b))
+
+	b, err = mapipwriter.NewCoreDNSHostsRenderer("", true)(snapshot)
+	require.NoError(t, err)
+	require.Equal(t, "# generated by cmd-map-ip-k8s\n"+
+		"148.142.120.1\t127.0.0.1\n"+
+		"127.0.0.1\t148.142.120.1\n"+
+		"2001:db8::1\tfd00--1\n"+
+		"fd00::1\t2001-db8--1\n", string(b))
+}
+
+func Test_MapWriterRetriesFailedTargetsIndependently(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	var dir = t.TempDir()
+	var healthy = filepath.Join(dir, "healthy.yaml")
+	var broken = filepath.Join(dir, "broken.yaml")
+
+	var brokenWrites atomic.Int32
+	var healthyWrites atomic.Int32
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancel()
+
+	var writer = mapipwriter.MapIPWriter{
+		RetryInterval: time.Millisecond * 50,
+		Targets: []mapipwriter.Target{
+			&mapipwriter.FileTarget{
+				Path: healthy,
+				WriteFile: func(path string, data []byte) error {
+					healthyWrites.Add(1)
+					return os.WriteFile(path, data, 0o600)
+				},
+			},
+			&mapipwriter.FileTarget{
+				Path: broken,
+				WriteFile: func(path string, data []byte) error {
+					if brokenWrites.Add(1) < 3 {
+						return errors.New("volume is not ready")
+					}
+					return os.WriteFile(path, data, 0o600)
+				},
+			},
+		},
+	}
+
+	var eventCh = make(chan mapipwriter.Event)
+	go writer.Start(ctx, eventCh)
+
+	eventCh <- mapipwriter.Event{
+		Type:        watch.Added,
+		Translation: mapipwriter.Translation{From: "127.0.0.1", To: "148.142.120.1"},
+	}
+
+	require.Eventually(t, func() bool {
+		// #nosec
+		b, err := os.ReadFile(broken)
+		return err == nil && strings.TrimSpace(string(b)) == "127.0.0.1: 148.142.120.1"
+	}, time.Second, time.Millisecond*10)
+
+	require.Equal(t, int32(1), healthyWrites.Load())
+	require.Equal(t, int32(3), brokenWrites.Load())
+}
+
+func Test_FileTargetFsync(t *testing.T) {
+	outputFile := filepath.Join(t.TempDir(), "output.yaml")
+
+	var target = mapipwriter.FileTarget{
+		Path:  outputFile,
+		Fsync: true,
+	}
+
+	written, err := target.Write(context.Background(), &mapipwriter.Snapshot{
+		Entries: []mapipwriter.Entry{
+			{Translation: mapipwriter.Translation{From: "127.0.0.1", To: "148.142.120.1"}},
+		},
+	})
+	require.NoError(t, err)
+	require.True(t, written)
+
+	// #nosec
+	b, err := os.ReadFile(outputFile)
+	require.NoError(t, err)
+	require.Equal(t, "127.0.0.1: 148.142.120.1", strings.TrimSpace(string(b)))
+
+	matches, err := filepath.Glob(outputFile + ".tmp-*")
+	require.NoError(t, err)
+	require.Empty(t, matches)
+}
+
+func Test_MapWriterDebounce(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	outputFile := filepath.Join(t.TempDir(), "output.yaml")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancel()
+
+	var writes atomic.Int32
+	var writer = mapipwriter.MapIPWriter{
+		WriteDebounce:   time.Millisecond * 200,
+		WriteMaxLatency: time.Second,
+		Targets: []mapipwriter.Target{
+			&mapipwriter.FileTarget{
+				Path: outputFile,
+				WriteFile: func(path string, data []byte) error {
+					writes.Add(1)
+					return os.WriteFile(path, data, 0o600)
+				},
+			},
+		},
+	}
+
+	var eventCh = make(chan mapipwriter.Event)
+	go writer.Start(ctx, eventCh)
+
+	const count = 100
+	for i := 0; i < count; i++ {
+		eventCh <- mapipwriter.Event{
+			Type:        watch.Added,
+			Translation: mapipwriter.Translation{From: fmt.Sprintf("10.0.0.%v", i), To: "148.142.120.1"},
+		}
+	}
+
+	require.Eventually(t, func() bool {
+		// #nosec
+		b, err := os.ReadFile(outputFile)
+		if err != nil {
+			return false
+		}
+		var m map[string]string
+		return yaml.Unmarshal(b, &m) == nil && len(m) == count
+	}, time.Second, time.Millisecond*10)
+
+	require.Equal(t, int32(1), writes.Load())
+}
+
+func Test_MapWriterFlushOnShutdown(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	outputFile := filepath.Join(t.TempDir(), "output.yaml")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var writer = mapipwriter.MapIPWriter{
+		OutputPath:    outputFile,
+		WriteDebounce: time.Hour,
+		FlushTimeout:  time.Second,
+	}
+
+	const count = 10
+	var eventCh = make(chan mapipwriter.Event, count)
+	var done = make(chan struct{})
+	go func() {
+		defer close(done)
+		writer.Start(ctx, eventCh)
+	}()
+
+	for i := 0; i < count; i++ {
+		eventCh <- mapipwriter.Event{
+			Type:        watch.Added,
+			Translation: mapipwriter.Translation{From: fmt.Sprintf("10.0.0.%v", i), To: "148.142.120.1"},
+		}
+	}
+	cancel()
+	<-done
+
+	// #nosec
+	b, err := os.ReadFile(outputFile)
+	require.NoError(t, err)
+	var m map[string]string
+	require.NoError(t, yaml.Unmarshal(b, &m))
+	require.Len(t, m, count)
+}
+
+func Test_FileTargetOnClose(t *testing.T) {
+	var snapshot = &mapipwriter.Snapshot{
+		Entries: []mapipwriter.Entry{
+			{Translation: mapipwriter.Translation{From: "127.0.0.1", To: "148.142.120.1"}},
+		},
+		Generation: 3,
+	}
+
+	outputFile := filepath.Join(t.TempDir(), "output.yaml")
+	var target = &mapipwriter.FileTarget{Path: outputFile, OnClose: mapipwriter.OnCloseStale}
+	_, err := target.Write(context.Background(), snapshot)
+	require.NoError(t, err)
+	target.Restore()
+
+	// #nosec
+	b, err := os.ReadFile(outputFile + mapipwriter.StaleSuffix)
+	require.NoError(t, err)
+	require.Equal(t, "3\n", string(b))
+	// #nosec
+	b, err = os.ReadFile(outputFile)
+	require.NoError(t, err)
+	require.Equal(t, "127.0.0.1: 148.142.120.1", strings.TrimSpace(string(b)))
+
+	_, err = target.Write(context.Background(), snapshot)
+	require.NoError(t, err)
+	require.NoFileExists(t, outputFile+mapipwriter.StaleSuffix)
+
+	target.OnClose = mapipwriter.OnCloseTruncate
+	target.Restore()
+	// #nosec
+	b, err = os.ReadFile(outputFile)
+	require.NoError(t, err)
+	require.Empty(t, b)
+}
+
+func Test_MapWriterRateLimit(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	outputFile := filepath.Join(t.TempDir(), "output.yaml")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancel()
+
+	var writes atomic.Int32
+	var writer = mapipwriter.MapIPWriter{
+		WriteRateLimit: 5,
+		Targets: []mapipwriter.Target{
+			&mapipwriter.FileTarget{
+				Path: outputFile,
+				WriteFile: func(path string, data []byte) error {
+					writes.Add(1)
+					return os.WriteFile(path, data, 0o600)
+				},
+			},
+		},
+	}
+
+	var eventCh = make(chan mapipwriter.Event)
+	go writer.Start(ctx, eventCh)
+
+	const count = 50
+	for i := 0; i < count; i++ {
+		eventCh <- mapipwriter.Event{
+			Type:        watch.Added,
+			Translation: mapipwriter.Translation{From: fmt.Sprintf("10.0.0.%v", i), To: "148.142.120.1"},
+		}
+	}
+
+	require.Eventually(t, func() bool {
+		// #nosec
+		b, err := os.ReadFile(outputFile)
+		if err != nil {
+			return false
+		}
+		var m map[string]string
+		return yaml.Unmarshal(b, &m) == nil && len(m) == count
+	}, time.Second, time.Millisecond*10)
+
+	require.LessOrEqual(t, writes.Load(), int32(3))
+}
+
+func Test_FileTargetPermissions(t *testing.T) {
+	outputFile := filepath.Join(t.TempDir(), "dir", "output.yaml")
+
+	owner, err := mapipwriter.ParseFileOwner(fmt.Sprintf("%v:%v", os.Getuid(), os.Getgid()))
+	require.NoError(t, err)
+
+	var target = mapipwriter.FileTarget{
+		Path:    outputFile,
+		Mode:    0o640,
+		DirMode: 0o750,
+		Owner:   owner,
+	}
+
+	_, err = target.Write(context.Background(), &mapipwriter.Snapshot{
+		Entries: []mapipwriter.Entry{
+			{Translation: mapipwriter.Translation{From: "127.0.0.1", To: "148.142.120.1"}},
+		},
+	})
+	require.NoError(t, err)
+
+	info, err := os.Stat(outputFile)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o640), info.Mode().Perm())
+
+	info, err = os.Stat(filepath.Dir(outputFile))
+	require.NoError(t, err)
+	require.Zero(t, info.Mode().Perm()&^0o750)
+
+	_, err = mapipwriter.ParseFileOwner("nobody")
+	require.Error(t, err)
+}
+
+func Test_MapWriterInsertionOrder(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	outputFile := filepath.Join(t.TempDir(), "output.yaml")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancel()
+
+	var writer = mapipwriter.MapIPWriter{
+		OutputPath: outputFile,
+		Order:      mapipwriter.OrderInsertion,
+	}
+
+	var eventCh = make(chan mapipwriter.Event)
+	go writer.Start(ctx, eventCh)
+
+	for _, from := range []string{"10.0.0.3", "10.0.0.1", "10.0.0.2"} {
+		eventCh <- mapipwriter.Event{
+			Type:        watch.Added,
+			Translation: mapipwriter.Translation{From: from, To: "148.142.120.1"},
+		}
+	}
+	eventCh <- mapipwriter.Event{
+		Type:        watch.Deleted,
+		Translation: mapipwriter.Translation{From: "10.0.0.1", To: "148.142.120.1"},
+	}
+	eventCh <- mapipwriter.Event{
+		Type:        watch.Added,
+		Translation: mapipwriter.Translation{From: "10.0.0.1", To: "148.142.120.1"},
+	}
+
+	require.Eventually(t, func() bool {
+		// #nosec
+		b, err := os.ReadFile(outputFile)
+		return err == nil && string(b) == "10.0.0.3: 148.142.120.1\n10.0.0.2: 148.142.120.1\n10.0.0.1: 148.142.120.1\n"
+	}, time.Second, time.Millisecond*10)
+}
+
+func Test_MapWriterReconcile(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	outputFile := filepath.Join(t.TempDir(), "output.yaml")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancel()
+
+	var writer = mapipwriter.MapIPWriter{
+		OutputPath: outputFile,
+	}
+
+	var eventCh = make(chan mapipwriter.Event)
+	go writer.Start(ctx, eventCh)
+
+	for _, from := range []string{"10.0.0.1", "10.0.0.2"} {
+		eventCh <- mapipwriter.Event{
+			Type:        watch.Added,
+			Translation: mapipwriter.Translation{From: from, To: "148.142.120.1"},
+		}
+	}
+	require.Eventually(t, func() bool {
+		// #nosec
+		b, err := os.ReadFile(outputFile)
+		return err == nil && string(b) == "10.0.0.1: 148.142.120.1\n10.0.0.2: 148.142.120.1\n"
+	}, time.Second, time.Millisecond*10)
+
+	writer.Reconcile(ctx, []mapipwriter.Event{
+		{Type: watch.Added, Translation: mapipwriter.Translation{From: "10.0.0.2", To: "148.142.120.1"}},
+		{Type: watch.Added, Translation: mapipwriter.Translation{From: "10.0.0.3", To: "148.142.120.1"}},
+	})
+
+	// #nosec
+	b, err := os.ReadFile(outputFile)
+	require.NoError(t, err)
+	require.Equal(t, "10.0.0.2: 148.142.120.1\n10.0.0.3: 148.142.120.1\n", string(b))
+}
+
+func Test_FileTargetHeader(t *testing.T) {
+	outputFile := filepath.Join(t.TempDir(), "output.yaml")
+
+	var target = mapipwriter.FileTarget{
+		Path:          outputFile,
+		Header:        true,
+		NodeName:      "node-1",
+		SkipUnchanged: true,
+	}
+
+	var snapshot = &mapipwriter.Snapshot{
+		Generation: 7,
+		Entries: []mapipwriter.Entry{
+			{Translation: mapipwriter.Translation{From: "127.0.0.1", To: "148.142.120.1"}},
+		},
+	}
+	written, err := target.Write(context.Background(), snapshot)
+	require.NoError(t, err)
+	require.True(t, written)
+
+	// #nosec
+	b, err := os.ReadFile(outputFile)
+	require.NoError(t, err)
+
+	var lines = strings.Split(strings.TrimSpace(string(b)), "\n")
+	require.Len(t, lines, 4)
+	require.Equal(t, "# generation: 7", lines[0])
+	require.True(t, strings.HasPrefix(lines[1], "# timestamp: "))
+	_, err = time.Parse(time.RFC3339Nano, strings.TrimPrefix(lines[1], "# timestamp: "))
+	require.NoError(t, err)
+	require.Equal(t, "# node: node-1", lines[2])
+
+	var m map[string]string
+	require.NoError(t, yaml.Unmarshal(b, &m))
+	require.Equal(t, map[string]string{"127.0.0.1": "148.142.120.1"}, m)
+
+	written, err = target.Write(context.Background(), snapshot)
+	require.NoError(t, err)
+	require.False(t, written)
+}
+
+func Test_MapWriterCIDRTranslations(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	outputFile := filepath.Join(t.TempDir(), "output.yaml")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*3)
+	defer cancel()
+
+	var writer = mapipwriter.MapIPWriter{
+		OutputPath: outputFile,
+	}
+
+	var eventCh = make(chan mapipwriter.Event)
+	go writer.Start(ctx, eventCh)
+
+	for _, translation := range []mapipwriter.Translation{
+		{From: "10.0.0.0/16", To: "148.142.120.1"},
+		{From: "10.0.1.0/24", To: "192.168.1.0/24"},
+		{From: "10.0.1.5", To: "148.142.120.5"},
+		// invalid: different prefix length
+		{From: "10.0.2.0/24", To: "192.168.0.0/16"},
+		// invalid: different family
+		{From: "10.0.3.0/24", To: "2001:db8::1"},
+	} {
+		eventCh <- mapipwriter.Event{Type: watch.Added, Translation: translation}
+	}
+
+	require.Eventually(t, func() bool {
+		// #nosec
+		b, err := os.ReadFile(outputFile)
+		return err == nil && string(b) == "10.0.1.5: 148.142.120.5\n10.0.1.0/24: 192.168.1.0/24\n10.0.0.0/16: 148.142.120.1\n"
+	}, time.Second, time.Millisecond*10)
+}
+
+func Test_TemplateRenderer(t *testing.T) {
+	render, err := mapipwriter.NewTemplateRenderer(
+		"# generation {{ .Generation }}\n{{ range .Entries }}{{ .From }} -> {{ .To }}{{ with .Original }} ({{ . }}){{ end }}\n{{ end }}")
+	require.NoError(t, err)
+
+	b, err := render(&mapipwriter.Snapshot{
+		Generation: 3,
+		Entries: []mapipwriter.Entry{
+			{Translation: mapipwriter.Translation{From: "127.0.0.1", To: "148.142.120.1"}},
+			{Translation: mapipwriter.Translation{From: "127.0.0.2", To: "192.0.0.2"}, Original: "10.0.0.2"},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "# generation 3\n127.0.0.1 -> 148.142.120.1\n127.0.0.2 -> 192.0.0.2 (10.0.0.2)\n", string(b))
+
+	_, err = mapipwriter.NewTemplateRenderer("{{ .Entries ")
+	require.Error(t, err)
+}
+
+func Test_ProtobufRenderer(t *testing.T) {
+	var snapshot = &mapipwriter.Snapshot{
+		Generation: 42,
+		Entries: []mapipwriter.Entry{
+			{Translation: mapipwriter.Translation{From: "127.0.0.1", To: "148.142.120.1"}},
+			{Translation: mapipwriter.Translation{From: "127.0.0.2", To: "192.0.0.2"}, Original: "10.0.0.2"},
+		},
+	}
+
+	b, err := mapipwriter.ProtobufRenderer(snapshot)
+	require.NoError(t, err)
+
+	parsed, err := mapipwriter.UnmarshalProtobuf(b)
+	require.NoError(t, err)
+	require.Equal(t, snapshot, parsed)
+
+	_, err = mapipwriter.UnmarshalProtobuf(b[:len(b)-3])
+	require.Error(t, err)
+}
+
+func Test_EnvFileRenderer(t *testing.T) {
+	var snapshot = &mapipwriter.Snapshot{
+		Entries: []mapipwriter.Entry{
+			{Translation: mapipwriter.Translation{From: "127.0.0.1", To: "148.142.120.1"}},
+			{Translation: mapipwriter.Translation{From: "fd00::1", To: "2001:db8::1"}},
+		},
+	}
+
+	b, err := mapipwriter.NewEnvFileRenderer("IP_")(snapshot)
+	require.NoError(t, err)
+	require.Equal(t, "IP_127_0_0_1=148.142.120.1\nIP_FD00__1=2001:db8::1\n", string(b))
+
+	b, err = mapipwriter.NewEnvFileRenderer("")(snapshot)
+	require.NoError(t, err)
+	require.Equal(t, "_127_0_0_1=148.142.120.1\nFD00__1=2001:db8::1\n", string(b))
+}
+
+func Test_NetfilterRenderers(t *testing.T) {
+	var snapshot = &mapipwriter.Snapshot{
+		Generation: 7,
+		Entries: []mapipwriter.Entry{
+			{Translation: mapipwriter.Translation{From: "127.0.0.1", To: "148.142.120.1"}},
+			{Translation: mapipwriter.Translation{From: "127.0.0.2", To: "148.142.120.2"}},
+			{Translation: mapipwriter.Translation{From: "10.0.0.0/8", To: "192.168.0.0/8"}},
+			{Translation: mapipwriter.Translation{From: "node-1", To: "148.142.120.1"}},
+			{Translation: mapipwriter.Translation{From: "fd00::1", To: "2001:db8::1"}},
+		},
+	}
+
+	b, err := mapipwriter.NewNftRenderer("")(snapshot)
+	require.NoError(t, err)
+	require.Equal(t, `# generation: 7
+add table inet nsm_map_ip
+add map inet nsm_map_ip ip4_map { type ipv4_addr : ipv4_addr; }
+flush map inet nsm_map_ip ip4_map
+add element inet nsm_map_ip ip4_map { 127.0.0.1 : 148.142.120.1, 127.0.0.2 : 148.142.120.2 }
+add map inet nsm_map_ip ip6_map { type ipv6_addr : ipv6_addr; }
+flush map inet nsm_map_ip ip6_map
+add element inet nsm_map_ip ip6_map { fd00::1 : 2001:db8::1 }
+`, string(b))
+
+	b, err = mapipwriter.NewIpsetRenderer("map")(snapshot)
+	require.NoError(t, err)
+	require.Equal(t, `# generation: 7
+create map4 hash:net,net family inet
+create map4-tmp hash:net,net family inet
+flush map4-tmp
+add map4-tmp 127.0.0.1,148.142.120.1
+add map4-tmp 127.0.0.2,148.142.120.2
+swap map4-tmp map4
+destroy map4-tmp
+create map6 hash:net,net family inet6
+create map6-tmp hash:net,net family inet6
+flush map6-tmp
+add map6-tmp fd00::1,2001:db8::1
+swap map6-tmp map6
+destroy map6-tmp
+`, string(b))
+}
+
+func Test_FileTargetGzip(t *testing.T) {
+	outputFile := filepath.Join(t.TempDir(), "output.yaml"+mapipwriter.GzipSuffix)
+
+	var target = mapipwriter.FileTarget{
+		Path:   outputFile,
+		Gzip:   true,
+		Header: true,
+	}
+
+	_, err := target.Write(context.Background(), &mapipwriter.Snapshot{
+		Entries: []mapipwriter.Entry{
+			{Translation: mapipwriter.Translation{From: "127.0.0.1", To: "148.142.120.1"}},
+		},
+	})
+	require.NoError(t, err)
+
+	// #nosec
+	f, err := os.Open(outputFile)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	r, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	b, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	var m map[string]string
+	require.NoError(t, yaml.Unmarshal(b, &m))
+	require.Equal(t, map[string]string{"127.0.0.1": "148.142.120.1"}, m)
+}
+
+func Test_FileTargetKeepVersions(t *testing.T) {
+	outputFile := filepath.Join(t.TempDir(), "output.yaml")
+
+	var target = mapipwriter.FileTarget{
+		Path:         outputFile,
+		KeepVersions: 2,
+	}
+
+	for i := 1; i <= 4; i++ {
+		_, err := target.Write(context.Background(), &mapipwriter.Snapshot{
+			Entries: []mapipwriter.Entry{
+				{Translation: mapipwriter.Translation{From: "127.0.0.1", To: fmt.Sprintf("148.142.120.%v", i)}},
+			},
+		})
+		require.NoError(t, err)
+	}
+
+	for suffix, expected := range map[string]string{
+		"":   "127.0.0.1: 148.142.120.4",
+		".1": "127.0.0.1: 148.142.120.3",
+		".2": "127.0.0.1: 148.142.120.2",
+	} {
+		// #nosec
+		b, err := os.ReadFile(outputFile + suffix)
+		require.NoError(t, err)
+		require.Equal(t, expected, strings.TrimSpace(string(b)))
+	}
+	require.NoFileExists(t, outputFile+".3")
+}
+
+func Test_FileTargetWritePatch(t *testing.T) {
+	outputFile := filepath.Join(t.TempDir(), "output.yaml")
+
+	var target = mapipwriter.FileTarget{
+		Path:       outputFile,
+		WritePatch: true,
+	}
+
+	var readPatch = func() []mapipwriter.PatchOperation {
+		// #nosec
+		b, err := os.ReadFile(outputFile + mapipwriter.PatchSuffix)
+		require.NoError(t, err)
+		var ops []mapipwriter.PatchOperation
+		require.NoError(t, json.Unmarshal(b, &ops))
+		return ops
+	}
+
+	_, err := target.Write(context.Background(), &mapipwriter.Snapshot{
+		Entries: []mapipwriter.Entry{
+			{Translation: mapipwriter.Translation{From: "127.0.0.1", To: "148.142.120.1"}},
+			{Translation: mapipwriter.Translation{From: "127.0.0.2", To: "148.142.120.2"}},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []mapipwriter.PatchOperation{
+		{Op: "add", Path: "/127.0.0.1", Value: "148.142.120.1"},
+		{Op: "add", Path: "/127.0.0.2", Value: "148.142.120.2"},
+	}, readPatch())
+
+	_, err = target.Write(context.Background(), &mapipwriter.Snapshot{
+		Entries: []mapipwriter.Entry{
+			{Translation: mapipwriter.Translation{From: "10.0.0.0/8", To: "148.142.120.3"}},
+			{Translation: mapipwriter.Translation{From: "127.0.0.2", To: "148.142.120.5"}},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, []mapipwriter.PatchOperation{
+		{Op: "add", Path: "/10.0.0.0~18", Value: "148.142.120.3"},
+		{Op: "remove", Path: "/127.0.0.1"},
+		{Op: "replace", Path: "/127.0.0.2", Value: "148.142.120.5"},
+	}, readPatch())
+}
+
+func Test_FileTargetHMAC(t *testing.T) {
+	outputFile := filepath.Join(t.TempDir(), "output.yaml")
+	var key = []byte("secret")
+
+	var target = mapipwriter.FileTarget{
+		Path:    outputFile,
+		HMACKey: key,
+	}
+
+	_, err := target.Write(context.Background(), &mapipwriter.Snapshot{
+		Entries: []mapipwriter.Entry{
+			{Translation: mapipwriter.Translation{From: "127.0.0.1", To: "148.142.120.1"}},
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, mapipwriter.VerifyHMAC(outputFile, key))
+	require.Error(t, mapipwriter.VerifyHMAC(outputFile, []byte("another secret")))
+
+	require.NoError(t, os.WriteFile(outputFile, []byte("127.0.0.1: 6.6.6.6\n"), 0o600))
+	require.Error(t, mapipwriter.VerifyHMAC(outputFile, key))
+}
+
+func Test_HostsBlockTarget(t *testing.T) {
+	var path = filepath.Join(t.TempDir(), "hosts")
+	const unmanaged = "127.0.0.1\tlocalhost\n::1\tlocalhost"
+	require.NoError(t, os.WriteFile(path, []byte(unmanaged), os.ModePerm))
+
+	hostname, err := mapipwriter.ParseHostnameTemplate("{{.Node}} {{.Node}}.nodes.example.com")
+	require.NoError(t, err)
+	var target = &mapipwriter.HostsBlockTarget{Path: path, Hostname: hostname}
+
+	var snapshot = &mapipwriter.Snapshot{Generation: 1, Entries: []mapipwriter.Entry{
+		{Translation: mapipwriter.Translation{From: "10.0.0.1", To: "148.142.120.1"}, Node: "node-1"},
+		{Translation: mapipwriter.Translation{From: "10.0.0.5", To: "148.142.120.5"}},
+	}}
+	written, err := target.Write(context.Background(), snapshot)
+	require.NoError(t, err)
+	require.True(t, written)
+
+	var block = "# BEGIN nsm-map-ip\n148.142.120.1\tnode-1 node-1.nodes.example.com\n# END nsm-map-ip\n"
+	content, err := os.ReadFile(filepath.Clean(path))
+	require.NoError(t, err)
+	require.Equal(t, unmanaged+"\n"+block, string(content))
+
+	written, err = target.Write(context.Background(), snapshot)
+	require.NoError(t, err)
+	require.False(t, written)
+
+	require.NoError(t, os.WriteFile(path, []byte(unmanaged+"\n"+block+"10.1.1.1\tmanual\n"), os.ModePerm))
+	snapshot.Entries[0].To = "148.142.120.2"
+	written, err = target.Write(context.Background(), snapshot)
+	require.NoError(t, err)
+	require.True(t, written)
+	content, err = os.ReadFile(filepath.Clean(path))
+	require.NoError(t, err)
+	require.Equal(t, unmanaged+"\n"+strings.ReplaceAll(block, "148.142.120.1", "148.142.120.2")+"10.1.1.1\tmanual\n", string(content))
+
+	target.Restore()
+	content, err = os.ReadFile(filepath.Clean(path))
+	require.NoError(t, err)
+	require.Equal(t, unmanaged+"\n10.1.1.1\tmanual\n", string(content))
+}
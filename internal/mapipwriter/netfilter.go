@@ -0,0 +1,108 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapipwriter
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Defaults of netfilter formats
+const (
+	DefaultNftTable = "nsm_map_ip"
+	DefaultIpsetSet = "nsm-map-ip"
+)
+
+// splitFamilies returns single address translations of the snapshot split by the family of From addresses. Other
+// translations can't be loaded into netfilter and are skipped.
+func splitFamilies(snapshot *Snapshot) (v4, v6 []Translation) {
+	for i := range snapshot.Entries {
+		var from, to = net.ParseIP(snapshot.Entries[i].From), net.ParseIP(snapshot.Entries[i].To)
+		if from == nil || to == nil || (from.To4() == nil) != (to.To4() == nil) {
+			continue
+		}
+		var t = Translation{From: from.String(), To: to.String()}
+		if from.To4() != nil {
+			v4 = append(v4, t)
+		} else {
+			v6 = append(v6, t)
+		}
+	}
+	return v4, v6
+}
+
+// NewNftRenderer returns a renderer of an `nft -f` script replacing elements of ip4_map and ip6_map maps of the inet
+// table with single address translations. The maps are flushed and filled in one transaction, so reloads are
+// idempotent and rules of the table referencing the maps are kept.
+func NewNftRenderer(table string) Renderer {
+	if table == "" {
+		table = DefaultNftTable
+	}
+	return func(snapshot *Snapshot) ([]byte, error) {
+		var buf bytes.Buffer
+		var v4, v6 = splitFamilies(snapshot)
+		_, _ = fmt.Fprintf(&buf, "# generation: %d\n", snapshot.Generation)
+		_, _ = fmt.Fprintf(&buf, "add table inet %s\n", table)
+		for _, m := range []struct {
+			name, addrType string
+			elements       []Translation
+		}{{"ip4_map", "ipv4_addr", v4}, {"ip6_map", "ipv6_addr", v6}} {
+			_, _ = fmt.Fprintf(&buf, "add map inet %s %s { type %s : %s; }\n", table, m.name, m.addrType, m.addrType)
+			_, _ = fmt.Fprintf(&buf, "flush map inet %s %s\n", table, m.name)
+			if len(m.elements) == 0 {
+				continue
+			}
+			var elements = make([]string, 0, len(m.elements))
+			for _, t := range m.elements {
+				elements = append(elements, t.From+" : "+t.To)
+			}
+			_, _ = fmt.Fprintf(&buf, "add element inet %s %s { %s }\n", table, m.name, strings.Join(elements, ", "))
+		}
+		return buf.Bytes(), nil
+	}
+}
+
+// NewIpsetRenderer returns a renderer of `ipset restore -exist` input filling <set>4 and <set>6 hash:net,net sets with
+// From,To pairs of single address translations. Sets are filled as temporary ones and swapped, so reloads are
+// idempotent and atomic.
+func NewIpsetRenderer(set string) Renderer {
+	if set == "" {
+		set = DefaultIpsetSet
+	}
+	return func(snapshot *Snapshot) ([]byte, error) {
+		var buf bytes.Buffer
+		var v4, v6 = splitFamilies(snapshot)
+		_, _ = fmt.Fprintf(&buf, "# generation: %d\n", snapshot.Generation)
+		for _, s := range []struct {
+			name, family string
+			elements     []Translation
+		}{{set + "4", "inet", v4}, {set + "6", "inet6", v6}} {
+			var tmp = s.name + "-tmp"
+			_, _ = fmt.Fprintf(&buf, "create %s hash:net,net family %s\n", s.name, s.family)
+			_, _ = fmt.Fprintf(&buf, "create %s hash:net,net family %s\n", tmp, s.family)
+			_, _ = fmt.Fprintf(&buf, "flush %s\n", tmp)
+			for _, t := range s.elements {
+				_, _ = fmt.Fprintf(&buf, "add %s %s,%s\n", tmp, t.From, t.To)
+			}
+			_, _ = fmt.Fprintf(&buf, "swap %s %s\n", tmp, s.name)
+			_, _ = fmt.Fprintf(&buf, "destroy %s\n", tmp)
+		}
+		return buf.Bytes(), nil
+	}
+}
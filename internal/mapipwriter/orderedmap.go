@@ -0,0 +1,70 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapipwriter
+
+import (
+	"container/list"
+)
+
+type orderedEntry struct {
+	translation Translation
+	value       entry
+}
+
+// orderedMap is a map of translations that remembers the order of insertion. Updating of an existing translation
+// keeps its position.
+type orderedMap struct {
+	elements map[Translation]*list.Element
+	order    list.List
+}
+
+func (o *orderedMap) load(key Translation) (entry, bool) {
+	if e, ok := o.elements[key]; ok {
+		return e.Value.(*orderedEntry).value, true
+	}
+	return entry{}, false
+}
+
+func (o *orderedMap) store(key Translation, value entry) {
+	if e, ok := o.elements[key]; ok {
+		e.Value.(*orderedEntry).value = value
+		return
+	}
+	if o.elements == nil {
+		o.elements = make(map[Translation]*list.Element)
+	}
+	o.elements[key] = o.order.PushBack(&orderedEntry{translation: key, value: value})
+}
+
+func (o *orderedMap) delete(key Translation) {
+	if e, ok := o.elements[key]; ok {
+		o.order.Remove(e)
+		delete(o.elements, key)
+	}
+}
+
+func (o *orderedMap) len() int {
+	return len(o.elements)
+}
+
+// rangeInOrder calls f for each translation in the order of insertion
+func (o *orderedMap) rangeInOrder(f func(key Translation, value entry)) {
+	for e := o.order.Front(); e != nil; e = e.Next() {
+		var item = e.Value.(*orderedEntry)
+		f(item.translation, item.value)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapipwriter
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// PatchSuffix is the suffix of the companion file with the JSON Patch of the last change of the map
+const PatchSuffix = ".patch.json"
+
+// PatchOperation is an operation of JSON Patch (RFC 6902) applied to the JSON object of From to To addresses
+type PatchOperation struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	Value string `json:"value,omitempty"`
+}
+
+// flatten returns the map of From to To addresses. The last entry wins if a From address has several To addresses
+// like in YAMLRenderer.
+func flatten(snapshot *Snapshot) map[string]string {
+	var result = make(map[string]string, len(snapshot.Entries))
+	for i := range snapshot.Entries {
+		result[snapshot.Entries[i].From] = snapshot.Entries[i].To
+	}
+	return result
+}
+
+// Diff returns JSON Patch operations transforming prev map into next one ordered by From
+func Diff(prev, next map[string]string) []PatchOperation {
+	var keys = make([]string, 0, len(prev)+len(next))
+	for k := range prev {
+		keys = append(keys, k)
+	}
+	for k := range next {
+		if _, ok := prev[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var result = []PatchOperation{}
+	for _, k := range keys {
+		var path = "/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(k)
+		prevValue, inPrev := prev[k]
+		nextValue, inNext := next[k]
+		switch {
+		case !inNext:
+			result = append(result, PatchOperation{Op: "remove", Path: path})
+		case !inPrev:
+			result = append(result, PatchOperation{Op: "add", Path: path, Value: nextValue})
+		case prevValue != nextValue:
+			result = append(result, PatchOperation{Op: "replace", Path: path, Value: nextValue})
+		}
+	}
+	return result
+}
+
+func marshalPatch(ops []PatchOperation) ([]byte, error) {
+	b, err := json.Marshal(ops)
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
+}
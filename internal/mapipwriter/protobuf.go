@@ -0,0 +1,138 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapipwriter
+
+import (
+	"github.com/pkg/errors"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Field numbers of the protobuf representation of the map:
+//
+//	message Translation {
+//	  string from = 1;
+//	  string to = 2;
+//	  string original = 3;
+//	}
+//
+//	message Map {
+//	  repeated Translation translations = 1;
+//	  uint64 generation = 2;
+//	}
+const (
+	protoTranslationFrom     protowire.Number = 1
+	protoTranslationTo       protowire.Number = 2
+	protoTranslationOriginal protowire.Number = 3
+
+	protoMapTranslations protowire.Number = 1
+	protoMapGeneration   protowire.Number = 2
+)
+
+// ProtobufRenderer renders the snapshot as the protobuf Map message
+func ProtobufRenderer(snapshot *Snapshot) ([]byte, error) {
+	var b []byte
+	for i := range snapshot.Entries {
+		var e = &snapshot.Entries[i]
+
+		var translation []byte
+		translation = appendString(translation, protoTranslationFrom, e.From)
+		translation = appendString(translation, protoTranslationTo, e.To)
+		translation = appendString(translation, protoTranslationOriginal, e.Original)
+
+		b = protowire.AppendTag(b, protoMapTranslations, protowire.BytesType)
+		b = protowire.AppendBytes(b, translation)
+	}
+	if snapshot.Generation != 0 {
+		b = protowire.AppendTag(b, protoMapGeneration, protowire.VarintType)
+		b = protowire.AppendVarint(b, snapshot.Generation)
+	}
+	return b, nil
+}
+
+func appendString(b []byte, num protowire.Number, s string) []byte {
+	if s == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, s)
+}
+
+// UnmarshalProtobuf parses the protobuf Map message rendered by ProtobufRenderer
+func UnmarshalProtobuf(b []byte) (*Snapshot, error) {
+	var result = new(Snapshot)
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return nil, errors.Wrap(protowire.ParseError(n), "can't parse map")
+		}
+		b = b[n:]
+
+		switch {
+		case num == protoMapTranslations && typ == protowire.BytesType:
+			v, m := protowire.ConsumeBytes(b)
+			if m < 0 {
+				return nil, errors.Wrap(protowire.ParseError(m), "can't parse translation")
+			}
+			var e, err = unmarshalProtobufEntry(v)
+			if err != nil {
+				return nil, err
+			}
+			result.Entries = append(result.Entries, e)
+			n = m
+		case num == protoMapGeneration && typ == protowire.VarintType:
+			result.Generation, n = protowire.ConsumeVarint(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return nil, errors.Wrap(protowire.ParseError(n), "can't parse map")
+		}
+		b = b[n:]
+	}
+	return result, nil
+}
+
+func unmarshalProtobufEntry(b []byte) (Entry, error) {
+	var result Entry
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return result, errors.Wrap(protowire.ParseError(n), "can't parse translation")
+		}
+		b = b[n:]
+
+		var field *string
+		switch num {
+		case protoTranslationFrom:
+			field = &result.From
+		case protoTranslationTo:
+			field = &result.To
+		case protoTranslationOriginal:
+			field = &result.Original
+		}
+		if field != nil && typ == protowire.BytesType {
+			*field, n = protowire.ConsumeString(b)
+		} else {
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return result, errors.Wrap(protowire.ParseError(n), "can't parse translation")
+		}
+		b = b[n:]
+	}
+	return result, nil
+}
@@ -0,0 +1,77 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapipwriter
+
+import (
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// writeLimiter limits the rate of writes with a token bucket. Writes over the limit are delayed until a token is
+// available and coalesced into a single delayed write. A nil limiter allows all writes.
+type writeLimiter struct {
+	limiter *rate.Limiter
+	timer   *time.Timer
+	pending bool
+}
+
+func newWriteLimiter(limit float64, burst int) *writeLimiter {
+	if limit <= 0 {
+		return nil
+	}
+	return &writeLimiter{limiter: rate.NewLimiter(rate.Limit(limit), max(burst, 1))}
+}
+
+// allow returns true if the write is allowed now. Otherwise, the write is fired through C when it's allowed.
+func (l *writeLimiter) allow() bool {
+	if l == nil {
+		return true
+	}
+	if l.pending {
+		return false
+	}
+	var delay = l.limiter.Reserve().Delay()
+	if delay == 0 {
+		return true
+	}
+	l.pending = true
+	if l.timer == nil {
+		l.timer = time.NewTimer(delay)
+		return false
+	}
+	l.timer.Reset(delay)
+	return false
+}
+
+// C returns the channel of the delayed write. It's nil if there is no delayed write.
+func (l *writeLimiter) C() <-chan time.Time {
+	if l == nil || !l.pending {
+		return nil
+	}
+	return l.timer.C
+}
+
+func (l *writeLimiter) fired() {
+	l.pending = false
+}
+
+func (l *writeLimiter) stop() {
+	if l != nil && l.timer != nil {
+		l.timer.Stop()
+	}
+}
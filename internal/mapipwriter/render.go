@@ -0,0 +1,81 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapipwriter
+
+import (
+	"gopkg.in/yaml.v2"
+)
+
+// Renderer renders the snapshot into the content of a file
+type Renderer func(snapshot *Snapshot) ([]byte, error)
+
+type extendedEntry struct {
+	To        string `yaml:"to"`
+	Original  string `yaml:"original,omitempty"`
+	Namespace string `yaml:"namespace,omitempty"`
+	Cluster   string `yaml:"cluster,omitempty"`
+}
+
+// YAMLRenderer returns a renderer of the map of From to To addresses as YAML. Keys keep the order of the snapshot
+// entries. If extended is set then each entry carries the To address, the original address, the namespace of the
+// source configmap and the remote cluster.
+func YAMLRenderer(extended bool) Renderer {
+	return func(snapshot *Snapshot) ([]byte, error) {
+		var outmap yaml.MapSlice
+		var index = make(map[string]int)
+
+		for _, e := range snapshot.Entries {
+			var value interface{} = e.To
+			if extended {
+				value = extendedEntry{To: e.To, Original: e.Original, Namespace: e.Namespace, Cluster: e.Cluster}
+			}
+			if i, ok := index[e.From]; ok {
+				outmap[i].Value = value
+				continue
+			}
+			index[e.From] = len(outmap)
+			outmap = append(outmap, yaml.MapItem{Key: e.From, Value: value})
+		}
+
+		return yaml.Marshal(outmap)
+	}
+}
+
+// YAMLListRenderer returns a renderer of the map of From addresses to lists of all their To addresses as YAML, so
+// a From address translated into several To addresses keeps all of them. If extended is set then each item of the
+// list carries the To address and the original address.
+func YAMLListRenderer(extended bool) Renderer {
+	return func(snapshot *Snapshot) ([]byte, error) {
+		var outmap yaml.MapSlice
+		var index = make(map[string]int)
+
+		for _, e := range snapshot.Entries {
+			var value interface{} = e.To
+			if extended {
+				value = extendedEntry{To: e.To, Original: e.Original, Namespace: e.Namespace, Cluster: e.Cluster}
+			}
+			if i, ok := index[e.From]; ok {
+				outmap[i].Value = append(outmap[i].Value.([]interface{}), value)
+				continue
+			}
+			index[e.From] = len(outmap)
+			outmap = append(outmap, yaml.MapItem{Key: e.From, Value: []interface{}{value}})
+		}
+
+		return yaml.Marshal(outmap)
+	}
+}
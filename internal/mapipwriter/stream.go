@@ -0,0 +1,52 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapipwriter
+
+import (
+	"context"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// StreamTarget writes the map into Writer, e.g. stdout
+type StreamTarget struct {
+	Writer io.Writer
+	// Render renders the map. The map is marshaled as YAML if it's nil.
+	Render Renderer
+}
+
+// Name returns the name of the target
+func (s *StreamTarget) Name() string {
+	return "stream"
+}
+
+// Write writes the snapshot into the writer
+func (s *StreamTarget) Write(_ context.Context, snapshot *Snapshot) (bool, error) {
+	var render = s.Render
+	if render == nil {
+		render = YAMLRenderer(false)
+	}
+	b, err := render(snapshot)
+	if err != nil {
+		return false, errors.Wrap(err, "an error during marshaling ips map")
+	}
+	if _, err = s.Writer.Write(b); err != nil {
+		return false, errors.Wrap(err, "can't write ips map")
+	}
+	return true, nil
+}
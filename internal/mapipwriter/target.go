@@ -0,0 +1,60 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapipwriter
+
+import (
+	"context"
+)
+
+// Entry is a translation of the map with its metadata
+type Entry struct {
+	Translation
+	// Original is the To address before MapIPWriter.TransformTo. It's empty if the address was not transformed.
+	Original string
+	// Node is the name of the node the translation is derived from. It's empty for other sources.
+	Node string
+	// Namespace is the namespace of the source configmap if configmaps of all namespaces are watched
+	Namespace string
+	// Cluster is the identifier of the remote cluster the translation is derived from. It's empty for the local one.
+	Cluster string
+}
+
+// Snapshot is a consistent view of the map passed to targets. Entries are ordered by MapIPWriter.Order.
+type Snapshot struct {
+	Entries []Entry
+	// Generation is a monotonically increasing number of the map. It's changed only when the entries are changed.
+	Generation uint64
+}
+
+// Target is a destination of the map
+type Target interface {
+	// Name identifies the target in logs and metrics
+	Name() string
+	// Write publishes the snapshot. Returns false if the target is already up to date and nothing was written.
+	Write(ctx context.Context, snapshot *Snapshot) (bool, error)
+}
+
+// Closer is implemented by targets releasing resources when MapIPWriter stops
+type Closer interface {
+	Close()
+}
+
+// Restorer is implemented by targets restoring the state of the destination when MapIPWriter stops, e.g. removing the
+// map. It's not called when MapIPWriter is restarted, so consumers keep the map until it's written again.
+type Restorer interface {
+	Restore()
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mapipwriter
+
+import (
+	"bytes"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+// NewTemplateRenderer returns a renderer executing the Go template against the snapshot. The template has access to
+// .Entries with .From, .To and .Original fields and to .Generation of the map.
+func NewTemplateRenderer(text string) (Renderer, error) {
+	tmpl, err := template.New("output").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't parse output template")
+	}
+	return func(snapshot *Snapshot) ([]byte, error) {
+		var buf bytes.Buffer
+		if execErr := tmpl.Execute(&buf, snapshot); execErr != nil {
+			return nil, errors.Wrap(execErr, "can't execute output template")
+		}
+		return buf.Bytes(), nil
+	}, nil
+}
+
+// DefaultHostnameTemplate is the default template of hostnames of nodes
+const DefaultHostnameTemplate = "{{.Node}}"
+
+// ParseHostnameTemplate parses a template of hostnames executed with Entry, e.g. "{{.Node}}.nodes.example.com".
+// Empty text means DefaultHostnameTemplate.
+func ParseHostnameTemplate(text string) (*template.Template, error) {
+	if text == "" {
+		text = DefaultHostnameTemplate
+	}
+	tmpl, err := template.New("hostname").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't parse hostname template %q", text)
+	}
+	return tmpl, nil
+}
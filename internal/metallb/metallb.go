@@ -0,0 +1,48 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metallb provides the ServiceL2Status resource of MetalLB reporting nodes announcing Services
+package metallb
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ServiceL2Status resource of the metallb.io group
+const (
+	Group                   = "metallb.io"
+	Version                 = "v1beta1"
+	ServiceL2StatusResource = "servicel2statuses"
+)
+
+// GroupVersionResource of ServiceL2Statuses for the dynamic client
+var GroupVersionResource = schema.GroupVersionResource{Group: Group, Version: Version, Resource: ServiceL2StatusResource}
+
+// Status is the status of the ServiceL2Status
+type Status struct {
+	// Node is the name of the node announcing the Service
+	Node             string `json:"node"`
+	ServiceName      string `json:"serviceName"`
+	ServiceNamespace string `json:"serviceNamespace"`
+}
+
+// ServiceL2Status reports the node announcing addresses of a Service in the layer 2 mode
+type ServiceL2Status struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Status            Status `json:"status"`
+}
@@ -0,0 +1,173 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metrics provides opentelemetry instruments reported by cmd-map-ip-k8s
+package metrics
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/metric/noop"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+)
+
+const (
+	meterName = "map-ip-k8s"
+
+	// EntriesName is the name of the metric with the count of entries in the map
+	EntriesName = "map_ip_entries"
+	// NodesName is the name of the metric with the count of known nodes
+	NodesName = "map_ip_nodes"
+	// LastWriteName is the name of the metric with the unix timestamp of the last write of the output file
+	LastWriteName = "map_ip_last_write_timestamp_seconds"
+	// WritesName is the name of the metric with the count of writes per target
+	WritesName = "map_ip_writes"
+	// WriteDurationName is the name of the metric with the duration of writes per target
+	WriteDurationName = "map_ip_write_duration_seconds"
+	// InvalidConfigMapValuesName is the name of the metric with the count of configmap values that can't be used as a map
+	InvalidConfigMapValuesName = "map_ip_configmap_invalid_values"
+	// WatchRestartsName is the name of the metric with the count of failed lists and watches of sources which are
+	// retried
+	WatchRestartsName = "map_ip_watch_restarts"
+	// EventQueueDepthName is the name of the metric with the count of events waiting for the writer
+	EventQueueDepthName = "map_ip_event_queue_depth"
+	// EventsCoalescedName is the name of the metric with the count of events replaced in the queue by later events of
+	// the same translation
+	EventsCoalescedName = "map_ip_events_coalesced"
+
+	// ZoneKey is the attribute key carrying node topology zone
+	ZoneKey = attribute.Key("zone")
+	// RegionKey is the attribute key carrying node topology region
+	RegionKey = attribute.Key("region")
+	// TargetKey is the attribute key carrying the name of the output target
+	TargetKey = attribute.Key("target")
+	// ResourceKey is the attribute key carrying the resource of a source
+	ResourceKey = attribute.Key("resource")
+	// ResultKey is the attribute key carrying the result of an operation
+	ResultKey = attribute.Key("result")
+
+	// ResultSuccess is the value of ResultKey for successful operations
+	ResultSuccess = "success"
+	// ResultFailure is the value of ResultKey for failed operations
+	ResultFailure = "failure"
+)
+
+// Meter returns the meter from the current global meter provider
+func Meter() metric.Meter {
+	return otel.Meter(meterName)
+}
+
+// Int64UpDownCounter creates an up-down counter, logging and falling back to a noop instrument on error
+func Int64UpDownCounter(ctx context.Context, name string, options ...metric.Int64UpDownCounterOption) metric.Int64UpDownCounter {
+	counter, err := Meter().Int64UpDownCounter(name, options...)
+	if err != nil {
+		log.FromContext(ctx).Errorf("can't create metric %v: %v", name, err.Error())
+		counter = noop.Int64UpDownCounter{}
+	}
+	return counter
+}
+
+// Int64Counter creates a counter, logging and falling back to a noop instrument on error
+func Int64Counter(ctx context.Context, name string, options ...metric.Int64CounterOption) metric.Int64Counter {
+	counter, err := Meter().Int64Counter(name, options...)
+	if err != nil {
+		log.FromContext(ctx).Errorf("can't create metric %v: %v", name, err.Error())
+		counter = noop.Int64Counter{}
+	}
+	return counter
+}
+
+// Float64Histogram creates a histogram, logging and falling back to a noop instrument on error
+func Float64Histogram(ctx context.Context, name string, options ...metric.Float64HistogramOption) metric.Float64Histogram {
+	histogram, err := Meter().Float64Histogram(name, options...)
+	if err != nil {
+		log.FromContext(ctx).Errorf("can't create metric %v: %v", name, err.Error())
+		histogram = noop.Float64Histogram{}
+	}
+	return histogram
+}
+
+// ObserveFloat64 registers a gauge reporting the result of valueFn on each collection. Returns a function that
+// unregisters the gauge.
+func ObserveFloat64(ctx context.Context, name, description string, valueFn func() float64) func() {
+	var meter = Meter()
+	gauge, err := meter.Float64ObservableGauge(name, metric.WithDescription(description))
+	if err != nil {
+		log.FromContext(ctx).Errorf("can't create metric %v: %v", name, err.Error())
+		return func() {}
+	}
+	registration, err := meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		o.ObserveFloat64(gauge, valueFn())
+		return nil
+	}, gauge)
+	if err != nil {
+		log.FromContext(ctx).Errorf("can't register callback for metric %v: %v", name, err.Error())
+		return func() {}
+	}
+	return func() {
+		_ = registration.Unregister()
+	}
+}
+
+// TopologyAttributes returns metric attributes for the passed zone and region. Empty values are omitted.
+func TopologyAttributes(zone, region string) []attribute.KeyValue {
+	var result []attribute.KeyValue
+	if zone != "" {
+		result = append(result, ZoneKey.String(zone))
+	}
+	if region != "" {
+		result = append(result, RegionKey.String(region))
+	}
+	return result
+}
+
+// NodeCounter reports the count of known nodes. It's safe for concurrent use.
+type NodeCounter struct {
+	once    sync.Once
+	mu      sync.Mutex
+	counter metric.Int64UpDownCounter
+	nodes   map[string]attribute.Set
+}
+
+// Update adds, updates or removes the node with the passed attributes
+func (n *NodeCounter) Update(ctx context.Context, name string, deleted bool, attrs ...attribute.KeyValue) {
+	n.once.Do(func() {
+		n.counter = Int64UpDownCounter(ctx, NodesName, metric.WithDescription("count of known nodes"))
+		n.nodes = make(map[string]attribute.Set)
+	})
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	var next = attribute.NewSet(attrs...)
+	if prev, ok := n.nodes[name]; ok {
+		if !deleted && prev.Equals(&next) {
+			return
+		}
+		n.counter.Add(ctx, -1, metric.WithAttributeSet(prev))
+		delete(n.nodes, name)
+	}
+	if deleted {
+		return
+	}
+	n.nodes[name] = next
+	n.counter.Add(ctx, 1, metric.WithAttributeSet(next))
+}
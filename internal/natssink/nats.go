@@ -0,0 +1,256 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package natssink provides a target publishing changes of the map to a NATS subject
+package natssink
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+)
+
+const (
+	ioTimeout   = 5 * time.Second
+	defaultPort = "4222"
+	clientName  = "cmd-map-ip-k8s"
+)
+
+// Event is the message published on the subject for each added, modified or deleted translation. A From address with
+// several To addresses has an event per To address. A deleted event without To withdraws all translations of the
+// From address.
+type Event struct {
+	Type       watch.EventType `json:"type"`
+	From       string          `json:"from"`
+	To         string          `json:"to,omitempty"`
+	Generation uint64          `json:"generation"`
+}
+
+// Target publishes an Event on Subject for each change of the map. All translations are published as added on the
+// first write.
+type Target struct {
+	// URL is nats://[user:password@]host[:port] of the NATS server
+	URL     string
+	Token   string
+	Subject string
+
+	conn      net.Conn
+	reader    *bufio.Reader
+	writer    *bufio.Writer
+	published map[string][]string
+}
+
+// Name returns the name of the target
+func (t *Target) Name() string {
+	return "nats"
+}
+
+// Write publishes events for changed translations since the previous write
+func (t *Target) Write(ctx context.Context, snapshot *mapipwriter.Snapshot) (bool, error) {
+	var froms []string
+	var next = make(map[string][]string, len(snapshot.Entries))
+	for i := range snapshot.Entries {
+		var e = &snapshot.Entries[i]
+		if _, ok := next[e.From]; !ok {
+			froms = append(froms, e.From)
+		}
+		next[e.From] = append(next[e.From], e.To)
+	}
+
+	var events []Event
+	for _, from := range froms {
+		sort.Strings(next[from])
+		events = append(events, changes(from, t.published[from], next[from], snapshot.Generation)...)
+	}
+	var removed []string
+	for from := range t.published {
+		if _, ok := next[from]; !ok {
+			removed = append(removed, from)
+		}
+	}
+	sort.Strings(removed)
+	for _, from := range removed {
+		events = append(events, Event{Type: watch.Deleted, From: from, Generation: snapshot.Generation})
+	}
+	if len(events) == 0 {
+		return false, nil
+	}
+
+	if err := t.publish(ctx, events); err != nil {
+		t.Close()
+		return false, err
+	}
+	t.published = next
+	return true, nil
+}
+
+// changes returns events changing prev To addresses of the From address to next ones. A single replaced To address
+// is published as modified.
+func changes(from string, prev, next []string, generation uint64) []Event {
+	var added, deleted = subtract(next, prev), subtract(prev, next)
+	if len(added) == 1 && len(deleted) == 1 {
+		return []Event{{Type: watch.Modified, From: from, To: added[0], Generation: generation}}
+	}
+	var events []Event
+	for _, to := range deleted {
+		events = append(events, Event{Type: watch.Deleted, From: from, To: to, Generation: generation})
+	}
+	for _, to := range added {
+		events = append(events, Event{Type: watch.Added, From: from, To: to, Generation: generation})
+	}
+	return events
+}
+
+// subtract returns addresses of a missing in b
+func subtract(a, b []string) []string {
+	var result []string
+	for _, address := range a {
+		if !contains(b, address) && !contains(result, address) {
+			result = append(result, address)
+		}
+	}
+	return result
+}
+
+func contains(addresses []string, address string) bool {
+	for _, a := range addresses {
+		if a == address {
+			return true
+		}
+	}
+	return false
+}
+
+// publish sends the events followed by PING and waits for PONG, so errors of the server are reported
+func (t *Target) publish(ctx context.Context, events []Event) error {
+	if err := t.connect(ctx); err != nil {
+		return err
+	}
+	_ = t.conn.SetDeadline(time.Now().Add(ioTimeout))
+	for i := range events {
+		payload, err := json.Marshal(&events[i])
+		if err != nil {
+			return errors.Wrap(err, "can't marshal event of ips map")
+		}
+		_, _ = t.writer.WriteString("PUB " + t.Subject + " " + strconv.Itoa(len(payload)) + "\r\n")
+		_, _ = t.writer.Write(payload)
+		_, _ = t.writer.WriteString("\r\n")
+	}
+	return t.ping()
+}
+
+func (t *Target) ping() error {
+	_, _ = t.writer.WriteString("PING\r\n")
+	if err := t.writer.Flush(); err != nil {
+		return errors.Wrap(err, "can't write to nats")
+	}
+	for {
+		line, err := t.reader.ReadString('\n')
+		if err != nil {
+			return errors.Wrap(err, "can't read from nats")
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			_, _ = t.writer.WriteString("PONG\r\n")
+			if err = t.writer.Flush(); err != nil {
+				return errors.Wrap(err, "can't write to nats")
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return errors.Errorf("nats error: %v", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+	}
+}
+
+func (t *Target) connect(ctx context.Context) error {
+	if t.conn != nil {
+		return nil
+	}
+	u, err := url.Parse(t.URL)
+	if err != nil {
+		return errors.Wrapf(err, "can't parse nats url %v", t.URL)
+	}
+	var address = u.Host
+	if u.Port() == "" {
+		address = net.JoinHostPort(u.Hostname(), defaultPort)
+	}
+
+	conn, err := new(net.Dialer).DialContext(ctx, "tcp", address)
+	if err != nil {
+		return errors.Wrapf(err, "can't connect to nats %v", address)
+	}
+	t.conn, t.reader, t.writer = conn, bufio.NewReader(conn), bufio.NewWriter(conn)
+	_ = t.conn.SetDeadline(time.Now().Add(ioTimeout))
+
+	info, err := t.reader.ReadString('\n')
+	if err != nil {
+		t.Close()
+		return errors.Wrapf(err, "can't read greeting of nats %v", address)
+	}
+	if !strings.HasPrefix(info, "INFO") {
+		t.Close()
+		return errors.Errorf("unexpected greeting of nats %v: %q", address, info)
+	}
+
+	var options = map[string]interface{}{
+		"verbose":  false,
+		"pedantic": false,
+		"name":     clientName,
+		"lang":     "go",
+		"version":  "1.0.0",
+	}
+	if u.User != nil {
+		options["user"] = u.User.Username()
+		if password, ok := u.User.Password(); ok {
+			options["pass"] = password
+		}
+	}
+	if t.Token != "" {
+		options["auth_token"] = t.Token
+	}
+	connect, err := json.Marshal(options)
+	if err != nil {
+		t.Close()
+		return errors.Wrap(err, "can't marshal nats connect options")
+	}
+	_, _ = t.writer.WriteString("CONNECT " + string(connect) + "\r\n")
+	if err = t.ping(); err != nil {
+		t.Close()
+		return errors.Wrap(err, "can't connect to nats")
+	}
+	return nil
+}
+
+// Close closes the connection to NATS. It is reopened on the next write.
+func (t *Target) Close() {
+	if t.conn != nil {
+		_ = t.conn.Close()
+		t.conn = nil
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package natssink_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/natssink"
+)
+
+// fakeNATS supports the subset of the protocol used by the target
+type fakeNATS struct {
+	mu        sync.Mutex
+	connect   map[string]interface{}
+	published map[string][]natssink.Event
+}
+
+func (f *fakeNATS) serve(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+
+	_, _ = conn.Write([]byte("INFO {\"server_id\":\"fake\"}\r\n"))
+	var r = bufio.NewReader(conn)
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		var fields = strings.Fields(line)
+		f.mu.Lock()
+		switch fields[0] {
+		case "CONNECT":
+			_ = json.Unmarshal([]byte(strings.TrimPrefix(strings.TrimSpace(line), "CONNECT ")), &f.connect)
+		case "PUB":
+			n, _ := strconv.Atoi(fields[2])
+			var payload = make([]byte, n+2)
+			if _, err = io.ReadFull(r, payload); err != nil {
+				f.mu.Unlock()
+				return
+			}
+			var event natssink.Event
+			_ = json.Unmarshal(payload[:n], &event)
+			f.published[fields[1]] = append(f.published[fields[1]], event)
+		case "PING":
+			_, _ = conn.Write([]byte("PONG\r\n"))
+		}
+		f.mu.Unlock()
+	}
+}
+
+// startFakeNATS serves one connection until the returned stop is called
+func startFakeNATS(t *testing.T) (nats *fakeNATS, address string, stop func()) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	nats = &fakeNATS{published: make(map[string][]natssink.Event)}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		conn, acceptErr := listener.Accept()
+		if acceptErr == nil {
+			nats.serve(conn)
+		}
+	}()
+	return nats, listener.Addr().String(), func() {
+		_ = listener.Close()
+		wg.Wait()
+	}
+}
+
+func Test_NATSTarget(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	nats, address, stop := startFakeNATS(t)
+	var target = &natssink.Target{
+		URL:     "nats://user:secret@" + address,
+		Subject: "nsm.map-ip",
+	}
+
+	_, err := target.Write(context.Background(), &mapipwriter.Snapshot{
+		Generation: 1,
+		Entries: []mapipwriter.Entry{
+			{Translation: mapipwriter.Translation{From: "127.0.0.1", To: "148.142.120.1"}},
+			{Translation: mapipwriter.Translation{From: "127.0.0.2", To: "148.142.120.2"}},
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = target.Write(context.Background(), &mapipwriter.Snapshot{
+		Generation: 2,
+		Entries: []mapipwriter.Entry{
+			{Translation: mapipwriter.Translation{From: "127.0.0.1", To: "148.142.120.3"}},
+		},
+	})
+	require.NoError(t, err)
+
+	written, err := target.Write(context.Background(), &mapipwriter.Snapshot{
+		Generation: 2,
+		Entries: []mapipwriter.Entry{
+			{Translation: mapipwriter.Translation{From: "127.0.0.1", To: "148.142.120.3"}},
+		},
+	})
+	require.NoError(t, err)
+	require.False(t, written)
+
+	target.Close()
+	stop()
+
+	require.Equal(t, "user", nats.connect["user"])
+	require.Equal(t, "secret", nats.connect["pass"])
+	require.Equal(t, []natssink.Event{
+		{Type: watch.Added, From: "127.0.0.1", To: "148.142.120.1", Generation: 1},
+		{Type: watch.Added, From: "127.0.0.2", To: "148.142.120.2", Generation: 1},
+		{Type: watch.Modified, From: "127.0.0.1", To: "148.142.120.3", Generation: 2},
+		{Type: watch.Deleted, From: "127.0.0.2", Generation: 2},
+	}, nats.published["nsm.map-ip"])
+}
+
+func Test_NATSTargetMultipleTo(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	nats, address, stop := startFakeNATS(t)
+	var target = &natssink.Target{
+		URL:     "nats://" + address,
+		Subject: "nsm.map-ip",
+	}
+
+	for i, entries := range [][]mapipwriter.Entry{
+		{
+			{Translation: mapipwriter.Translation{From: "10.0.0.1", To: "1.1.1.1"}},
+			{Translation: mapipwriter.Translation{From: "10.0.0.1", To: "2.2.2.2"}},
+		},
+		{
+			{Translation: mapipwriter.Translation{From: "10.0.0.1", To: "1.1.1.1"}},
+			{Translation: mapipwriter.Translation{From: "10.0.0.1", To: "2.2.2.2"}},
+			{Translation: mapipwriter.Translation{From: "10.0.0.2", To: "3.3.3.3"}},
+		},
+		{
+			{Translation: mapipwriter.Translation{From: "10.0.0.1", To: "1.1.1.1"}},
+			{Translation: mapipwriter.Translation{From: "10.0.0.2", To: "3.3.3.3"}},
+		},
+		{
+			{Translation: mapipwriter.Translation{From: "10.0.0.1", To: "4.4.4.4"}},
+			{Translation: mapipwriter.Translation{From: "10.0.0.2", To: "3.3.3.3"}},
+		},
+	} {
+		_, err := target.Write(context.Background(), &mapipwriter.Snapshot{Generation: uint64(i + 1), Entries: entries})
+		require.NoError(t, err)
+	}
+
+	target.Close()
+	stop()
+
+	require.Equal(t, []natssink.Event{
+		{Type: watch.Added, From: "10.0.0.1", To: "1.1.1.1", Generation: 1},
+		{Type: watch.Added, From: "10.0.0.1", To: "2.2.2.2", Generation: 1},
+		{Type: watch.Added, From: "10.0.0.2", To: "3.3.3.3", Generation: 2},
+		{Type: watch.Deleted, From: "10.0.0.1", To: "2.2.2.2", Generation: 3},
+		{Type: watch.Modified, From: "10.0.0.1", To: "4.4.4.4", Generation: 4},
+	}, nats.published["nsm.map-ip"])
+}
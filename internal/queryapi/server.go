@@ -0,0 +1,150 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package queryapi provides a line-based query protocol over a unix socket resolving single ips from the map
+package queryapi
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+)
+
+const (
+	idleTimeout    = time.Minute
+	maxRequestSize = 4096
+)
+
+// Response is a JSON line written for each request
+type Response struct {
+	From       string `json:"from,omitempty"`
+	To         string `json:"to,omitempty"`
+	Generation uint64 `json:"generation"`
+	Error      string `json:"error,omitempty"`
+}
+
+type index struct {
+	generation uint64
+	byFrom     map[string]string
+}
+
+// Server answers requests written as lines to the connection:
+//
+//	resolve <ip>  - responds with the translation of the ip or the "not found" error
+//	generation    - responds with the generation of the map
+//
+// Each response is a Response written as a JSON line.
+type Server struct {
+	index atomic.Pointer[index]
+}
+
+// Name returns the name of the target
+func (s *Server) Name() string {
+	return "query"
+}
+
+// Write replaces the translations served by the server with the snapshot
+func (s *Server) Write(_ context.Context, snapshot *mapipwriter.Snapshot) (bool, error) {
+	if prev := s.index.Load(); prev != nil && prev.generation == snapshot.Generation {
+		return false, nil
+	}
+	var next = &index{generation: snapshot.Generation, byFrom: make(map[string]string, len(snapshot.Entries))}
+	for i := range snapshot.Entries {
+		next.byFrom[snapshot.Entries[i].From] = snapshot.Entries[i].To
+	}
+	s.index.Store(next)
+	return true, nil
+}
+
+func (s *Server) handle(request string) *Response {
+	var current = s.index.Load()
+	if current == nil {
+		current = new(index)
+	}
+	var response = &Response{Generation: current.generation}
+
+	var fields = strings.Fields(request)
+	switch {
+	case len(fields) == 2 && fields[0] == "resolve":
+		response.From = fields[1]
+		if to, ok := current.byFrom[fields[1]]; ok {
+			response.To = to
+		} else {
+			response.Error = "not found"
+		}
+	case len(fields) == 1 && fields[0] == "generation":
+	default:
+		response.Error = "unknown request"
+	}
+	return response
+}
+
+// ServeConn answers requests of the connection until it's closed or idle for a minute
+func (s *Server) ServeConn(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+
+	var scanner = bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, maxRequestSize), maxRequestSize)
+	var encoder = json.NewEncoder(conn)
+	for {
+		_ = conn.SetDeadline(time.Now().Add(idleTimeout))
+		if !scanner.Scan() {
+			return
+		}
+		if err := encoder.Encode(s.handle(scanner.Text())); err != nil {
+			return
+		}
+	}
+}
+
+// ListenAndServe listens on the unix socket and serves connections until the context is done. The socket is removed
+// on return.
+func (s *Server) ListenAndServe(ctx context.Context, path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "can't remove stale socket %v", path)
+	}
+	listener, err := new(net.ListenConfig).Listen(ctx, "unix", path)
+	if err != nil {
+		return errors.Wrapf(err, "can't listen on %v", path)
+	}
+
+	go func() {
+		<-ctx.Done()
+		_ = listener.Close()
+	}()
+
+	log.FromContext(ctx).Infof("query api is listening on %v", path)
+	for {
+		conn, acceptErr := listener.Accept()
+		if acceptErr != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return errors.Wrapf(acceptErr, "can't accept connection on %v", path)
+		}
+		go s.ServeConn(conn)
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queryapi_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/queryapi"
+)
+
+func Test_QueryServer(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var socket = filepath.Join(t.TempDir(), "map-ip.sock")
+	var server = new(queryapi.Server)
+	_, err := server.Write(ctx, &mapipwriter.Snapshot{
+		Generation: 3,
+		Entries:    []mapipwriter.Entry{{Translation: mapipwriter.Translation{From: "127.0.0.1", To: "148.142.120.1"}}},
+	})
+	require.NoError(t, err)
+
+	var done = make(chan error, 1)
+	go func() { done <- server.ListenAndServe(ctx, socket) }()
+	defer func() {
+		cancel()
+		require.NoError(t, <-done)
+	}()
+
+	var conn net.Conn
+	require.Eventually(t, func() bool {
+		conn, err = net.Dial("unix", socket)
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+	defer func() { _ = conn.Close() }()
+
+	var reader = bufio.NewReader(conn)
+	var query = func(request string) queryapi.Response {
+		_, writeErr := conn.Write([]byte(request + "\n"))
+		require.NoError(t, writeErr)
+		line, readErr := reader.ReadBytes('\n')
+		require.NoError(t, readErr)
+		var response queryapi.Response
+		require.NoError(t, json.Unmarshal(line, &response))
+		return response
+	}
+
+	require.Equal(t, queryapi.Response{From: "127.0.0.1", To: "148.142.120.1", Generation: 3}, query("resolve 127.0.0.1"))
+	require.Equal(t, queryapi.Response{From: "127.0.0.2", Generation: 3, Error: "not found"}, query("resolve 127.0.0.2"))
+	require.Equal(t, queryapi.Response{Generation: 3}, query("generation"))
+	require.Equal(t, "unknown request", query("unknown").Error)
+}
@@ -0,0 +1,170 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package redissink provides a target writing the map into a Redis hash and publishing changes on a channel
+package redissink
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+)
+
+const ioTimeout = 5 * time.Second
+
+// Change is the message published on the channel on each change of the map
+type Change struct {
+	Generation uint64            `json:"generation"`
+	Updated    map[string]string `json:"updated,omitempty"`
+	Removed    []string          `json:"removed,omitempty"`
+	// Full is set if the hash was rewritten, e.g. on start, so consumers should reload it
+	Full bool `json:"full,omitempty"`
+}
+
+// Target writes each translation as the From field of the Key hash with the To value. Changes are applied in a
+// transaction and published as Change on Channel if it's not empty.
+type Target struct {
+	// Address is host:port of Redis
+	Address  string
+	Password string
+	Key      string
+	Channel  string
+
+	conn      net.Conn
+	reader    *bufio.Reader
+	writer    *bufio.Writer
+	published map[string]string
+}
+
+// Name returns the name of the target
+func (t *Target) Name() string {
+	return "redis"
+}
+
+// Write applies changed and removed translations to the hash. The hash is rewritten on the first write.
+func (t *Target) Write(ctx context.Context, snapshot *mapipwriter.Snapshot) (bool, error) {
+	var change = Change{Generation: snapshot.Generation, Updated: make(map[string]string), Full: t.published == nil}
+
+	var next = make(map[string]string, len(snapshot.Entries))
+	for i := range snapshot.Entries {
+		next[snapshot.Entries[i].From] = snapshot.Entries[i].To
+	}
+	for from, to := range next {
+		if prev, ok := t.published[from]; !ok || prev != to {
+			change.Updated[from] = to
+		}
+	}
+	for from := range t.published {
+		if _, ok := next[from]; !ok {
+			change.Removed = append(change.Removed, from)
+		}
+	}
+	if !change.Full && len(change.Updated) == 0 && len(change.Removed) == 0 {
+		return false, nil
+	}
+
+	if err := t.apply(ctx, &change); err != nil {
+		t.Close()
+		return false, err
+	}
+	t.published = next
+	return true, nil
+}
+
+func (t *Target) apply(ctx context.Context, change *Change) error {
+	if err := t.connect(ctx); err != nil {
+		return err
+	}
+
+	var commands = [][]string{{"MULTI"}}
+	if change.Full {
+		commands = append(commands, []string{"DEL", t.Key})
+	}
+	if len(change.Updated) > 0 {
+		var hset = []string{"HSET", t.Key}
+		for from, to := range change.Updated {
+			hset = append(hset, from, to)
+		}
+		commands = append(commands, hset)
+	}
+	if len(change.Removed) > 0 {
+		commands = append(commands, append([]string{"HDEL", t.Key}, change.Removed...))
+	}
+	commands = append(commands, []string{"EXEC"})
+	if t.Channel != "" {
+		message, err := json.Marshal(change)
+		if err != nil {
+			return errors.Wrap(err, "can't marshal change of ips map")
+		}
+		commands = append(commands, []string{"PUBLISH", t.Channel, string(message)})
+	}
+
+	_, err := t.do(commands...)
+	return err
+}
+
+// do pipelines the commands and returns the reply of the last one
+func (t *Target) do(commands ...[]string) (interface{}, error) {
+	_ = t.conn.SetDeadline(time.Now().Add(ioTimeout))
+	for _, command := range commands {
+		writeCommand(t.writer, command...)
+	}
+	if err := t.writer.Flush(); err != nil {
+		return nil, errors.Wrap(err, "can't write redis commands")
+	}
+
+	var reply interface{}
+	var firstErr error
+	for range commands {
+		var err error
+		if reply, err = readReply(t.reader); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return reply, firstErr
+}
+
+func (t *Target) connect(ctx context.Context) error {
+	if t.conn != nil {
+		return nil
+	}
+	conn, err := new(net.Dialer).DialContext(ctx, "tcp", t.Address)
+	if err != nil {
+		return errors.Wrapf(err, "can't connect to redis %v", t.Address)
+	}
+	t.conn, t.reader, t.writer = conn, bufio.NewReader(conn), bufio.NewWriter(conn)
+	if t.Password != "" {
+		if _, err = t.do([]string{"AUTH", t.Password}); err != nil {
+			t.Close()
+			return errors.Wrap(err, "can't authenticate to redis")
+		}
+	}
+	return nil
+}
+
+// Close closes the connection to Redis. It is reopened on the next write.
+func (t *Target) Close() {
+	if t.conn != nil {
+		_ = t.conn.Close()
+		t.conn = nil
+	}
+}
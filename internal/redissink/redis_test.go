@@ -0,0 +1,171 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redissink_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/goleak"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/redissink"
+)
+
+// fakeRedis supports the subset of commands used by the target. Transactions are applied on EXEC.
+type fakeRedis struct {
+	mu        sync.Mutex
+	hash      map[string]string
+	published []redissink.Change
+}
+
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	n, _ := strconv.Atoi(strings.TrimSpace(line[1:]))
+	var result []string
+	for i := 0; i < n; i++ {
+		if _, err = r.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		var arg string
+		if arg, err = r.ReadString('\n'); err != nil {
+			return nil, err
+		}
+		result = append(result, strings.TrimSuffix(arg, "\r\n"))
+	}
+	return result, nil
+}
+
+func (f *fakeRedis) serve(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+
+	var r = bufio.NewReader(conn)
+	var queued [][]string
+	for {
+		command, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		f.mu.Lock()
+		switch command[0] {
+		case "MULTI":
+			_, _ = conn.Write([]byte("+OK\r\n"))
+		case "DEL", "HSET", "HDEL":
+			queued = append(queued, command)
+			_, _ = conn.Write([]byte("+QUEUED\r\n"))
+		case "EXEC":
+			for _, c := range queued {
+				f.exec(c)
+			}
+			_, _ = conn.Write([]byte("*" + strconv.Itoa(len(queued)) + "\r\n" + strings.Repeat(":1\r\n", len(queued))))
+			queued = nil
+		case "PUBLISH":
+			var change redissink.Change
+			_ = json.Unmarshal([]byte(command[2]), &change)
+			f.published = append(f.published, change)
+			_, _ = conn.Write([]byte(":1\r\n"))
+		default:
+			_, _ = conn.Write([]byte("-ERR unknown command\r\n"))
+		}
+		f.mu.Unlock()
+	}
+}
+
+func (f *fakeRedis) exec(command []string) {
+	switch command[0] {
+	case "DEL":
+		f.hash = make(map[string]string)
+	case "HSET":
+		for i := 2; i+1 < len(command); i += 2 {
+			f.hash[command[i]] = command[i+1]
+		}
+	case "HDEL":
+		for _, field := range command[2:] {
+			delete(f.hash, field)
+		}
+	}
+}
+
+func Test_RedisTarget(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	var redis = &fakeRedis{hash: map[string]string{"stale": "1.1.1.1"}}
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		conn, acceptErr := listener.Accept()
+		if acceptErr == nil {
+			redis.serve(conn)
+		}
+	}()
+
+	var target = &redissink.Target{
+		Address: listener.Addr().String(),
+		Key:     "nsm:map-ip",
+		Channel: "nsm:map-ip:changes",
+	}
+
+	_, err = target.Write(context.Background(), &mapipwriter.Snapshot{
+		Generation: 1,
+		Entries: []mapipwriter.Entry{
+			{Translation: mapipwriter.Translation{From: "127.0.0.1", To: "148.142.120.1"}},
+			{Translation: mapipwriter.Translation{From: "127.0.0.2", To: "148.142.120.2"}},
+		},
+	})
+	require.NoError(t, err)
+
+	_, err = target.Write(context.Background(), &mapipwriter.Snapshot{
+		Generation: 2,
+		Entries: []mapipwriter.Entry{
+			{Translation: mapipwriter.Translation{From: "127.0.0.1", To: "148.142.120.1"}},
+		},
+	})
+	require.NoError(t, err)
+
+	written, err := target.Write(context.Background(), &mapipwriter.Snapshot{
+		Generation: 2,
+		Entries: []mapipwriter.Entry{
+			{Translation: mapipwriter.Translation{From: "127.0.0.1", To: "148.142.120.1"}},
+		},
+	})
+	require.NoError(t, err)
+	require.False(t, written)
+
+	_ = listener.Close()
+	target.Close()
+	wg.Wait()
+
+	require.Equal(t, map[string]string{"127.0.0.1": "148.142.120.1"}, redis.hash)
+	require.Equal(t, []redissink.Change{
+		{Generation: 1, Full: true, Updated: map[string]string{"127.0.0.1": "148.142.120.1", "127.0.0.2": "148.142.120.2"}},
+		{Generation: 2, Removed: []string{"127.0.0.2"}},
+	}, redis.published)
+}
@@ -0,0 +1,92 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package redissink
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// writeCommand writes the command as a RESP array of bulk strings. Errors are reported by Flush of the writer.
+func writeCommand(w *bufio.Writer, args ...string) {
+	_, _ = w.WriteString("*" + strconv.Itoa(len(args)) + "\r\n")
+	for _, arg := range args {
+		_, _ = w.WriteString("$" + strconv.Itoa(len(arg)) + "\r\n")
+		_, _ = w.WriteString(arg)
+		_, _ = w.WriteString("\r\n")
+	}
+}
+
+// readReply reads a RESP reply. Error replies are returned as errors, arrays are returned as []interface{}.
+func readReply(r *bufio.Reader) (interface{}, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, errors.Wrap(err, "can't read redis reply")
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, errors.Errorf("invalid redis reply %q", line)
+	}
+	var payload = line[1 : len(line)-2]
+
+	switch line[0] {
+	case '+':
+		return payload, nil
+	case '-':
+		return nil, errors.Errorf("redis error: %v", payload)
+	case ':':
+		return strconv.ParseInt(payload, 10, 64)
+	case '$':
+		return readBulkString(r, payload)
+	case '*':
+		return readArray(r, payload)
+	default:
+		return nil, errors.Errorf("invalid redis reply %q", line)
+	}
+}
+
+// readBulkString reads the bulk string of the length from the payload of the reply. Null is returned as nil.
+func readBulkString(r *bufio.Reader, payload string) (interface{}, error) {
+	n, err := strconv.Atoi(payload)
+	if err != nil || n < 0 {
+		return nil, err
+	}
+	var buf = make([]byte, n+2)
+	if _, err = io.ReadFull(r, buf); err != nil {
+		return nil, errors.Wrap(err, "can't read redis reply")
+	}
+	return string(buf[:n]), nil
+}
+
+// readArray reads the count of replies from the payload of the reply. Null is returned as nil.
+func readArray(r *bufio.Reader, payload string) (interface{}, error) {
+	n, err := strconv.Atoi(payload)
+	if err != nil || n < 0 {
+		return nil, err
+	}
+	var result = make([]interface{}, 0, n)
+	for i := 0; i < n; i++ {
+		var item interface{}
+		if item, err = readReply(r); err != nil {
+			return nil, err
+		}
+		result = append(result, item)
+	}
+	return result, nil
+}
@@ -0,0 +1,88 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package remap provides CIDR based remapping of ip addresses
+package remap
+
+import (
+	"net"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+type rule struct {
+	from, to *net.IPNet
+}
+
+// Remap replaces the prefix of addresses matching one of its rules keeping the host part of the address
+type Remap []rule
+
+// Parse parses comma separated list of rules in form of fromCIDR=toCIDR. Both CIDRs of the rule should have the same
+// address family and the prefix length.
+func Parse(s string) (Remap, error) {
+	var result Remap
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.Split(item, "=")
+		if len(parts) != 2 {
+			return nil, errors.Errorf("invalid remap rule %q: expected fromCIDR=toCIDR", item)
+		}
+		_, from, err := net.ParseCIDR(strings.TrimSpace(parts[0]))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid remap rule %q", item)
+		}
+		_, to, err := net.ParseCIDR(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid remap rule %q", item)
+		}
+		fromOnes, fromBits := from.Mask.Size()
+		toOnes, toBits := to.Mask.Size()
+		if fromOnes != toOnes || fromBits != toBits {
+			return nil, errors.Errorf("invalid remap rule %q: CIDRs should have the same family and prefix length", item)
+		}
+		result = append(result, rule{from: from, to: to})
+	}
+	return result, nil
+}
+
+// Apply returns remapped address by the first matching rule. Address is returned as is if no rules are matched.
+func (r Remap) Apply(addr string) string {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return addr
+	}
+	for _, item := range r {
+		if !item.from.Contains(ip) {
+			continue
+		}
+		var src = ip.To16()
+		var prefix = item.to.IP.To16()
+		var mask = item.to.Mask
+		if len(mask) == net.IPv4len {
+			src, prefix = ip.To4(), item.to.IP.To4()
+		}
+		var result = make(net.IP, len(src))
+		for i := range src {
+			result[i] = prefix[i] | (src[i] &^ mask[i])
+		}
+		return result.String()
+	}
+	return addr
+}
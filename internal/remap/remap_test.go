@@ -0,0 +1,37 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remap_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/remap"
+)
+
+func Test_Remap(t *testing.T) {
+	r, err := remap.Parse("10.0.0.0/8=192.0.0.0/8, fd00::/64=2001:db8::/64")
+	require.NoError(t, err)
+
+	require.Equal(t, "192.1.2.3", r.Apply("10.1.2.3"))
+	require.Equal(t, "2001:db8::5", r.Apply("fd00::5"))
+	require.Equal(t, "11.1.2.3", r.Apply("11.1.2.3"))
+
+	_, err = remap.Parse("10.0.0.0/8=192.168.0.0/16")
+	require.Error(t, err)
+}
@@ -0,0 +1,165 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package restapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+)
+
+// Types of events
+const (
+	EventAdded   = "added"
+	EventRemoved = "removed"
+)
+
+// Event is an added or removed translation streamed by /events. A changed translation is streamed as removed and
+// added events.
+type Event struct {
+	Type       string `json:"type"`
+	From       string `json:"from"`
+	To         string `json:"to"`
+	Generation uint64 `json:"generation"`
+}
+
+// translationSet is a set of translations streamed to a client
+type translationSet map[mapipwriter.Translation]struct{}
+
+// diff returns events changing the prev set of translations to the snapshot and the set of the snapshot
+func diff(prev translationSet, snapshot *mapipwriter.Snapshot) ([]Event, translationSet) {
+	var events []Event
+	var next = make(translationSet, len(snapshot.Entries))
+	for i := range snapshot.Entries {
+		next[snapshot.Entries[i].Translation] = struct{}{}
+	}
+
+	var removed []mapipwriter.Translation
+	for translation := range prev {
+		if _, ok := next[translation]; !ok {
+			removed = append(removed, translation)
+		}
+	}
+	sort.Slice(removed, func(i, j int) bool {
+		if removed[i].From != removed[j].From {
+			return removed[i].From < removed[j].From
+		}
+		return removed[i].To < removed[j].To
+	})
+	for _, translation := range removed {
+		events = append(events, newEvent(EventRemoved, translation, snapshot.Generation))
+	}
+	var added = make(translationSet)
+	for i := range snapshot.Entries {
+		var translation = snapshot.Entries[i].Translation
+		if _, ok := prev[translation]; ok {
+			continue
+		}
+		if _, ok := added[translation]; ok {
+			continue
+		}
+		added[translation] = struct{}{}
+		events = append(events, newEvent(EventAdded, translation, snapshot.Generation))
+	}
+	return events, next
+}
+
+func newEvent(eventType string, translation mapipwriter.Translation, generation uint64) Event {
+	return Event{Type: eventType, From: translation.From, To: translation.To, Generation: generation}
+}
+
+// watch sends the current map as added events and then events of each change until the context is done or send fails
+func (s *Server) watch(ctx context.Context, send func([]Event) error) error {
+	var published translationSet
+	for {
+		snapshot, changed := s.Current()
+		var events []Event
+		events, published = diff(published, snapshot)
+		if len(events) > 0 {
+			if err := send(events); err != nil {
+				return err
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-changed:
+		}
+	}
+}
+
+// getEvents streams events as Server-Sent Events or as WebSocket text frames if the request upgrades the connection
+func (s *Server) getEvents(w http.ResponseWriter, r *http.Request) {
+	if strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		websocket.Server{
+			// Dashboards of any origin may subscribe
+			Handshake: func(*websocket.Config, *http.Request) error { return nil },
+			Handler: func(conn *websocket.Conn) {
+				// the hijacked connection doesn't cancel the context of the request, so closing is detected by reading
+				ctx, cancel := context.WithCancel(r.Context())
+				defer cancel()
+				go func() {
+					defer cancel()
+					_, _ = io.Copy(io.Discard, conn)
+				}()
+				_ = s.watch(ctx, func(events []Event) error {
+					for i := range events {
+						if err := websocket.JSON.Send(conn, &events[i]); err != nil {
+							return err
+						}
+					}
+					return nil
+				})
+			},
+		}.ServeHTTP(w, r)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	_ = s.watch(r.Context(), func(events []Event) error {
+		for i := range events {
+			data, err := json.Marshal(&events[i])
+			if err != nil {
+				return err
+			}
+			var id = strconv.FormatUint(events[i].Generation, 10)
+			if _, err = fmt.Fprintf(w, "id: %v\nevent: %v\ndata: %s\n\n", id, events[i].Type, data); err != nil {
+				return err
+			}
+		}
+		flusher.Flush()
+		return nil
+	})
+}
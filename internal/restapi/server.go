@@ -0,0 +1,184 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package restapi provides an HTTP server exposing the map of ips as JSON
+package restapi
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+)
+
+const (
+	readHeaderTimeout = 5 * time.Second
+	// MaxWait limits the wait query parameter of long polling requests
+	MaxWait = 5 * time.Minute
+)
+
+// Translation is a translation of the map
+type Translation struct {
+	From     string `json:"from"`
+	To       string `json:"to"`
+	Original string `json:"original,omitempty"`
+}
+
+// Mappings is the response of GET /mappings
+type Mappings struct {
+	Generation   uint64        `json:"generation"`
+	Translations []Translation `json:"translations"`
+}
+
+// Server serves:
+//
+//	GET /mappings       - the map as Mappings
+//	GET /mappings/{ip}  - the Translation of the ip or 404
+//	GET /events         - Event stream of the map as Server-Sent Events or WebSocket text frames
+//
+// Responses have the ETag of the generation of the map and the instance of the server. Requests with a matching
+// If-None-Match header get 304, or wait for a change of the map up to the ?wait= duration if it's set (long polling).
+type Server struct {
+	mapipwriter.Latest
+
+	once     sync.Once
+	instance string
+}
+
+// Name returns the name of the target
+func (s *Server) Name() string {
+	return "rest"
+}
+
+// Handler returns the HTTP handler of the API
+func (s *Server) Handler() http.Handler {
+	var mux = http.NewServeMux()
+	mux.HandleFunc("GET /mappings", s.getMappings)
+	mux.HandleFunc("GET /mappings/{ip}", s.getMapping)
+	mux.HandleFunc("GET /events", s.getEvents)
+	return mux
+}
+
+// etag returns the ETag of the snapshot. Generations restart from zero with the app, so the ETag includes the random
+// instance of the server and ETags of the map served before a restart don't match.
+func (s *Server) etag(snapshot *mapipwriter.Snapshot) string {
+	s.once.Do(func() {
+		var instance [8]byte
+		_, _ = rand.Read(instance[:])
+		s.instance = hex.EncodeToString(instance[:])
+	})
+	return `"` + s.instance + "-" + strconv.FormatUint(snapshot.Generation, 10) + `"`
+}
+
+// wait returns the snapshot to respond with or nil if the client already has the latest snapshot
+func (s *Server) wait(w http.ResponseWriter, r *http.Request) (*mapipwriter.Snapshot, bool) {
+	var timeout time.Duration
+	if value := r.URL.Query().Get("wait"); value != "" {
+		var err error
+		if timeout, err = time.ParseDuration(value); err != nil || timeout < 0 {
+			http.Error(w, "invalid wait duration", http.StatusBadRequest)
+			return nil, false
+		}
+		if timeout > MaxWait {
+			timeout = MaxWait
+		}
+	}
+
+	snapshot, changed := s.Current()
+	var ifNoneMatch = r.Header.Get("If-None-Match")
+	if ifNoneMatch == "" || ifNoneMatch != s.etag(snapshot) {
+		return snapshot, true
+	}
+	if timeout > 0 {
+		var timer = time.NewTimer(timeout)
+		defer timer.Stop()
+		select {
+		case <-changed:
+			snapshot, _ = s.Current()
+			return snapshot, true
+		case <-timer.C:
+		case <-r.Context().Done():
+			return nil, false
+		}
+	}
+	w.Header().Set("ETag", s.etag(snapshot))
+	w.WriteHeader(http.StatusNotModified)
+	return nil, false
+}
+
+func (s *Server) getMappings(w http.ResponseWriter, r *http.Request) {
+	snapshot, ok := s.wait(w, r)
+	if !ok {
+		return
+	}
+	var result = Mappings{Generation: snapshot.Generation, Translations: make([]Translation, 0, len(snapshot.Entries))}
+	for i := range snapshot.Entries {
+		var e = &snapshot.Entries[i]
+		result.Translations = append(result.Translations, Translation{From: e.From, To: e.To, Original: e.Original})
+	}
+	writeJSON(w, s.etag(snapshot), result)
+}
+
+func (s *Server) getMapping(w http.ResponseWriter, r *http.Request) {
+	snapshot, ok := s.wait(w, r)
+	if !ok {
+		return
+	}
+	var ip = r.PathValue("ip")
+	for i := range snapshot.Entries {
+		if e := &snapshot.Entries[i]; e.From == ip {
+			writeJSON(w, s.etag(snapshot), Translation{From: e.From, To: e.To, Original: e.Original})
+			return
+		}
+	}
+	w.Header().Set("ETag", s.etag(snapshot))
+	http.Error(w, "no translation of "+ip, http.StatusNotFound)
+}
+
+func writeJSON(w http.ResponseWriter, etag string, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("ETag", etag)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// ListenAndServe serves the API on the TCP address until the context is done
+func (s *Server) ListenAndServe(ctx context.Context, addr string) error {
+	var server = &http.Server{
+		Addr:              addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: readHeaderTimeout,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+	log.FromContext(ctx).Infof("rest api is listening on %v", addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return errors.Wrapf(err, "can't serve rest api on %v", addr)
+	}
+	return nil
+}
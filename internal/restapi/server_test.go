@@ -0,0 +1,230 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package restapi_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/restapi"
+)
+
+func get(t *testing.T, url, ifNoneMatch string) *http.Response {
+	request, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, http.NoBody)
+	require.NoError(t, err)
+	if ifNoneMatch != "" {
+		request.Header.Set("If-None-Match", ifNoneMatch)
+	}
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = response.Body.Close() })
+	return response
+}
+
+func Test_RESTServer(t *testing.T) {
+	var api = new(restapi.Server)
+	_, err := api.Write(context.Background(), &mapipwriter.Snapshot{
+		Generation: 1,
+		Entries:    []mapipwriter.Entry{{Translation: mapipwriter.Translation{From: "127.0.0.1", To: "148.142.120.1"}}},
+	})
+	require.NoError(t, err)
+
+	var server = httptest.NewServer(api.Handler())
+	defer server.Close()
+
+	response := get(t, server.URL+"/mappings", "")
+	require.Equal(t, http.StatusOK, response.StatusCode)
+	var etag = response.Header.Get("ETag")
+	require.True(t, strings.HasSuffix(etag, `-1"`), etag)
+	var mappings restapi.Mappings
+	require.NoError(t, json.NewDecoder(response.Body).Decode(&mappings))
+	require.Equal(t, restapi.Mappings{
+		Generation:   1,
+		Translations: []restapi.Translation{{From: "127.0.0.1", To: "148.142.120.1"}},
+	}, mappings)
+
+	response = get(t, server.URL+"/mappings/127.0.0.1", "")
+	require.Equal(t, http.StatusOK, response.StatusCode)
+	var translation restapi.Translation
+	require.NoError(t, json.NewDecoder(response.Body).Decode(&translation))
+	require.Equal(t, "148.142.120.1", translation.To)
+
+	require.Equal(t, http.StatusNotFound, get(t, server.URL+"/mappings/127.0.0.2", "").StatusCode)
+	require.Equal(t, http.StatusNotModified, get(t, server.URL+"/mappings", etag).StatusCode)
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		_, _ = api.Write(context.Background(), &mapipwriter.Snapshot{
+			Generation: 2,
+			Entries:    []mapipwriter.Entry{{Translation: mapipwriter.Translation{From: "127.0.0.1", To: "148.142.120.2"}}},
+		})
+	}()
+	response = get(t, server.URL+"/mappings/127.0.0.1?wait=10s", etag)
+	require.Equal(t, http.StatusOK, response.StatusCode)
+	require.Equal(t, strings.TrimSuffix(etag, `1"`)+`2"`, response.Header.Get("ETag"))
+	require.NoError(t, json.NewDecoder(response.Body).Decode(&translation))
+	require.Equal(t, "148.142.120.2", translation.To)
+}
+
+func Test_RESTServerRestarted(t *testing.T) {
+	var snapshot = &mapipwriter.Snapshot{
+		Generation: 1,
+		Entries:    []mapipwriter.Entry{{Translation: mapipwriter.Translation{From: "127.0.0.1", To: "148.142.120.1"}}},
+	}
+	var api = new(restapi.Server)
+	_, err := api.Write(context.Background(), snapshot)
+	require.NoError(t, err)
+	var server = httptest.NewServer(api.Handler())
+	defer server.Close()
+
+	var etag = get(t, server.URL+"/mappings", "").Header.Get("ETag")
+	require.Equal(t, http.StatusNotModified, get(t, server.URL+"/mappings", etag).StatusCode)
+
+	// the restarted app has the same generation of another map
+	snapshot.Entries[0].To = "148.142.120.2"
+	var restarted = new(restapi.Server)
+	_, err = restarted.Write(context.Background(), snapshot)
+	require.NoError(t, err)
+	var restartedServer = httptest.NewServer(restarted.Handler())
+	defer restartedServer.Close()
+
+	var response = get(t, restartedServer.URL+"/mappings", etag)
+	require.Equal(t, http.StatusOK, response.StatusCode)
+	require.NotEqual(t, etag, response.Header.Get("ETag"))
+}
+
+// sseReader returns a function reading the next event of the stream
+func sseReader(t *testing.T, response *http.Response) func() restapi.Event {
+	var lines = bufio.NewReader(response.Body)
+	return func() restapi.Event {
+		var event restapi.Event
+		for {
+			line, err := lines.ReadString('\n')
+			require.NoError(t, err)
+			if data, ok := strings.CutPrefix(line, "data: "); ok {
+				require.NoError(t, json.Unmarshal([]byte(data), &event))
+				return event
+			}
+		}
+	}
+}
+
+func Test_RESTServerEvents(t *testing.T) {
+	var api = new(restapi.Server)
+	_, err := api.Write(context.Background(), &mapipwriter.Snapshot{
+		Generation: 1,
+		Entries:    []mapipwriter.Entry{{Translation: mapipwriter.Translation{From: "127.0.0.1", To: "148.142.120.1"}}},
+	})
+	require.NoError(t, err)
+
+	var server = httptest.NewServer(api.Handler())
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	sse := get(t, server.URL+"/events", "")
+	defer func() { _ = sse.Body.Close() }()
+	require.Equal(t, "text/event-stream", sse.Header.Get("Content-Type"))
+	var readSSE = sseReader(t, sse)
+
+	ws, err := websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/events", "", server.URL)
+	require.NoError(t, err)
+	defer func() { _ = ws.Close() }()
+	require.NoError(t, ws.SetDeadline(time.Now().Add(10*time.Second)))
+
+	var readWS = func() restapi.Event {
+		var event restapi.Event
+		require.NoError(t, websocket.JSON.Receive(ws, &event))
+		return event
+	}
+
+	var added = restapi.Event{Type: restapi.EventAdded, From: "127.0.0.1", To: "148.142.120.1", Generation: 1}
+	require.Equal(t, added, readSSE())
+	require.Equal(t, added, readWS())
+
+	_, err = api.Write(ctx, &mapipwriter.Snapshot{
+		Generation: 2,
+		Entries:    []mapipwriter.Entry{{Translation: mapipwriter.Translation{From: "127.0.0.1", To: "148.142.120.2"}}},
+	})
+	require.NoError(t, err)
+
+	var removed = restapi.Event{Type: restapi.EventRemoved, From: "127.0.0.1", To: "148.142.120.1", Generation: 2}
+	added = restapi.Event{Type: restapi.EventAdded, From: "127.0.0.1", To: "148.142.120.2", Generation: 2}
+	require.Equal(t, removed, readSSE())
+	require.Equal(t, added, readSSE())
+	require.Equal(t, removed, readWS())
+	require.Equal(t, added, readWS())
+}
+
+func Test_RESTServerEventsMultipleTo(t *testing.T) {
+	var api = new(restapi.Server)
+	var server = httptest.NewServer(api.Handler())
+	defer server.Close()
+
+	sse := get(t, server.URL+"/events", "")
+	defer func() { _ = sse.Body.Close() }()
+	var readSSE = sseReader(t, sse)
+
+	for i, step := range []struct {
+		entries []mapipwriter.Entry
+		events  []restapi.Event
+	}{
+		{
+			entries: []mapipwriter.Entry{
+				{Translation: mapipwriter.Translation{From: "10.0.0.1", To: "1.1.1.1"}},
+				{Translation: mapipwriter.Translation{From: "10.0.0.1", To: "2.2.2.2"}},
+			},
+			events: []restapi.Event{
+				{Type: restapi.EventAdded, From: "10.0.0.1", To: "1.1.1.1", Generation: 1},
+				{Type: restapi.EventAdded, From: "10.0.0.1", To: "2.2.2.2", Generation: 1},
+			},
+		},
+		{
+			entries: []mapipwriter.Entry{
+				{Translation: mapipwriter.Translation{From: "10.0.0.1", To: "1.1.1.1"}},
+				{Translation: mapipwriter.Translation{From: "10.0.0.1", To: "2.2.2.2"}},
+				{Translation: mapipwriter.Translation{From: "10.0.0.2", To: "3.3.3.3"}},
+			},
+			events: []restapi.Event{{Type: restapi.EventAdded, From: "10.0.0.2", To: "3.3.3.3", Generation: 2}},
+		},
+		{
+			entries: []mapipwriter.Entry{
+				{Translation: mapipwriter.Translation{From: "10.0.0.1", To: "1.1.1.1"}},
+				{Translation: mapipwriter.Translation{From: "10.0.0.2", To: "3.3.3.3"}},
+			},
+			events: []restapi.Event{{Type: restapi.EventRemoved, From: "10.0.0.1", To: "2.2.2.2", Generation: 3}},
+		},
+	} {
+		_, err := api.Write(context.Background(), &mapipwriter.Snapshot{Generation: uint64(i + 1), Entries: step.entries})
+		require.NoError(t, err)
+		// events of each write are read before the next one, so writes aren't coalesced
+		for _, event := range step.events {
+			require.Equal(t, event, readSSE())
+		}
+	}
+}
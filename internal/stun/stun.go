@@ -0,0 +1,243 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package stun provides discovery of the public address of the host behind NAT using STUN Binding requests (RFC 5389)
+package stun
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"net"
+	"net/netip"
+	"slices"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Name is the name of the provider of public IPs
+const Name = "stun"
+
+// DefaultTimeout is the timeout of a Binding transaction with a server if Client.Timeout is not set
+const DefaultTimeout = 3 * time.Second
+
+const (
+	headerLen          = 20
+	magicCookie        = 0x2112A442
+	bindingRequest     = 0x0001
+	bindingSuccess     = 0x0101
+	attrMappedAddress  = 0x0001
+	attrXorMappedAddr  = 0x0020
+	familyIPv4         = 0x01
+	familyIPv6         = 0x02
+	maxMessageLen      = 1500
+	transactionIDBytes = 12
+)
+
+// Mapping is the public address a server observed the requests from
+type Mapping struct {
+	Server  string
+	Address netip.AddrPort
+}
+
+// Result is the result of the discovery
+type Result struct {
+	Mappings []Mapping
+	// EndpointIndependent is true if all servers observed the same public address and port, so the NAT keeps the
+	// mapping regardless of the destination. It's false for symmetric NATs and if less than two servers responded.
+	EndpointIndependent bool
+}
+
+// IPs returns distinct public IPs of the mappings
+func (r *Result) IPs() []string {
+	var ips []string
+	for _, mapping := range r.Mappings {
+		if ip := mapping.Address.Addr().String(); !slices.Contains(ips, ip) {
+			ips = append(ips, ip)
+		}
+	}
+	return ips
+}
+
+// Client sends Binding requests to Servers from a single UDP socket
+type Client struct {
+	// Servers are host:port of STUN servers
+	Servers []string
+	// Timeout of each transaction. Zero value means DefaultTimeout.
+	Timeout time.Duration
+}
+
+// Name returns Name
+func (c *Client) Name() string {
+	return Name
+}
+
+// PublicIPs returns distinct public IPs observed by the servers
+func (c *Client) PublicIPs(ctx context.Context) ([]string, error) {
+	result, err := c.Discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return result.IPs(), nil
+}
+
+// Discover sends a Binding request to each server. Servers that don't respond are skipped. It returns an error if no
+// server responded.
+func (c *Client) Discover(ctx context.Context) (*Result, error) {
+	var listenConfig net.ListenConfig
+	conn, err := listenConfig.ListenPacket(ctx, "udp", ":0")
+	if err != nil {
+		return nil, errors.Wrap(err, "can't listen UDP")
+	}
+	defer func() { _ = conn.Close() }()
+
+	var result = new(Result)
+	var lastErr = errors.New("no STUN servers")
+	for _, server := range c.Servers {
+		address, bindErr := c.bind(ctx, conn, server)
+		if bindErr != nil {
+			lastErr = bindErr
+			continue
+		}
+		result.Mappings = append(result.Mappings, Mapping{Server: server, Address: address})
+	}
+	if len(result.Mappings) == 0 {
+		return nil, lastErr
+	}
+
+	result.EndpointIndependent = len(result.Mappings) > 1
+	for _, mapping := range result.Mappings[1:] {
+		if mapping.Address != result.Mappings[0].Address {
+			result.EndpointIndependent = false
+		}
+	}
+	return result, nil
+}
+
+func (c *Client) bind(ctx context.Context, conn net.PacketConn, server string) (netip.AddrPort, error) {
+	var resolver net.Resolver
+	host, port, err := net.SplitHostPort(server)
+	if err != nil {
+		return netip.AddrPort{}, errors.Wrapf(err, "invalid STUN server %v", server)
+	}
+	ips, err := resolver.LookupNetIP(ctx, "ip", host)
+	if err != nil || len(ips) == 0 {
+		return netip.AddrPort{}, errors.Wrapf(err, "can't resolve STUN server %v", server)
+	}
+	portNumber, err := net.LookupPort("udp", port)
+	if err != nil {
+		return netip.AddrPort{}, errors.Wrapf(err, "invalid port of STUN server %v", server)
+	}
+	// #nosec G115 -- LookupPort returns 16 bit port numbers
+	var to = net.UDPAddrFromAddrPort(netip.AddrPortFrom(ips[0].Unmap(), uint16(portNumber)))
+
+	var request = make([]byte, headerLen)
+	binary.BigEndian.PutUint16(request[0:], bindingRequest)
+	binary.BigEndian.PutUint32(request[4:], magicCookie)
+	if _, err = rand.Read(request[8:headerLen]); err != nil {
+		return netip.AddrPort{}, errors.Wrap(err, "can't generate transaction ID")
+	}
+
+	var timeout = c.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	var deadline = time.Now().Add(timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if err = conn.SetDeadline(deadline); err != nil {
+		return netip.AddrPort{}, errors.Wrap(err, "can't set deadline")
+	}
+	if _, err = conn.WriteTo(request, to); err != nil {
+		return netip.AddrPort{}, errors.Wrapf(err, "can't send Binding request to %v", server)
+	}
+
+	var buf = make([]byte, maxMessageLen)
+	for {
+		n, _, readErr := conn.ReadFrom(buf)
+		if readErr != nil {
+			return netip.AddrPort{}, errors.Wrapf(readErr, "no Binding response from %v", server)
+		}
+		// responses of previous transactions are skipped by the transaction ID
+		if address, ok := parseResponse(buf[:n], request[8:headerLen]); ok {
+			return address, nil
+		}
+	}
+}
+
+// parseResponse returns the mapped address of the Binding success response of the transaction
+func parseResponse(message, transactionID []byte) (netip.AddrPort, bool) {
+	if len(message) < headerLen || binary.BigEndian.Uint16(message[0:]) != bindingSuccess ||
+		binary.BigEndian.Uint32(message[4:]) != magicCookie || string(message[8:headerLen]) != string(transactionID) {
+		return netip.AddrPort{}, false
+	}
+	var length = int(binary.BigEndian.Uint16(message[2:]))
+	if headerLen+length > len(message) {
+		return netip.AddrPort{}, false
+	}
+
+	var mapped netip.AddrPort
+	for attrs := message[headerLen : headerLen+length]; len(attrs) >= 4; {
+		var attrType, attrLen = binary.BigEndian.Uint16(attrs[0:]), int(binary.BigEndian.Uint16(attrs[2:]))
+		if 4+attrLen > len(attrs) {
+			break
+		}
+		var value = attrs[4 : 4+attrLen]
+		switch attrType {
+		case attrXorMappedAddr:
+			if address, ok := parseAddress(value, message[4:headerLen]); ok {
+				return address, true
+			}
+		case attrMappedAddress:
+			if address, ok := parseAddress(value, nil); ok {
+				mapped = address
+			}
+		}
+		// attributes are padded to 4 bytes
+		var next = 4 + (attrLen+3)&^3
+		if next > len(attrs) {
+			break
+		}
+		attrs = attrs[next:]
+	}
+	return mapped, mapped.IsValid()
+}
+
+// parseAddress parses the value of MAPPED-ADDRESS or XOR-MAPPED-ADDRESS if xor is the magic cookie followed by the
+// transaction ID
+func parseAddress(value, xor []byte) (netip.AddrPort, bool) {
+	if len(value) < 4 {
+		return netip.AddrPort{}, false
+	}
+	var port = binary.BigEndian.Uint16(value[2:])
+	var ip = slices.Clone(value[4:])
+	switch {
+	case value[1] == familyIPv4 && len(ip) == net.IPv4len:
+	case value[1] == familyIPv6 && len(ip) == net.IPv6len:
+	default:
+		return netip.AddrPort{}, false
+	}
+	if xor != nil {
+		port ^= uint16(magicCookie >> 16)
+		for i := range ip {
+			ip[i] ^= xor[i]
+		}
+	}
+	addr, _ := netip.AddrFromSlice(ip)
+	return netip.AddrPortFrom(addr, port), true
+}
@@ -0,0 +1,83 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package stun_test
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/stun"
+)
+
+// serveSTUN responds to Binding requests with XOR-MAPPED-ADDRESS of the source address with the port shifted by
+// portShift to emulate a NAT with address dependent mapping
+func serveSTUN(t *testing.T, portShift uint16) string {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	go func() {
+		var buf = make([]byte, 1500)
+		for {
+			n, from, readErr := conn.ReadFrom(buf)
+			if readErr != nil {
+				return
+			}
+			if n < 20 || binary.BigEndian.Uint16(buf) != 0x0001 {
+				continue
+			}
+			var source = from.(*net.UDPAddr).AddrPort()
+			var response = make([]byte, 20, 32)
+			binary.BigEndian.PutUint16(response[0:], 0x0101)
+			binary.BigEndian.PutUint16(response[2:], 12)
+			copy(response[4:20], buf[4:20])
+			var ip = source.Addr().As4()
+			response = binary.BigEndian.AppendUint16(response, 0x0020)
+			response = binary.BigEndian.AppendUint16(response, 8)
+			response = append(response, 0, 0x01)
+			response = binary.BigEndian.AppendUint16(response, (source.Port()+portShift)^0x2112)
+			response = binary.BigEndian.AppendUint32(response, binary.BigEndian.Uint32(ip[:])^0x2112A442)
+			_, _ = conn.WriteTo(response, from)
+		}
+	}()
+	return conn.LocalAddr().String()
+}
+
+func Test_Discover(t *testing.T) {
+	var first, second = serveSTUN(t, 0), serveSTUN(t, 0)
+
+	var client = &stun.Client{Servers: []string{first, second}}
+	result, err := client.Discover(context.Background())
+	require.NoError(t, err)
+	require.Len(t, result.Mappings, 2)
+	require.Equal(t, netip.MustParseAddr("127.0.0.1"), result.Mappings[0].Address.Addr())
+	require.True(t, result.EndpointIndependent)
+
+	ips, err := client.PublicIPs(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{"127.0.0.1"}, ips)
+
+	client.Servers = []string{first, serveSTUN(t, 1)}
+	result, err = client.Discover(context.Background())
+	require.NoError(t, err)
+	require.False(t, result.EndpointIndependent)
+}
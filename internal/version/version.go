@@ -0,0 +1,66 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package version provides the version of the build
+package version
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// Version, Commit and BuildDate of the build are set with -ldflags, e.g.
+// -X github.com/networkservicemesh/cmd-map-ip-k8s/internal/version.Version=v1.14.0. Commit and BuildDate default to
+// the VCS revision and time stamped by the Go toolchain.
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildDate = ""
+)
+
+// Info is the version of the build
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"buildDate"`
+	GoVersion string `json:"goVersion"`
+}
+
+// Get returns the version of the build
+func Get() Info {
+	var info = Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildDate: BuildDate,
+		GoVersion: runtime.Version(),
+	}
+	if buildInfo, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range buildInfo.Settings {
+			switch {
+			case setting.Key == "vcs.revision" && info.Commit == "":
+				info.Commit = setting.Value
+			case setting.Key == "vcs.time" && info.BuildDate == "":
+				info.BuildDate = setting.Value
+			}
+		}
+	}
+	return info
+}
+
+func (i Info) String() string {
+	return fmt.Sprintf("version: %v, commit: %v, build date: %v, go: %v", i.Version, i.Commit, i.BuildDate, i.GoVersion)
+}
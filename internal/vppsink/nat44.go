@@ -0,0 +1,183 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package vppsink provides a target programming translations as NAT44 static mappings of VPP
+package vppsink
+
+import (
+	"context"
+	"net"
+	"sort"
+
+	"github.com/pkg/errors"
+	"go.fd.io/govpp/adapter/socketclient"
+	"go.fd.io/govpp/binapi/interface_types"
+	"go.fd.io/govpp/binapi/ip_types"
+	"go.fd.io/govpp/binapi/nat44_ed"
+	"go.fd.io/govpp/binapi/nat_types"
+	"go.fd.io/govpp/core"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+)
+
+// DefaultTag is the default tag of static mappings programmed by the target
+const DefaultTag = "nsm-map-ip"
+
+// StaticMappings is the subset of the nat44_ed API used by the target
+type StaticMappings interface {
+	Nat44AddDelStaticMapping(ctx context.Context, in *nat44_ed.Nat44AddDelStaticMapping) (*nat44_ed.Nat44AddDelStaticMappingReply, error)
+}
+
+// Target programs address only NAT44 static mappings of IPv4 From addresses to their To addresses. Mappings of
+// changed and removed translations are deleted. Translations of other families are skipped.
+type Target struct {
+	// Socket is the path of the VPP API socket. It's used if API is nil.
+	Socket string
+	// API programs mappings. It's connected to Socket on the first write if it's nil.
+	API   StaticMappings
+	Tag   string
+	VrfID uint32
+
+	conn *core.Connection
+	// programmed maps local addresses of programmed mappings to their external addresses
+	programmed map[string]string
+}
+
+// Name returns the name of the target
+func (t *Target) Name() string {
+	return "vpp-nat44"
+}
+
+func (t *Target) connect() error {
+	if t.API != nil {
+		return nil
+	}
+	conn, err := core.Connect(socketclient.NewVppClient(t.Socket))
+	if err != nil {
+		return errors.Wrapf(err, "can't connect to vpp api %v", t.Socket)
+	}
+	t.conn, t.API = conn, nat44_ed.NewServiceClient(conn)
+	return nil
+}
+
+// Close disconnects from VPP if the target connected to Socket
+func (t *Target) Close() {
+	if t.conn != nil {
+		t.conn.Disconnect()
+		t.conn, t.API = nil, nil
+	}
+}
+
+func (t *Target) staticMapping(isAdd bool, local, external string) *nat44_ed.Nat44AddDelStaticMapping {
+	var tag = t.Tag
+	if tag == "" {
+		tag = DefaultTag
+	}
+	return &nat44_ed.Nat44AddDelStaticMapping{
+		IsAdd:             isAdd,
+		Flags:             nat_types.NAT_IS_ADDR_ONLY,
+		LocalIPAddress:    ip_types.NewIP4Address(net.ParseIP(local)),
+		ExternalIPAddress: ip_types.NewIP4Address(net.ParseIP(external)),
+		ExternalSwIfIndex: interface_types.InterfaceIndex(^uint32(0)),
+		VrfID:             t.VrfID,
+		Tag:               tag,
+	}
+}
+
+// Write deletes mappings of changed or removed translations and adds mappings of new or changed ones
+func (t *Target) Write(ctx context.Context, snapshot *mapipwriter.Snapshot) (bool, error) {
+	var next = ipv4Translations(snapshot)
+	deleted, added := t.changes(next)
+	if len(deleted) == 0 && len(added) == 0 {
+		return false, nil
+	}
+
+	if err := t.connect(); err != nil {
+		return false, err
+	}
+	if err := t.deleteMappings(ctx, deleted); err != nil {
+		return false, err
+	}
+	if err := t.addMappings(ctx, added, next); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ipv4Translations returns local to external addresses of IPv4 translations of the snapshot
+func ipv4Translations(snapshot *mapipwriter.Snapshot) map[string]string {
+	var translations = make(map[string]string)
+	for i := range snapshot.Entries {
+		var from, to = net.ParseIP(snapshot.Entries[i].From), net.ParseIP(snapshot.Entries[i].To)
+		if from == nil || to == nil || from.To4() == nil || to.To4() == nil {
+			continue
+		}
+		translations[from.String()] = to.String()
+	}
+	return translations
+}
+
+// changes returns sorted local addresses of programmed mappings to delete and of next mappings to add
+func (t *Target) changes(next map[string]string) (deleted, added []string) {
+	for local, external := range t.programmed {
+		if next[local] != external {
+			deleted = append(deleted, local)
+		}
+	}
+	for local, external := range next {
+		if prev, ok := t.programmed[local]; !ok || prev != external {
+			added = append(added, local)
+		}
+	}
+	sort.Strings(deleted)
+	sort.Strings(added)
+	return deleted, added
+}
+
+func (t *Target) deleteMappings(ctx context.Context, locals []string) error {
+	for _, local := range locals {
+		if err := t.call(ctx, t.staticMapping(false, local, t.programmed[local])); err != nil {
+			return err
+		}
+		delete(t.programmed, local)
+	}
+	return nil
+}
+
+func (t *Target) addMappings(ctx context.Context, locals []string, next map[string]string) error {
+	if t.programmed == nil {
+		t.programmed = make(map[string]string)
+	}
+	for _, local := range locals {
+		if err := t.call(ctx, t.staticMapping(true, local, next[local])); err != nil {
+			return err
+		}
+		t.programmed[local] = next[local]
+	}
+	return nil
+}
+
+func (t *Target) call(ctx context.Context, request *nat44_ed.Nat44AddDelStaticMapping) error {
+	if _, err := t.API.Nat44AddDelStaticMapping(ctx, request); err != nil {
+		t.Close()
+		action := "add"
+		if !request.IsAdd {
+			action = "delete"
+		}
+		return errors.Wrapf(err, "can't %v nat44 static mapping %v->%v", action, request.LocalIPAddress, request.ExternalIPAddress)
+	}
+	return nil
+}
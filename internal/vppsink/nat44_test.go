@@ -0,0 +1,86 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vppsink_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+	"go.fd.io/govpp/binapi/nat44_ed"
+	"go.fd.io/govpp/binapi/nat_types"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/vppsink"
+)
+
+type fakeNAT44 struct {
+	calls []string
+}
+
+func (f *fakeNAT44) Nat44AddDelStaticMapping(_ context.Context, in *nat44_ed.Nat44AddDelStaticMapping) (*nat44_ed.Nat44AddDelStaticMappingReply, error) {
+	if in.Flags != nat_types.NAT_IS_ADDR_ONLY || in.Tag != vppsink.DefaultTag {
+		return nil, errors.Errorf("unexpected mapping %+v", in)
+	}
+	var action = "add"
+	if !in.IsAdd {
+		action = "del"
+	}
+	f.calls = append(f.calls, fmt.Sprintf("%v %v->%v", action, in.LocalIPAddress, in.ExternalIPAddress))
+	return new(nat44_ed.Nat44AddDelStaticMappingReply), nil
+}
+
+func Test_NAT44Target(t *testing.T) {
+	var api = new(fakeNAT44)
+	var target = &vppsink.Target{API: api}
+
+	written, err := target.Write(context.Background(), &mapipwriter.Snapshot{
+		Entries: []mapipwriter.Entry{
+			{Translation: mapipwriter.Translation{From: "10.0.0.1", To: "203.0.113.1"}},
+			{Translation: mapipwriter.Translation{From: "10.0.0.2", To: "203.0.113.2"}},
+			{Translation: mapipwriter.Translation{From: "fd00::1", To: "2001:db8::1"}},
+		},
+	})
+	require.NoError(t, err)
+	require.True(t, written)
+
+	written, err = target.Write(context.Background(), &mapipwriter.Snapshot{
+		Entries: []mapipwriter.Entry{
+			{Translation: mapipwriter.Translation{From: "10.0.0.1", To: "203.0.113.3"}},
+		},
+	})
+	require.NoError(t, err)
+	require.True(t, written)
+
+	written, err = target.Write(context.Background(), &mapipwriter.Snapshot{
+		Entries: []mapipwriter.Entry{
+			{Translation: mapipwriter.Translation{From: "10.0.0.1", To: "203.0.113.3"}},
+		},
+	})
+	require.NoError(t, err)
+	require.False(t, written)
+
+	require.Equal(t, []string{
+		"add 10.0.0.1->203.0.113.1",
+		"add 10.0.0.2->203.0.113.2",
+		"del 10.0.0.1->203.0.113.1",
+		"del 10.0.0.2->203.0.113.2",
+		"add 10.0.0.1->203.0.113.3",
+	}, api.calls)
+}
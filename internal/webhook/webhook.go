@@ -0,0 +1,110 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package webhook provides a target pushing the map to an HTTP endpoint on each change
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+)
+
+// Notification is the JSON body posted to the endpoint. It contains either the whole Map or the Patch from the
+// previously pushed map.
+type Notification struct {
+	Generation uint64                       `json:"generation"`
+	Map        map[string]string            `json:"map,omitempty"`
+	Patch      []mapipwriter.PatchOperation `json:"patch,omitempty"`
+}
+
+// DefaultTimeout is the timeout of posts used if the client is not set
+const DefaultTimeout = 5 * time.Second
+
+// Target posts Notification to URL on each change of the map. Failed posts are not retried by the target, the
+// writer retries failed targets with the latest map.
+type Target struct {
+	URL string
+	// Diff posts the JSON Patch from the previously pushed map instead of the whole map
+	Diff bool
+	// Client is the client posting notifications. A client with DefaultTimeout is used if it's nil.
+	Client *http.Client
+
+	pushed     map[string]string
+	generation uint64
+}
+
+// Name returns the URL of the endpoint
+func (t *Target) Name() string {
+	return t.URL
+}
+
+// Write posts the snapshot if it's changed since the last successful post
+func (t *Target) Write(ctx context.Context, snapshot *mapipwriter.Snapshot) (bool, error) {
+	if t.pushed != nil && t.generation == snapshot.Generation {
+		return false, nil
+	}
+
+	var next = make(map[string]string, len(snapshot.Entries))
+	for i := range snapshot.Entries {
+		next[snapshot.Entries[i].From] = snapshot.Entries[i].To
+	}
+	var notification = Notification{Generation: snapshot.Generation}
+	if t.Diff {
+		notification.Patch = mapipwriter.Diff(t.pushed, next)
+	} else {
+		notification.Map = next
+	}
+	body, err := json.Marshal(&notification)
+	if err != nil {
+		return false, errors.Wrap(err, "can't marshal notification")
+	}
+
+	if err = t.post(ctx, body); err != nil {
+		return false, err
+	}
+
+	t.pushed, t.generation = next, snapshot.Generation
+	return true, nil
+}
+
+func (t *Target) post(ctx context.Context, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.URL, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrapf(err, "can't create request to %v", t.URL)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var client = t.Client
+	if client == nil {
+		client = &http.Client{Timeout: DefaultTimeout}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "can't post to %v", t.URL)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("%v responded with %v", t.URL, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/webhook"
+)
+
+func Test_WebhookTarget(t *testing.T) {
+	var mu sync.Mutex
+	var requests int
+	var received []webhook.Notification
+
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		var notification webhook.Notification
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&notification))
+		received = append(received, notification)
+	}))
+	defer server.Close()
+
+	var target = &webhook.Target{
+		URL:    server.URL,
+		Diff:   true,
+		Client: &http.Client{Timeout: time.Second},
+	}
+
+	written, err := target.Write(context.Background(), &mapipwriter.Snapshot{
+		Generation: 1,
+		Entries: []mapipwriter.Entry{
+			{Translation: mapipwriter.Translation{From: "127.0.0.1", To: "148.142.120.1"}},
+		},
+	})
+	require.Error(t, err)
+	require.False(t, written)
+
+	// the failed post is retried by the writer with the latest map
+	written, err = target.Write(context.Background(), &mapipwriter.Snapshot{
+		Generation: 1,
+		Entries: []mapipwriter.Entry{
+			{Translation: mapipwriter.Translation{From: "127.0.0.1", To: "148.142.120.1"}},
+		},
+	})
+	require.NoError(t, err)
+	require.True(t, written)
+
+	written, err = target.Write(context.Background(), &mapipwriter.Snapshot{
+		Generation: 1,
+		Entries: []mapipwriter.Entry{
+			{Translation: mapipwriter.Translation{From: "127.0.0.1", To: "148.142.120.1"}},
+		},
+	})
+	require.NoError(t, err)
+	require.False(t, written)
+
+	_, err = target.Write(context.Background(), &mapipwriter.Snapshot{Generation: 2})
+	require.NoError(t, err)
+
+	require.Equal(t, 3, requests)
+	require.Equal(t, []webhook.Notification{
+		{Generation: 1, Patch: []mapipwriter.PatchOperation{{Op: "add", Path: "/127.0.0.1", Value: "148.142.120.1"}}},
+		{Generation: 2, Patch: []mapipwriter.PatchOperation{{Op: "remove", Path: "/127.0.0.1"}}},
+	}, received)
+}
+
+func Test_WebhookTargetTimeout(t *testing.T) {
+	var done = make(chan struct{})
+	var server = httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		<-done
+	}))
+	defer server.Close()
+	defer close(done)
+
+	var target = &webhook.Target{
+		URL:    server.URL,
+		Client: &http.Client{Timeout: 50 * time.Millisecond},
+	}
+	var start = time.Now()
+	written, err := target.Write(context.Background(), &mapipwriter.Snapshot{Generation: 1})
+	require.Error(t, err)
+	require.False(t, written)
+	require.Less(t, time.Since(start), 5*time.Second)
+}
@@ -0,0 +1,167 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/iptranslation"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+)
+
+// ipTranslations selects translations of IPTranslations. The object with the highest priority is selected for each
+// From address, expired objects are skipped.
+type ipTranslations struct {
+	allNamespaces bool
+	objects       map[string]*iptranslation.IPTranslation
+	published     map[string]mapipwriter.Event
+}
+
+func newIPTranslations(conf *Config) *ipTranslations {
+	return &ipTranslations{
+		allNamespaces: conf.FromAllNamespaces,
+		objects:       make(map[string]*iptranslation.IPTranslation),
+		published:     make(map[string]mapipwriter.Event),
+	}
+}
+
+// update applies the event of the IPTranslation and returns events of changed translations
+func (s *ipTranslations) update(e watch.Event, now time.Time) []mapipwriter.Event {
+	var object = fromUnstructured[iptranslation.IPTranslation](e.Object)
+	var key = object.Namespace + "/" + object.Name
+	if e.Type == watch.Deleted {
+		delete(s.objects, key)
+	} else {
+		s.objects[key] = object
+	}
+	return s.resync(now)
+}
+
+// resync returns events replacing published translations with the translations selected at the time
+func (s *ipTranslations) resync(now time.Time) []mapipwriter.Event {
+	var selected = make(map[string]*iptranslation.IPTranslation)
+	for _, object := range s.objects {
+		if expiration := object.ExpirationTime(); !expiration.IsZero() && !now.Before(expiration) {
+			continue
+		}
+		if prev, ok := selected[object.Spec.From]; !ok || precedes(object, prev) {
+			selected[object.Spec.From] = object
+		}
+	}
+
+	var result []mapipwriter.Event
+	for from, prev := range s.published {
+		if object, ok := selected[from]; !ok || s.event(object) != prev {
+			prev.Type = watch.Deleted
+			result = append(result, prev)
+			delete(s.published, from)
+		}
+	}
+	for from, object := range selected {
+		if _, ok := s.published[from]; !ok {
+			s.published[from] = s.event(object)
+			result = append(result, s.published[from])
+		}
+	}
+	return result
+}
+
+func (s *ipTranslations) event(object *iptranslation.IPTranslation) mapipwriter.Event {
+	var result = mapipwriter.Event{
+		Type:        watch.Added,
+		Translation: mapipwriter.Translation{From: object.Spec.From, To: object.Spec.To},
+	}
+	if s.allNamespaces {
+		result.Namespace = object.Namespace
+	}
+	return result
+}
+
+// nextExpiration returns the earliest expiration time of objects after now. Zero value means none of them expires.
+func (s *ipTranslations) nextExpiration(now time.Time) time.Time {
+	var result time.Time
+	for _, object := range s.objects {
+		if expiration := object.ExpirationTime(); expiration.After(now) && (result.IsZero() || expiration.Before(result)) {
+			result = expiration
+		}
+	}
+	return result
+}
+
+// precedes returns true if the translation of a is selected instead of b. Objects of the same priority are ordered
+// by namespace and name.
+func precedes(a, b *iptranslation.IPTranslation) bool {
+	if a.Spec.Priority != b.Spec.Priority {
+		return a.Spec.Priority > b.Spec.Priority
+	}
+	if a.Namespace != b.Namespace {
+		return a.Namespace < b.Namespace
+	}
+	return a.Name < b.Name
+}
+
+// startIPTranslationSource watches IPTranslations and withdraws their translations when they expire. Events are sent
+// under the lock, so events of the informer and of expirations are not reordered.
+func startIPTranslationSource(ctx context.Context, conf *Config, f *informerFactories, eventsCh chan<- mapipwriter.Event) {
+	var s = newIPTranslations(conf)
+	var mu sync.Mutex
+	var wakeCh = make(chan struct{}, 1)
+
+	var send = func(events []mapipwriter.Event) {
+		for _, event := range events {
+			eventsCh <- event
+		}
+		select {
+		case wakeCh <- struct{}{}:
+		default:
+		}
+	}
+
+	var informer = f.customInformer(configMapNamespace(conf), iptranslation.GroupVersionResource)
+	f.run(ctx, conf, iptranslation.Resource, informer, func(e watch.Event) []mapipwriter.Event {
+		mu.Lock()
+		defer mu.Unlock()
+		send(s.update(e, time.Now()))
+		return nil
+	}, eventsCh)
+
+	go func() {
+		for {
+			mu.Lock()
+			var next = s.nextExpiration(time.Now())
+			mu.Unlock()
+
+			var expireCh <-chan time.Time
+			if !next.IsZero() {
+				expireCh = time.After(time.Until(next))
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-wakeCh:
+			case <-expireCh:
+				mu.Lock()
+				send(s.resync(time.Now()))
+				mu.Unlock()
+			}
+		}
+	}()
+}
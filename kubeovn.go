@@ -0,0 +1,65 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"net"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// withKubeOVNAddresses returns the event with IPs of the annotations added to the node as InternalIP addresses, so
+// they are translated like other internal IPs of the node. Values are comma separated IPs or CIDRs, e.g. join IPs of
+// kube-ovn "100.64.0.2,fd00:100:64::2".
+func withKubeOVNAddresses(e watch.Event, annotations []string) watch.Event {
+	var node = e.Object.(*corev1.Node)
+	var added []corev1.NodeAddress
+	for _, annotation := range annotations {
+		for _, value := range strings.Split(node.Annotations[annotation], ",") {
+			var ip = parseIPOrCIDR(strings.TrimSpace(value))
+			if ip == "" {
+				continue
+			}
+			var address = corev1.NodeAddress{Type: corev1.NodeInternalIP, Address: ip}
+			if !slices.Contains(node.Status.Addresses, address) && !slices.Contains(added, address) {
+				added = append(added, address)
+			}
+		}
+	}
+	if len(added) == 0 {
+		return e
+	}
+
+	node = node.DeepCopy()
+	node.Status.Addresses = append(node.Status.Addresses, added...)
+	e.Object = node
+	return e
+}
+
+// parseIPOrCIDR returns the IP of the value that is an IP or a CIDR. It returns an empty string otherwise.
+func parseIPOrCIDR(value string) string {
+	if ip, _, err := net.ParseCIDR(value); err == nil {
+		return ip.String()
+	}
+	if ip := net.ParseIP(value); ip != nil {
+		return ip.String()
+	}
+	return ""
+}
@@ -0,0 +1,56 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/watch"
+	corelisters "k8s.io/client-go/listers/core/v1"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/kubevirt"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+)
+
+func startKubeVirtSource(ctx context.Context, conf *Config, f *informerFactories, eventsCh chan<- mapipwriter.Event) {
+	var nodes = f.nodes()
+	var informer = f.customInformer(conf.KubeVirtNamespace, kubevirt.GroupVersionResource)
+	var translate = synchronized(newVMITranslator(ctx, conf, &cachedObjects{nodes: corelisters.NewNodeLister(nodes.GetIndexer())}))
+	f.follow(ctx, nodes, informer, externalAddressesChanged(conf), runsOn(vmiNodeName), translate, eventsCh)
+	f.run(ctx, conf, kubevirt.Resource, informer, translate, eventsCh)
+}
+
+func vmiNodeName(obj interface{}) string {
+	return fromUnstructured[kubevirt.VirtualMachineInstance](obj).Status.NodeName
+}
+
+// newVMITranslator returns a translator of VirtualMachineInstances mapping IPs of their interfaces to external
+// addresses of the hosting node. Translations follow a live migration to another node.
+func newVMITranslator(ctx context.Context, conf *Config, objects clusterObjects) func(watch.Event) []mapipwriter.Event {
+	var published publishedEntries
+	return func(e watch.Event) []mapipwriter.Event {
+		var vmi = fromUnstructured[kubevirt.VirtualMachineInstance](e.Object)
+		var events []mapipwriter.Event
+		if e.Type != watch.Deleted && vmi.Status.NodeName != "" {
+			events = translationToSameFamily(e.Type, vmi.IPs(), nodeExternalAddresses(ctx, conf, objects, vmi.Status.NodeName))
+		}
+		for i := range events {
+			events[i].Node = vmi.Status.NodeName
+		}
+		return published.update(vmi.Namespace+"/"+vmi.Name, e.Type, events)
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+)
+
+// clusterObjects gets nodes and Services translations of other objects are resolved with
+type clusterObjects interface {
+	getNode(ctx context.Context, name string) (*corev1.Node, error)
+	listNodes(ctx context.Context, selector string) ([]*corev1.Node, error)
+	getService(ctx context.Context, namespace, name string) (*corev1.Service, error)
+	listServices(ctx context.Context, namespace, selector string) ([]*corev1.Service, error)
+}
+
+// cachedObjects gets nodes and Services from caches of informers, so translations don't call the API. Listers of
+// objects which aren't needed may be nil.
+type cachedObjects struct {
+	nodes    corelisters.NodeLister
+	services corelisters.ServiceLister
+}
+
+func (o *cachedObjects) getNode(_ context.Context, name string) (*corev1.Node, error) {
+	return o.nodes.Get(name)
+}
+
+func (o *cachedObjects) listNodes(_ context.Context, selector string) ([]*corev1.Node, error) {
+	parsed, err := labels.Parse(selector)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't parse selector %v", selector)
+	}
+	return o.nodes.List(parsed)
+}
+
+func (o *cachedObjects) getService(_ context.Context, namespace, name string) (*corev1.Service, error) {
+	return o.services.Services(namespace).Get(name)
+}
+
+func (o *cachedObjects) listServices(_ context.Context, namespace, selector string) ([]*corev1.Service, error) {
+	parsed, err := labels.Parse(selector)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't parse selector %v", selector)
+	}
+	return o.services.Services(namespace).List(parsed)
+}
+
+// clientObjects gets nodes and Services from the API for sources listed once. Nodes are remembered, so a node is got
+// once per list of all objects of sources.
+type clientObjects struct {
+	client kubernetes.Interface
+	mu     sync.Mutex
+	nodes  map[string]*corev1.Node
+}
+
+func newClientObjects(c kubernetes.Interface) *clientObjects {
+	return &clientObjects{client: c, nodes: make(map[string]*corev1.Node)}
+}
+
+func (o *clientObjects) getNode(ctx context.Context, name string) (*corev1.Node, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if node, ok := o.nodes[name]; ok {
+		return node, nil
+	}
+	node, err := o.client.CoreV1().Nodes().Get(ctx, name, v1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	o.nodes[name] = node
+	return node, nil
+}
+
+func (o *clientObjects) listNodes(ctx context.Context, selector string) ([]*corev1.Node, error) {
+	list, err := o.client.CoreV1().Nodes().List(ctx, v1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+	var result []*corev1.Node
+	for i := range list.Items {
+		result = append(result, &list.Items[i])
+	}
+	return result, nil
+}
+
+func (o *clientObjects) getService(ctx context.Context, namespace, name string) (*corev1.Service, error) {
+	return o.client.CoreV1().Services(namespace).Get(ctx, name, v1.GetOptions{})
+}
+
+func (o *clientObjects) listServices(ctx context.Context, namespace, selector string) ([]*corev1.Service, error) {
+	list, err := o.client.CoreV1().Services(namespace).List(ctx, v1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+	var result []*corev1.Service
+	for i := range list.Items {
+		result = append(result, &list.Items[i])
+	}
+	return result, nil
+}
+
+// externalAddressesChanged returns the function reporting whether external addresses of the updated node are changed
+func externalAddressesChanged(conf *Config) func(oldObj, newObj interface{}) bool {
+	return func(oldObj, newObj interface{}) bool {
+		return !slices.Equal(externalAddresses(oldObj.(*corev1.Node), conf.ExternalIPAnnotation),
+			externalAddresses(newObj.(*corev1.Node), conf.ExternalIPAnnotation))
+	}
+}
+
+// internalIPsChanged reports whether internal IPs or labels NodeSelector matches of the updated node are changed
+func internalIPsChanged(oldObj, newObj interface{}) bool {
+	var oldNode, newNode = oldObj.(*corev1.Node), newObj.(*corev1.Node)
+	return !slices.Equal(internalIPs(oldNode), internalIPs(newNode)) || !maps.Equal(oldNode.Labels, newNode.Labels)
+}
+
+// runsOn returns the function reporting whether the object runs on the node by name of its node
+func runsOn(nodeName func(obj interface{}) string) func(node, obj interface{}) bool {
+	return func(node, obj interface{}) bool {
+		return nodeName(obj) == node.(*corev1.Node).Name
+	}
+}
+
+// internalIPs returns internal IPs of the node
+func internalIPs(node *corev1.Node) []string {
+	var result []string
+	for _, address := range node.Status.Addresses {
+		if address.Type == corev1.NodeInternalIP {
+			result = append(result, address.Address)
+		}
+	}
+	return result
+}
+
+// resourceChanged reports whether the updated object is changed, i.e. it's not a resync
+func resourceChanged(oldObj, newObj interface{}) bool {
+	return oldObj.(v1.Object).GetResourceVersion() != newObj.(v1.Object).GetResourceVersion()
+}
+
+// anyObject matches every object
+func anyObject(_, _ interface{}) bool {
+	return true
+}
@@ -0,0 +1,65 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/clusterapi"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+)
+
+// startMachineSource lists and watches Machines of Cluster API. MachineSets carry no addresses, so only Machines are
+// watched.
+func startMachineSource(ctx context.Context, conf *Config, f *informerFactories, eventsCh chan<- mapipwriter.Event) {
+	var informer = f.customInformer(conf.ClusterAPINamespace, clusterapi.GroupVersionResource)
+	f.run(ctx, conf, clusterapi.Resource, informer, newMachineTranslator(conf), eventsCh)
+}
+
+// newMachineTranslator returns a translator of Machines. Addresses of a Machine are translated as addresses of its
+// node, so mappings are available before the node registers. Translations are withdrawn when addresses of the Machine
+// change or it's deleted.
+func newMachineTranslator(conf *Config) func(watch.Event) []mapipwriter.Event {
+	var published publishedEntries
+	return func(e watch.Event) []mapipwriter.Event {
+		var machine = fromUnstructured[clusterapi.Machine](e.Object)
+		var events []mapipwriter.Event
+		if e.Type != watch.Deleted {
+			events = translationFromNode(watch.Event{Type: e.Type, Object: machineNode(machine)}, "", conf.NodeAllExternalIPs)
+		}
+		for i := range events {
+			events[i].Node = machine.NodeName()
+		}
+		return published.update(machine.Namespace+"/"+machine.Name, e.Type, events)
+	}
+}
+
+// machineNode returns a node with addresses of the Machine
+func machineNode(machine *clusterapi.Machine) *corev1.Node {
+	var node = &corev1.Node{ObjectMeta: v1.ObjectMeta{Name: machine.NodeName()}}
+	for _, address := range machine.Status.Addresses {
+		node.Status.Addresses = append(node.Status.Addresses, corev1.NodeAddress{
+			Type:    corev1.NodeAddressType(address.Type),
+			Address: address.Address,
+		})
+	}
+	return node
+}
@@ -21,26 +21,62 @@
 package main
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
+	"fmt"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	nested "github.com/antonfisher/nested-logrus-formatter"
 	"github.com/kelseyhightower/envconfig"
+	"github.com/pkg/errors"
 	"gopkg.in/yaml.v2"
 
 	"github.com/sirupsen/logrus"
+	"github.com/spf13/pflag"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
 
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/consulsink"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/ddns"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/dnsserver"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/ebpfsink"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/eds"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/etcdsink"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/eventqueue"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/grpcserver"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/health"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/k8ssink"
 	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/metrics"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/natssink"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/queryapi"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/redissink"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/remap"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/restapi"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/version"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/vppsink"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/webhook"
 	"github.com/networkservicemesh/sdk/pkg/tools/log"
 	"github.com/networkservicemesh/sdk/pkg/tools/log/logruslogger"
 	"github.com/networkservicemesh/sdk/pkg/tools/opentelemetry"
@@ -49,15 +85,156 @@ import (
 
 // Config represents the configuration for cmd-map-ip-k8s application
 type Config struct {
-	OutputPath            string        `default:"external_ips.yaml" desc:"Path to writing map of internal to extenrnal ips" split_words:"true"`
+	ConfigFile            string        `default:"" desc:"Path of a YAML file of environment variables of the config overriding the environment, e.g. NSM_FROM_CONFIG_MAP: nsm. The app is restarted with the changed config on changes of the file. Empty value disables it" split_words:"true"`
+	OutputPath            string        `default:"external_ips.yaml" desc:"Comma separated paths to writing map of internal to extenrnal ips" split_words:"true"`
 	NodeName              string        `default:"" desc:"The name of node where application is running" split_words:"true"`
 	LogLevel              string        `default:"INFO" desc:"Log level" split_words:"true"`
 	Namespace             string        `default:"default" desc:"Namespace where is mapip running" split_words:"true"`
-	FromConfigMap         string        `default:"" desc:"If it's not empty then gets entries from the configmap" split_words:"true"`
+	FromConfigMap         string        `default:"" desc:"Comma separated list of configmaps entries are merged from. Empty value disables it" split_words:"true"`
 	OpenTelemetryEndpoint string        `default:"otel-collector.observability.svc.cluster.local:4317" desc:"OpenTelemetry Collector Endpoint" split_words:"true"`
 	MetricsExportInterval time.Duration `default:"10s" desc:"interval between mertics exports" split_words:"true"`
 	PprofEnabled          bool          `default:"false" desc:"is pprof enabled" split_words:"true"`
 	PprofListenOn         string        `default:"localhost:6060" desc:"pprof URL to ListenAndServe" split_words:"true"`
+	MetricsTopologyLabels bool          `default:"false" desc:"If it's true then labels metrics by node topology zone and region" split_words:"true"`
+	ToCIDRRemap           string        `default:"" desc:"Comma separated list of fromCIDR=toCIDR rules applied to the To addresses" split_words:"true"`
+	ExtendedOutput        bool          `default:"false" desc:"If it's true then each entry contains the To address, the original address before remapping and the remote cluster" split_words:"true"`
+	SkipUnchangedWrites   bool          `default:"true" desc:"If it's true then skips writing of the output file when the digest of its content is not changed, e.g. on duplicate events of relists" split_words:"true"`
+	VerifyAfterWrite      bool          `default:"false" desc:"If it's true then re-reads the output file after writing and rewrites it on mismatch" split_words:"true"`
+	FollowSymlinks        bool          `default:"false" desc:"If it's true and the output path is a symlink then writes into the linked file preserving the link" split_words:"true"`
+	WriteGeneration       bool          `default:"false" desc:"If it's true then writes the generation of the map into a companion file with .generation suffix" split_words:"true"`
+	PostWriteCommand      string        `default:"" desc:"Shell command executed after each successful write of the output file" split_words:"true"`
+	PostWriteTimeout      time.Duration `default:"10s" desc:"Timeout of the post-write command" split_words:"true"`
+	CleanupTempFiles      bool          `default:"true" desc:"If it's true then removes temporary files of the output left by previous runs on start. Files modified within the last minute are kept as they may be written by another instance" split_words:"true"`
+	OutputHeader          bool          `default:"false" desc:"If it's true then prepends the output file with comments containing the generation of the map, the write timestamp and the node name" split_words:"true"`
+	WritePatch            bool          `default:"false" desc:"If it's true then writes the JSON Patch of the last change of the map into a companion file with .patch.json suffix" split_words:"true"`
+	HMACKey               string        `default:"" desc:"If it's not empty then signs the output file with HMAC-SHA256 written into a companion file with .hmac suffix" split_words:"true"`
+	HMACKeyFile           string        `default:"" desc:"Path to the file with the HMAC key, e.g. a mounted secret. It's used if HMACKey is empty" split_words:"true"`
+	OutputKeepVersions    int           `default:"0" desc:"Count of previous versions of the output file kept with .1, .2, ... suffixes" split_words:"true"`
+	OutputGzip            bool          `default:"false" desc:"If it's true then writes the output compressed with gzip into the output path with .gz suffix" split_words:"true"`
+	KeepUncompressed      bool          `default:"false" desc:"If it's true and the output is compressed then writes the uncompressed output as well" split_words:"true"`
+	OutputFileMode        string        `default:"0777" desc:"Octal permission bits of the output file" split_words:"true"`
+	OutputDirMode         string        `default:"0777" desc:"Octal permission bits of directories created for the output file" split_words:"true"`
+	OutputOwner           string        `default:"" desc:"Numeric owner of the output file in form of uid or uid:gid. Empty value keeps the owner of the process" split_words:"true"`
+	FsyncWrites           bool          `default:"false" desc:"If it's true then flushes the output file and its directory to the storage on each write" split_words:"true"`
+	WriteDebounce         time.Duration `default:"0" desc:"Window of coalescing bursts of events into a single write of the output. Zero value disables debouncing" split_words:"true"`
+	WriteMaxLatency       time.Duration `default:"1s" desc:"Max delay of the write since the first event of a burst when debouncing is enabled" split_words:"true"`
+	WriteRateLimit        float64       `default:"0" desc:"Max rate of writes of the output per second caused by events. Writes over the limit are coalesced into a delayed write of the latest map. Zero value disables the limit" split_words:"true"`
+	WriteBurst            int           `default:"1" desc:"Count of writes allowed over the rate limit at once" split_words:"true"`
+	EventQueueSize        int           `default:"64" desc:"Size of the queue of events waiting for the writer, sources are blocked while it's full. Zero value means an unbounded queue coalescing pending events of the same translation" split_words:"true"`
+	FlushTimeout          time.Duration `default:"5s" desc:"Timeout of the final write of pending events on shutdown. Zero value disables the final write" split_words:"true"`
+	OutputOnShutdown      string        `default:"keep" desc:"Action with output files on shutdown: keep, truncate or stale. The stale action writes a companion file with .stale suffix removed on the next write. It is not applied on restarts caused by changes of the config file" split_words:"true"`
+	OutputMultipleTo      bool          `default:"false" desc:"If it's true then the yaml output format maps each From address to the list of all its To addresses" split_words:"true"`
+	NodeAllExternalIPs    bool          `default:"false" desc:"If it's true then internal IPs of a node are mapped to all external IPs of the node instead of the first one" split_words:"true"`
+	NodePodCIDRs          bool          `default:"false" desc:"If it's true then pod CIDRs of nodes are mapped to the address the node is mapped to" split_words:"true"`
+	OutputOrder           string        `default:"sorted" desc:"Order of entries in the output: sorted by addresses or insertion" split_words:"true"`
+	WriteRetryInterval    time.Duration `default:"5s" desc:"Interval of retrying writes into outputs that failed" split_words:"true"`
+	PodIP                 string        `default:"" desc:"If it's not empty then maps the pod IP to the node address. Expected to be injected from status.podIP" envconfig:"POD_IP"`
+	ExternalIPAnnotation  string        `default:"nsm.io/external-ip" desc:"Node annotation with comma separated IPs overriding external IPs of the node. Empty value disables overriding" split_words:"true"`
+	ConfigMapOnly         bool          `default:"false" desc:"If it's true then publishes only entries from the configmap and ignores nodes" split_words:"true"`
+	FromConfigMapSelector string        `envconfig:"FROM_CONFIGMAP_SELECTOR" default:"" desc:"Label selector of configmaps entries are merged from, e.g. app=nsm-ipmap. Configmaps created after the start are picked up" split_words:"true"`
+	FromAllNamespaces     bool          `default:"false" desc:"If it's true then input configmaps are watched in all namespaces and entries are tagged with their namespace" split_words:"true"`
+	ConfigMapAdditive     bool          `default:"false" desc:"If it's true then entries removed from the configmap are kept in the map" split_words:"true"`
+	DNSListenOn           string        `default:"" desc:"UDP address of the DNS responder answering queries from the map. Empty value disables it" split_words:"true"`
+	DNSZone               string        `default:"" desc:"Optional domain suffix of names served by the DNS responder and written in the coredns output format" split_words:"true"`
+	ExitOnForbidden       bool          `default:"false" desc:"If it's true then exits when the apiserver forbids watching nodes or configmaps" split_words:"true"`
+	ReconcileInterval     time.Duration `default:"0" desc:"Interval of listing all sources from scratch and removing entries of the map which no longer correspond to any source. Zero value disables it" split_words:"true"`
+	InformerResync        time.Duration `default:"10m" desc:"Interval informers deliver all cached objects to sources again with. Zero value disables it" split_words:"true"`
+	OutputFormat          string        `default:"yaml" desc:"Format of the output file: yaml, hosts, coredns, protobuf, env, nftables or ipset" split_words:"true"`
+	OutputTemplate        string        `default:"" desc:"Go template of the output rendered against the map. It overrides the output format if it's not empty" split_words:"true"`
+	EnvPrefix             string        `default:"IP_" desc:"Prefix of variable names of the env output format" split_words:"true"`
+	HostsColumns          string        `default:"to,from" desc:"Comma separated columns of the hosts output format: to, from, original. Columns after the first one are names, colons of IPv6 addresses are replaced with dashes" split_words:"true"`
+	ToConfigMap           string        `default:"" desc:"If it's not empty then also writes the map into the configmap with this name" split_words:"true"`
+	ToConfigMapNamespace  string        `default:"" desc:"Namespace of the configmap the map is written into. Namespace is used if it's empty" split_words:"true"`
+	ToConfigMapKey        string        `default:"external_ips.yaml" desc:"Key of the configmap the map is written into" split_words:"true"`
+	ReverseEntries        bool          `default:"false" desc:"If it's true then the coredns output format also resolves From addresses by To addresses" split_words:"true"`
+	OneShot               bool          `default:"false" desc:"If it's true then writes the map once and exits" split_words:"true"`
+	OneShotStdout         bool          `default:"true" desc:"If it's true then the one-shot mode writes the map into stdout instead of the output paths" split_words:"true"`
+	EtcdEndpoint          string        `default:"" desc:"URL of etcd the translations are published into, e.g. http://etcd:2379. Empty value disables it" split_words:"true"`
+	EtcdPrefix            string        `default:"/nsm/map-ip/" desc:"Prefix of etcd keys of the translations" split_words:"true"`
+	EtcdLeaseTTL          time.Duration `default:"0" desc:"TTL of the etcd lease the keys are attached to. Zero value disables the lease" split_words:"true"`
+	ConsulAddress         string        `default:"" desc:"URL of the Consul agent the translations are published into, e.g. http://consul:8500. Empty value disables it" split_words:"true"`
+	ConsulPrefix          string        `default:"nsm/map-ip/" desc:"Prefix of Consul KV keys of the translations" split_words:"true"`
+	ConsulToken           string        `default:"" desc:"ACL token of Consul" split_words:"true"`
+	RedisAddress          string        `default:"" desc:"host:port of Redis the map is written into. Empty value disables it" split_words:"true"`
+	RedisPassword         string        `default:"" desc:"Password of Redis" split_words:"true"`
+	RedisKey              string        `default:"nsm:map-ip" desc:"Key of the Redis hash of the map" split_words:"true"`
+	RedisChannel          string        `default:"nsm:map-ip:changes" desc:"Redis channel changes of the map are published on. Empty value disables publishing" split_words:"true"`
+	NotifyURL             string        `default:"" desc:"URL the map is posted to as JSON on each change. Empty value disables it" split_words:"true"`
+	NotifyDiff            bool          `default:"false" desc:"If it's true then posts the JSON Patch from the previously posted map instead of the whole map" split_words:"true"`
+	NotifyTimeout         time.Duration `default:"5s" desc:"Timeout of each post of a change. Failed posts are retried every write retry interval" split_words:"true"`
+	ToSecret              string        `default:"" desc:"If it's not empty then also writes the map into the secret with this name using server-side apply" split_words:"true"`
+	ToSecretNamespace     string        `default:"" desc:"Namespace of the secret the map is written into. Namespace is used if it's empty" split_words:"true"`
+	ToSecretKey           string        `default:"external_ips.yaml" desc:"Key of the secret the map is written into" split_words:"true"`
+	ToNodeAnnotation      string        `default:"" desc:"If it's not empty then writes translations of the current node into this annotation of the node" split_words:"true"`
+	ToIPMap               string        `default:"" desc:"If it's not empty then the map is applied into the cluster-scoped IPMap object with this name" split_words:"true"`
+	NatsURL               string        `default:"" desc:"nats://[user:password@]host[:port] of NATS changes of the map are published to. Empty value disables it" split_words:"true"`
+	NatsToken             string        `default:"" desc:"Authentication token of NATS" split_words:"true"`
+	NatsSubject           string        `default:"nsm.map-ip" desc:"NATS subject changes of the map are published on" split_words:"true"`
+	GRPCListenOn          string        `default:"" desc:"unix:///path or tcp://host:port of the gRPC MapIP service. Empty value disables it" split_words:"true"`
+	HTTPListenOn          string        `default:"" desc:"TCP address of the REST API serving the map on /mappings. Empty value disables it" split_words:"true"`
+	HealthListenOn        string        `default:"" desc:"TCP address of liveness and readiness probes on /healthz and /readyz. It's ready once the initial lists of all sources are loaded. Empty value disables it" split_words:"true"`
+	QuerySocket           string        `default:"" desc:"Path of the unix socket of the query API resolving single ips. Relative path is resolved against the directory of the output file. Empty value disables it" split_words:"true"`
+	EDSListenOn           string        `default:"" desc:"unix:///path or tcp://host:port of the Envoy endpoint discovery service. Empty value disables it" split_words:"true"`
+	EDSEndpointPort       uint32        `default:"443" desc:"Port of endpoints served by the Envoy endpoint discovery service" split_words:"true"`
+	ToDNSEndpoint         string        `default:"" desc:"If it's not empty then A/AAAA records of nodes are applied into the external-dns DNSEndpoint with this name" split_words:"true"`
+	DNSEndpointHostname   string        `default:"{{.Node}}" desc:"Template of hostnames of nodes in the DNSEndpoint, e.g. {{.Node}}.nodes.example.com" split_words:"true"`
+	DNSEndpointTTL        int64         `default:"0" desc:"TTL of records in the DNSEndpoint. Zero value means the default TTL of external-dns" split_words:"true"`
+	DDNSServer            string        `default:"" desc:"host:port of the primary DNS server receiving RFC 2136 updates of addresses of nodes. Empty value disables it" split_words:"true"`
+	DDNSZone              string        `default:"" desc:"Zone updated by dynamic DNS updates" split_words:"true"`
+	DDNSHostname          string        `default:"{{.Node}}" desc:"Template of hostnames of nodes in the zone" split_words:"true"`
+	DDNSRecordTTL         uint32        `default:"60" desc:"TTL of records added by dynamic DNS updates" split_words:"true"`
+	DDNSKeyName           string        `default:"" desc:"Name of the TSIG key signing dynamic DNS updates. Empty value disables signing" split_words:"true"`
+	DDNSKeyAlgorithm      string        `default:"hmac-sha256" desc:"Algorithm of the TSIG key: hmac-sha1, hmac-sha256 or hmac-sha512" split_words:"true"`
+	DDNSKeySecret         string        `default:"" desc:"Base64 encoded secret of the TSIG key" split_words:"true"`
+	NftTable              string        `default:"nsm_map_ip" desc:"inet table of ip4_map and ip6_map maps of the nftables output format" split_words:"true"`
+	IpsetName             string        `default:"nsm-map-ip" desc:"Prefix of names of sets of the ipset output format" split_words:"true"`
+	VPPNat44Socket        string        `default:"" desc:"Path of the VPP API socket NAT44 static mappings of translations are programmed into. Empty value disables it" split_words:"true"`
+	VPPNat44VrfID         uint32        `default:"0" desc:"VRF of NAT44 static mappings" split_words:"true"`
+	VPPNat44Tag           string        `default:"nsm-map-ip" desc:"Tag of NAT44 static mappings" split_words:"true"`
+	EBPFPinPath           string        `default:"" desc:"bpffs path of the pinned eBPF hash map translations are written into, e.g. /sys/fs/bpf/nsm/map_ip. Empty value disables it" split_words:"true"`
+	EBPFMaxEntries        uint32        `default:"65536" desc:"Max entries of the eBPF hash map created by the app" split_words:"true"`
+	HostsFile             string        `default:"" desc:"Path of a hosts file, e.g. /etc/hosts, a managed block mapping hostnames of nodes to their addresses is maintained in. Empty value disables it" split_words:"true"`
+	HostsFileHostname     string        `default:"{{.Node}}" desc:"Template of whitespace separated hostname and aliases of nodes in the hosts file" split_words:"true"`
+	HostsFileMarker       string        `default:"nsm-map-ip" desc:"Marker of BEGIN and END lines of the managed block of the hosts file" split_words:"true"`
+	FromLoadBalancers     bool          `default:"false" desc:"If it's true then ClusterIPs of Services of type LoadBalancer are mapped to their load balancer ingress addresses" split_words:"true"`
+	LoadBalancerNamespace string        `default:"" desc:"Namespace of watched Services of type LoadBalancer. Empty value means all namespaces" split_words:"true"`
+	LoadBalancerNodePorts bool          `default:"false" desc:"If it's true then internal IPs of nodes are mapped to ingress addresses of Services with NodePorts as well" split_words:"true"`
+	EndpointSliceServices string        `default:"" desc:"Comma separated list of namespace/name of Services addresses of endpoints of which are mapped to external IPs of their nodes. Name without namespace refers to Namespace" split_words:"true"`
+	HostNetworkPods       string        `default:"" desc:"Label selector of Pods with host network IPs of which are mapped to external IPs of their nodes. Empty value disables it" split_words:"true"`
+	HostNetworkNamespace  string        `default:"" desc:"Namespace of watched Pods with host network. Empty value means all namespaces" split_words:"true"`
+	NodeSelector          string        `default:"" desc:"Label selector of nodes included in the map, e.g. nsm.io/enabled=true. Empty value means all nodes" split_words:"true"`
+	NodeOwnOnly           bool          `default:"false" desc:"If it's true then only the node of NodeName is watched with a field selector instead of all nodes, e.g. in DaemonSet deployments" split_words:"true"`
+	CloudMetadata         string        `default:"" desc:"Cloud metadata service public IPs of the node of the app are queried from if the node has no external IP: aws, gcp, azure, openstack, hetzner, digitalocean or equinix. Empty value disables it" split_words:"true"`
+	CloudMetadataURL      string        `default:"" desc:"Base URL of the cloud metadata service. Empty value means the default endpoint of the provider" split_words:"true"`
+	CloudMetadataOverride bool          `default:"false" desc:"If it's true then public IPs from the cloud metadata service replace external IPs of the node status" split_words:"true"`
+	StunServers           string        `default:"" desc:"Comma separated host:port of STUN servers public IPs of the node of the app are discovered with if there is no cloud metadata. Empty value disables it" split_words:"true"`
+	GatewayProtocol       string        `default:"" desc:"Protocol the WAN address of the gateway is discovered with if there is no cloud metadata: natpmp or upnp. Empty value disables it" split_words:"true"`
+	GatewayAddress        string        `default:"" desc:"Address of the NAT-PMP gateway or the SSDP address of UPnP discovery. Empty value means the default gateway or 239.255.255.250:1900" split_words:"true"`
+	GatewayPortMappings   string        `default:"" desc:"Comma separated protocol:port the gateway is asked to map to the same port of the node, e.g. udp:51820,tcp:443" split_words:"true"`
+	StaticMappings        string        `default:"" desc:"Comma separated from=to translations added to the map. They take precedence over translations of other sources with the same from address" split_words:"true"`
+	FromFiles             string        `default:"" desc:"Comma separated paths of YAML files with from: to entries merged into the map. Files are re-read on changes" split_words:"true"`
+	FromIPTranslations    bool          `default:"false" desc:"If it's true then translations of IPTranslation custom resources of the namespace are added to the map" split_words:"true"`
+	FromIngresses         bool          `default:"false" desc:"If it's true then ClusterIPs of backend Services of Ingresses are mapped to their load balancer addresses" split_words:"true"`
+	IngressNamespace      string        `default:"" desc:"Namespace of watched Ingresses. Empty value means all namespaces" split_words:"true"`
+	IngressNodePorts      bool          `default:"false" desc:"If it's true then internal IPs of nodes are mapped to addresses of Ingresses with backend Services with NodePorts as well" split_words:"true"`
+	FromMetalLB           bool          `default:"false" desc:"If it's true then internal IPs of nodes announcing Services in the MetalLB layer 2 mode are mapped to the load balancer IPs of the Services" split_words:"true"`
+	MetalLBNamespace      string        `default:"metallb-system" desc:"Namespace MetalLB is installed into" split_words:"true"`
+	FromGatewayAPI        bool          `default:"false" desc:"If it's true then ClusterIPs of Services of Gateways of the Gateway API are mapped to addresses of the Gateways" split_words:"true"`
+	GatewayAPINamespace   string        `default:"" desc:"Namespace of watched Gateways of the Gateway API. Empty value means all namespaces" split_words:"true"`
+	Kubeconfig            string        `default:"" desc:"Path of the kubeconfig of the cluster. If it's empty then the in-cluster config is used, or the standard KUBECONFIG and ~/.kube/config when it's run outside of a cluster"`
+	RemoteKubeconfigs     string        `default:"" desc:"Directory of kubeconfigs of remote clusters nodes are watched in as well. The file name without the extension identifies the cluster" split_words:"true"`
+	NodeResolveDNS        bool          `default:"false" desc:"If it's true then ExternalDNS and InternalDNS addresses of nodes without IPs of the type are resolved into IPs" split_words:"true"`
+	NodeDNSRefresh        time.Duration `default:"5m" desc:"Interval of resolving DNS addresses of nodes again. Zero value disables it" split_words:"true"`
+	FromClusterAPI        bool          `default:"false" desc:"If it's true then addresses of Machines of Cluster API are mapped as addresses of nodes before the nodes register" split_words:"true"`
+	ClusterAPINamespace   string        `default:"" desc:"Namespace of watched Machines of Cluster API. Empty value means all namespaces" split_words:"true"`
+	FromKubeVirt          bool          `default:"false" desc:"If it's true then IPs of interfaces of KubeVirt VirtualMachineInstances are mapped to external IPs of their nodes" split_words:"true"`
+	KubeVirtNamespace     string        `default:"" desc:"Namespace of watched VirtualMachineInstances. Empty value means all namespaces" split_words:"true"`
+	FromCiliumNodes       bool          `default:"false" desc:"If it's true then InternalIP and ExternalIP addresses of CiliumNodes are mapped as addresses of nodes" split_words:"true"`
+	NodeCalicoAddresses   bool          `default:"false" desc:"If it's true then projectcalico.org/IPv4Address and IPv6Address annotations are used as internal IPs of nodes without internal IPs of the family" split_words:"true"`
+	NodeKubeOVNAddresses  bool          `default:"false" desc:"If it's true then IPs of KubeOVNAnnotations of nodes are mapped as internal IPs of the nodes" split_words:"true"`
+	KubeOVNAnnotations    string        `default:"ovn.kubernetes.io/ip_address" desc:"Comma separated annotations of nodes with kube-ovn IPs, e.g. the join IP" split_words:"true"`
+	MultusPods            string        `default:"" desc:"Label selector of Pods. IPs of secondary networks from their Multus network-status annotation are mapped to external IPs of their nodes. Empty value disables it" split_words:"true"`
+	MultusNamespace       string        `default:"" desc:"Namespace of watched Pods with Multus networks. Empty value means all namespaces" split_words:"true"`
 }
 
 func main() {
@@ -83,16 +260,14 @@ func main() {
 	ctx = log.WithLog(ctx, logruslogger.New(ctx, map[string]interface{}{"cmd": os.Args[:1]}))
 
 	logger := log.FromContext(ctx)
+	logger.Infof("build %v", version.Get().String())
 
 	// ********************************************************************************
 	// Get config from environment
 	// ********************************************************************************
-	conf := &Config{}
-	if err := envconfig.Usage("nsm", conf); err != nil {
-		logger.Fatal(err)
-	}
-	if err := envconfig.Process("nsm", conf); err != nil {
-		logger.Fatalf("error processing rootConf from env: %+v", err)
+	conf := loadConfig(logger, os.Args[1:])
+	if conf == nil {
+		return
 	}
 
 	level, err := logrus.ParseLevel(conf.LogLevel)
@@ -130,16 +305,54 @@ func main() {
 	// ********************************************************************************
 	// Create client-go
 	// ********************************************************************************
-	kubeConfig, err := rest.InClusterConfig()
+	kubeConfig, err := NewRestConfig(conf)
 	if err != nil {
-		logger.Fatalf("can't get Kubernetes config. Are you running this app inside Kubernetes pod")
+		logger.Fatalf("can't get Kubernetes config: %v", err.Error())
 	}
 	c, err := kubernetes.NewForConfig(kubeConfig)
 	if err != nil {
 		logger.Fatal(err.Error())
 	}
+	d, err := dynamic.NewForConfig(kubeConfig)
+	if err != nil {
+		logger.Fatal(err.Error())
+	}
 
-	<-Start(ctx, conf, c)
+	if conf.OneShot {
+		if err = RunOnce(ctx, conf, c, d, os.Stdout); err != nil {
+			logger.Fatal(err.Error())
+		}
+		return
+	}
+
+	if conf.ConfigFile != "" {
+		<-StartWithReload(ctx, conf, c, d, os.Args[1:])
+		return
+	}
+	<-Start(ctx, conf, c, d)
+}
+
+// loadConfig loads the config from args and environment and prints its usage. It returns nil if the application
+// should exit after printing the help or the version.
+func loadConfig(logger log.Logger, args []string) *Config {
+	conf, err := LoadConfig(args)
+	if errors.Is(err, pflag.ErrHelp) {
+		return nil
+	}
+	if errors.Is(err, ErrVersion) {
+		fmt.Println(version.Get().String())
+		return nil
+	}
+	if err != nil {
+		logger.Fatalf("error processing rootConf: %+v", err)
+	}
+	// usage is printed into stdout, so it's skipped when stdout is the output of the one-shot mode
+	if !conf.OneShot || !conf.OneShotStdout {
+		if err = envconfig.Usage(envPrefix, conf); err != nil {
+			logger.Fatal(err)
+		}
+	}
+	return conf
 }
 
 func getPublicIP(ctx context.Context) string {
@@ -162,130 +375,919 @@ func getPublicIP(ctx context.Context) string {
 	return ""
 }
 
-// Start starts main application
-func Start(ctx context.Context, conf *Config, c kubernetes.Interface) <-chan struct{} {
-	logger := log.FromContext(ctx)
+// NewRestConfig returns the config of the client of the cluster of Kubeconfig. The in-cluster config is used if it's
+// empty, or the standard KUBECONFIG and ~/.kube/config when the app is run outside of a cluster.
+func NewRestConfig(conf *Config) (*rest.Config, error) {
+	if conf.Kubeconfig != "" {
+		kubeConfig, err := clientcmd.BuildConfigFromFlags("", conf.Kubeconfig)
+		return kubeConfig, errors.Wrapf(err, "can't load kubeconfig %v", conf.Kubeconfig)
+	}
+	kubeConfig, err := rest.InClusterConfig()
+	if !errors.Is(err, rest.ErrNotInCluster) {
+		return kubeConfig, errors.Wrap(err, "can't load in-cluster config")
+	}
+	kubeConfig, err = clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		clientcmd.NewDefaultClientConfigLoadingRules(), &clientcmd.ConfigOverrides{}).ClientConfig()
+	return kubeConfig, errors.Wrap(err, "can't load kubeconfig outside of a cluster")
+}
 
-	var mapWriter = mapipwriter.MapIPWriter{
-		OutputPath: conf.OutputPath,
+// Start starts main application. Custom resources of sources and targets are listed, watched and applied with d, it
+// may be nil if none of them is enabled. The returned channel is closed when ctx is done, targets are closed and
+// servers have closed their listeners.
+func Start(ctx context.Context, conf *Config, c kubernetes.Interface, d dynamic.Interface) <-chan struct{} {
+	return start(ctx, conf, c, d, false)
+}
+
+// start starts main application. If it's restarted then the map isn't written until the initial lists of sources are
+// loaded, so the map written before the restart is kept meanwhile. The returned channel is closed when targets are
+// closed and servers have closed their listeners, so the restarted app can listen on the same addresses.
+func start(ctx context.Context, conf *Config, c kubernetes.Interface, d dynamic.Interface, restarted bool) <-chan struct{} {
+	var servers sync.WaitGroup
+	var mapWriter = newMapWriter(ctx, conf, c, d, &servers)
+	var ready chan struct{}
+	if restarted {
+		ready = make(chan struct{})
+		mapWriter.Ready = ready
 	}
+	eventsCh, done := startWriter(ctx, conf, mapWriter)
 
-	list, err := c.CoreV1().Nodes().List(ctx, v1.ListOptions{})
-	if err != nil {
-		logger.Fatal(err.Error())
+	var factories = newInformerFactories(conf, c, d)
+	if conf.StaticMappings != "" {
+		eventsCh = startStaticMappings(ctx, conf, eventsCh)
 	}
+	var translateNode = startSources(ctx, conf, factories, eventsCh)
 
-	var eventsCh = make(chan mapipwriter.Event, 64)
+	if conf.GatewayProtocol != "" && conf.GatewayPortMappings != "" {
+		startPortMappings(ctx, conf)
+	}
+	if conf.ReconcileInterval > 0 {
+		startReconcile(ctx, conf, c, d, translateNode, mapWriter)
+	}
+	if conf.HealthListenOn != "" {
+		startHealthServer(ctx, conf, factories, &servers)
+	}
+	if restarted {
+		go func() {
+			if factories.waitForSync(ctx) {
+				close(ready)
+			}
+		}()
+	}
+
+	var result = make(chan struct{})
+	go func() {
+		defer close(result)
+		<-done
+		servers.Wait()
+	}()
+	return result
+}
+
+// startWriter starts the writer of the map. It returns the channel of events of the map and the channel closed when
+// ctx is done and targets are closed.
+func startWriter(ctx context.Context, conf *Config, mapWriter *mapipwriter.MapIPWriter) (chan<- mapipwriter.Event, <-chan struct{}) {
+	var writerCh = make(chan mapipwriter.Event, max(conf.EventQueueSize, 0))
 
-	if conf.FromConfigMap != "" {
-		cm, err := c.CoreV1().ConfigMaps(conf.Namespace).Get(ctx, conf.FromConfigMap, v1.GetOptions{})
-		if err == nil {
-			for _, event := range translateFromConfigmap(watch.Event{
-				Type:   watch.Added,
-				Object: cm,
-			}) {
-				eventsCh <- event
+	var done = make(chan struct{})
+	var eventsCh chan<- mapipwriter.Event = writerCh
+	var queueDepth = func() int { return len(writerCh) }
+	if conf.EventQueueSize <= 0 {
+		var queue = eventqueue.Start(ctx, writerCh, done)
+		eventsCh = queue.In()
+		queueDepth = queue.Len
+		mapWriter.EventsClosedOnShutdown = true
+	}
+
+	go func() {
+		defer close(done)
+		defer metrics.ObserveFloat64(ctx, metrics.EventQueueDepthName, "count of events waiting for the writer", func() float64 {
+			return float64(queueDepth())
+		})()
+		mapWriter.Start(ctx, writerCh)
+	}()
+	return eventsCh, done
+}
+
+// source starts watching objects of a source
+type source struct {
+	enabled bool
+	start   func()
+}
+
+// startSources starts watching the enabled sources. It returns the translator of nodes if nodes are watched.
+func startSources(ctx context.Context, conf *Config, factories *informerFactories,
+	eventsCh chan<- mapipwriter.Event) func(watch.Event) []mapipwriter.Event {
+	var translateNode func(watch.Event) []mapipwriter.Event
+	var sources = []source{
+		{conf.FromConfigMap != "" || conf.FromConfigMapSelector != "", func() {
+			startConfigMapSource(ctx, conf, factories, eventsCh)
+		}},
+		{!conf.ConfigMapOnly, func() {
+			translateNode = startNodeSource(ctx, conf, factories, eventsCh)
+		}},
+		{conf.FromFiles != "", func() {
+			startFileSource(ctx, conf, eventsCh)
+		}},
+		{conf.FromIPTranslations, func() {
+			startIPTranslationSource(ctx, conf, factories, eventsCh)
+		}},
+		{conf.FromLoadBalancers, func() {
+			startServiceSource(ctx, conf, factories, eventsCh)
+		}},
+		{conf.FromIngresses, func() {
+			startIngressSource(ctx, conf, factories, eventsCh)
+		}},
+		{conf.FromMetalLB, func() {
+			startMetalLBSource(ctx, conf, factories, eventsCh)
+		}},
+		{conf.FromGatewayAPI, func() {
+			startGatewayAPISource(ctx, conf, factories, eventsCh)
+		}},
+		{conf.FromClusterAPI, func() {
+			startMachineSource(ctx, conf, factories, eventsCh)
+		}},
+		{conf.FromKubeVirt, func() {
+			startKubeVirtSource(ctx, conf, factories, eventsCh)
+		}},
+		{conf.FromCiliumNodes, func() {
+			startCiliumNodeSource(ctx, conf, factories, eventsCh)
+		}},
+		{conf.RemoteKubeconfigs != "", func() {
+			clusters, err := loadRemoteClusters(conf)
+			if err != nil {
+				log.FromContext(ctx).Fatal(err.Error())
 			}
+			startRemoteNodeSources(ctx, conf, clusters, eventsCh)
+		}},
+		{conf.EndpointSliceServices != "", func() {
+			startEndpointSliceSource(ctx, conf, factories, eventsCh)
+		}},
+		{conf.HostNetworkPods != "", func() {
+			startHostNetworkPodSource(ctx, conf, factories, eventsCh)
+		}},
+		{conf.MultusPods != "", func() {
+			startMultusPodSource(ctx, conf, factories, eventsCh)
+		}},
+	}
+	for _, s := range sources {
+		if s.enabled {
+			s.start()
 		}
 	}
+	return translateNode
+}
 
-	for i := 0; i < len(list.Items); i++ {
-		for _, event := range translationFromNode(watch.Event{
-			Type:   watch.Added,
-			Object: &list.Items[i],
-		}) {
-			eventsCh <- event
+func newMapWriter(ctx context.Context, conf *Config, c kubernetes.Interface, d dynamic.Interface, servers *sync.WaitGroup) *mapipwriter.MapIPWriter {
+	render, err := newRenderer(conf)
+	if err != nil {
+		log.FromContext(ctx).Fatal(err.Error())
+	}
+
+	var mapWriter = &mapipwriter.MapIPWriter{
+		OutputPath:       conf.OutputPath,
+		PostWriteCommand: conf.PostWriteCommand,
+		PostWriteTimeout: conf.PostWriteTimeout,
+		CleanupTempFiles: conf.CleanupTempFiles,
+		RetryInterval:    conf.WriteRetryInterval,
+		WriteDebounce:    conf.WriteDebounce,
+		WriteMaxLatency:  conf.WriteMaxLatency,
+		WriteRateLimit:   conf.WriteRateLimit,
+		WriteBurst:       conf.WriteBurst,
+		FlushTimeout:     conf.FlushTimeout,
+		Order:            conf.OutputOrder,
+	}
+
+	fileTargets, err := newFileTargets(conf, render)
+	if err != nil {
+		log.FromContext(ctx).Fatal(err.Error())
+	}
+	mapWriter.Targets = append(mapWriter.Targets, fileTargets...)
+
+	if conf.ToCIDRRemap != "" {
+		if mapWriter.TransformTo, err = newTransformTo(conf); err != nil {
+			log.FromContext(ctx).Fatal(err.Error())
 		}
 	}
 
-	go mapWriter.Start(ctx, eventsCh)
+	mapWriter.Targets = append(mapWriter.Targets, newK8sTargets(ctx, conf, c, d, render)...)
+	mapWriter.Targets = append(mapWriter.Targets, newExternalTargets(ctx, conf)...)
+	mapWriter.Targets = append(mapWriter.Targets, startServers(ctx, conf, servers)...)
+
+	return mapWriter
+}
 
-	go monitorEvents(ctx, eventsCh, func() watch.Interface {
-		r, _ := c.CoreV1().Nodes().Watch(ctx, v1.ListOptions{})
-		return r
-	}, func(e watch.Event) []mapipwriter.Event {
-		var result = translationFromNode(e)
-		var podEvent = translationFromPodToNode(ctx, e, conf.NodeName)
+// newExternalTargets creates targets writing the map into external services
+func newExternalTargets(ctx context.Context, conf *Config) []mapipwriter.Target {
+	var targets []mapipwriter.Target
 
-		if podEvent != nil {
-			result = append(result, *podEvent)
+	if conf.EtcdEndpoint != "" {
+		var etcd = &etcdsink.Target{
+			Endpoint: conf.EtcdEndpoint,
+			Prefix:   conf.EtcdPrefix,
+			LeaseTTL: conf.EtcdLeaseTTL,
 		}
+		targets = append(targets, etcd)
+		go etcd.KeepAlive(ctx)
+	}
 
-		return result
-	})
+	if conf.ConsulAddress != "" {
+		targets = append(targets, &consulsink.Target{
+			Address: conf.ConsulAddress,
+			Prefix:  conf.ConsulPrefix,
+			Token:   conf.ConsulToken,
+		})
+	}
+
+	if conf.RedisAddress != "" {
+		targets = append(targets, &redissink.Target{
+			Address:  conf.RedisAddress,
+			Password: conf.RedisPassword,
+			Key:      conf.RedisKey,
+			Channel:  conf.RedisChannel,
+		})
+	}
+
+	if conf.NatsURL != "" {
+		targets = append(targets, &natssink.Target{
+			URL:     conf.NatsURL,
+			Token:   conf.NatsToken,
+			Subject: conf.NatsSubject,
+		})
+	}
+
+	if conf.DDNSServer != "" {
+		ddnsTarget, err := newDDNSTarget(conf)
+		if err != nil {
+			log.FromContext(ctx).Fatal(err.Error())
+		}
+		targets = append(targets, ddnsTarget)
+	}
+
+	if conf.VPPNat44Socket != "" {
+		targets = append(targets, &vppsink.Target{
+			Socket: conf.VPPNat44Socket,
+			Tag:    conf.VPPNat44Tag,
+			VrfID:  conf.VPPNat44VrfID,
+		})
+	}
+
+	if conf.EBPFPinPath != "" {
+		targets = append(targets, &ebpfsink.Target{
+			PinPath:    conf.EBPFPinPath,
+			MaxEntries: conf.EBPFMaxEntries,
+		})
+	}
+
+	if conf.NotifyURL != "" {
+		targets = append(targets, &webhook.Target{
+			URL:    conf.NotifyURL,
+			Diff:   conf.NotifyDiff,
+			Client: &http.Client{Timeout: conf.NotifyTimeout},
+		})
+	}
+
+	return targets
+}
+
+// serve runs listenAndServe in the background and fails the app on its error. It's added to servers until it returns,
+// so its listener is closed by then.
+func serve(ctx context.Context, servers *sync.WaitGroup, listenAndServe func() error) {
+	servers.Add(1)
+	go func() {
+		defer servers.Done()
+		if serveErr := listenAndServe(); serveErr != nil {
+			log.FromContext(ctx).Fatal(serveErr.Error())
+		}
+	}()
+}
+
+// startServers starts servers answering queries from the map and returns them as targets of the map. The servers are
+// added to servers until they close their listeners.
+func startServers(ctx context.Context, conf *Config, servers *sync.WaitGroup) []mapipwriter.Target {
+	var targets []mapipwriter.Target
+
+	if conf.DNSListenOn != "" {
+		var dns = &dnsserver.Server{Zone: conf.DNSZone}
+		targets = append(targets, dns)
+		serve(ctx, servers, func() error { return dns.ListenAndServe(ctx, conf.DNSListenOn) })
+	}
+
+	if conf.GRPCListenOn != "" {
+		var grpcServer = new(grpcserver.Server)
+		targets = append(targets, grpcServer)
+		serve(ctx, servers, func() error { return grpcServer.ListenAndServe(ctx, conf.GRPCListenOn) })
+	}
+
+	if conf.EDSListenOn != "" {
+		var edsServer = &eds.Server{Port: conf.EDSEndpointPort}
+		targets = append(targets, edsServer)
+		serve(ctx, servers, func() error { return edsServer.ListenAndServe(ctx, conf.EDSListenOn) })
+	}
+
+	if conf.QuerySocket != "" {
+		var socket = conf.QuerySocket
+		if !filepath.IsAbs(socket) {
+			socket = filepath.Join(filepath.Dir(strings.Split(conf.OutputPath, ",")[0]), socket)
+		}
+		var query = new(queryapi.Server)
+		targets = append(targets, query)
+		serve(ctx, servers, func() error { return query.ListenAndServe(ctx, socket) })
+	}
 
-	if conf.FromConfigMap != "" {
-		go monitorEvents(ctx, eventsCh, func() watch.Interface {
-			r, _ := c.CoreV1().ConfigMaps(conf.FromConfigMap).Watch(ctx, v1.ListOptions{FieldSelector: "meta.name=" + conf.FromConfigMap})
-			return r
-		}, translateFromConfigmap)
+	if conf.HTTPListenOn != "" {
+		var api = new(restapi.Server)
+		targets = append(targets, api)
+		serve(ctx, servers, func() error { return api.ListenAndServe(ctx, conf.HTTPListenOn) })
 	}
-	return ctx.Done()
+
+	return targets
 }
 
-func monitorEvents(ctx context.Context, out chan<- mapipwriter.Event, getWatchFn func() watch.Interface, translateFn func(watch.Event) []mapipwriter.Event) {
-	w := getWatchFn()
-	defer func() {
-		if w != nil {
-			w.Stop()
+// startHealthServer serves probes which are ready once the initial lists of sources are loaded. Lists failed on start,
+// e.g. during apiserver restarts, are retried with backoff and the probe is not ready until they succeed.
+func startHealthServer(ctx context.Context, conf *Config, f *informerFactories, servers *sync.WaitGroup) {
+	var probes = new(health.Server)
+	serve(ctx, servers, func() error { return probes.ListenAndServe(ctx, conf.HealthListenOn) })
+	go func() {
+		if f.waitForSync(ctx) {
+			log.FromContext(ctx).Info("initial lists of sources are loaded")
+			probes.SetReady()
 		}
 	}()
+}
+
+// newK8sTargets creates targets writing the map into objects of the cluster
+func newK8sTargets(ctx context.Context, conf *Config, c kubernetes.Interface, d dynamic.Interface, render mapipwriter.Renderer) []mapipwriter.Target {
+	var targets []mapipwriter.Target
+
+	if conf.ToConfigMap != "" {
+		var namespace = conf.ToConfigMapNamespace
+		if namespace == "" {
+			namespace = conf.Namespace
+		}
+		targets = append(targets, &k8ssink.ConfigMap{
+			Client:        c,
+			Namespace:     namespace,
+			ConfigMapName: conf.ToConfigMap,
+			Key:           conf.ToConfigMapKey,
+			Render:        render,
+		})
+	}
+
+	if conf.ToSecret != "" {
+		var namespace = conf.ToSecretNamespace
+		if namespace == "" {
+			namespace = conf.Namespace
+		}
+		targets = append(targets, &k8ssink.Secret{
+			Client:     c,
+			Namespace:  namespace,
+			SecretName: conf.ToSecret,
+			Key:        conf.ToSecretKey,
+			Labels:     k8ssink.ManagedByLabels,
+			Render:     render,
+		})
+	}
+
+	if conf.ToNodeAnnotation != "" && conf.NodeName != "" {
+		targets = append(targets, &k8ssink.NodeAnnotation{
+			Client:     c,
+			NodeName:   conf.NodeName,
+			Annotation: conf.ToNodeAnnotation,
+		})
+	}
+
+	if conf.ToDNSEndpoint != "" {
+		hostname, err := mapipwriter.ParseHostnameTemplate(conf.DNSEndpointHostname)
+		if err != nil {
+			log.FromContext(ctx).Fatal(err.Error())
+		}
+		targets = append(targets, &k8ssink.DNSEndpoint{
+			Client:     d,
+			Namespace:  conf.Namespace,
+			ObjectName: conf.ToDNSEndpoint,
+			Labels:     k8ssink.ManagedByLabels,
+			Hostname:   hostname,
+			TTL:        conf.DNSEndpointTTL,
+		})
+	}
+
+	if conf.ToIPMap != "" {
+		targets = append(targets, &k8ssink.IPMap{
+			Client:     d,
+			ObjectName: conf.ToIPMap,
+			Labels:     k8ssink.ManagedByLabels,
+		})
+	}
 
-	for ctx.Err() == nil {
-		if w == nil {
-			log.FromContext(ctx).Errorf("cant supply watcher")
-			time.Sleep(time.Second / 2)
-			w = getWatchFn()
+	return targets
+}
+
+func newDDNSTarget(conf *Config) (*ddns.Target, error) {
+	hostname, err := mapipwriter.ParseHostnameTemplate(conf.DDNSHostname)
+	if err != nil {
+		return nil, err
+	}
+	var target = &ddns.Target{
+		Server:   conf.DDNSServer,
+		Zone:     conf.DDNSZone,
+		Hostname: hostname,
+		TTL:      conf.DDNSRecordTTL,
+	}
+	if conf.DDNSKeyName != "" {
+		secret, decodeErr := base64.StdEncoding.DecodeString(conf.DDNSKeySecret)
+		if decodeErr != nil {
+			return nil, errors.Wrap(decodeErr, "can't decode secret of the tsig key")
+		}
+		target.TSIG = &ddns.TSIG{KeyName: conf.DDNSKeyName, Algorithm: conf.DDNSKeyAlgorithm, Secret: secret}
+		if err = target.TSIG.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	return target, nil
+}
+
+func newTransformTo(conf *Config) (func(string) string, error) {
+	r, err := remap.Parse(conf.ToCIDRRemap)
+	if err != nil {
+		return nil, err
+	}
+	return r.Apply, nil
+}
+
+func newFileTargets(conf *Config, render mapipwriter.Renderer) ([]mapipwriter.Target, error) {
+	mode, err := parseFileMode(conf.OutputFileMode)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid output file mode")
+	}
+	dirMode, err := parseFileMode(conf.OutputDirMode)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid output directory mode")
+	}
+	switch conf.OutputOnShutdown {
+	case "", mapipwriter.OnCloseKeep, mapipwriter.OnCloseTruncate, mapipwriter.OnCloseStale:
+	default:
+		return nil, errors.Errorf("unknown output action on shutdown %q", conf.OutputOnShutdown)
+	}
+	var owner *mapipwriter.FileOwner
+	if conf.OutputOwner != "" {
+		if owner, err = mapipwriter.ParseFileOwner(conf.OutputOwner); err != nil {
+			return nil, err
+		}
+	}
+
+	hmacKey, err := loadHMACKey(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []mapipwriter.Target
+	for _, path := range strings.Split(conf.OutputPath, ",") {
+		if path = strings.TrimSpace(path); path == "" {
 			continue
 		}
+		var target = &mapipwriter.FileTarget{
+			Path:             path,
+			Render:           render,
+			Extended:         conf.ExtendedOutput,
+			SkipUnchanged:    conf.SkipUnchangedWrites,
+			VerifyAfterWrite: conf.VerifyAfterWrite,
+			FollowSymlinks:   conf.FollowSymlinks,
+			WriteGeneration:  conf.WriteGeneration,
+			Header:           conf.OutputHeader,
+			NodeName:         conf.NodeName,
+			Mode:             mode,
+			DirMode:          dirMode,
+			Owner:            owner,
+			Fsync:            conf.FsyncWrites,
+			KeepVersions:     conf.OutputKeepVersions,
+			WritePatch:       conf.WritePatch,
+			HMACKey:          hmacKey,
+			OnClose:          conf.OutputOnShutdown,
+		}
+		if !conf.OutputGzip {
+			result = append(result, target)
+			continue
+		}
+		if conf.KeepUncompressed {
+			var uncompressed = *target
+			result = append(result, &uncompressed)
+		}
+		target.Path += mapipwriter.GzipSuffix
+		target.Gzip = true
+		result = append(result, target)
+	}
+
+	if conf.HostsFile != "" {
+		hostname, parseErr := mapipwriter.ParseHostnameTemplate(conf.HostsFileHostname)
+		if parseErr != nil {
+			return nil, parseErr
+		}
+		result = append(result, &mapipwriter.HostsBlockTarget{
+			Path:     conf.HostsFile,
+			Marker:   conf.HostsFileMarker,
+			Hostname: hostname,
+		})
+	}
+	return result, nil
+}
+
+func loadHMACKey(conf *Config) ([]byte, error) {
+	if conf.HMACKey != "" || conf.HMACKeyFile == "" {
+		return []byte(conf.HMACKey), nil
+	}
+	// #nosec G304
+	key, err := os.ReadFile(conf.HMACKeyFile)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't read HMAC key")
+	}
+	return bytes.TrimSpace(key), nil
+}
+
+// parseFileMode parses octal permission bits. Empty value is parsed as zero mode meaning the default one.
+func parseFileMode(s string) (os.FileMode, error) {
+	if s == "" {
+		return 0, nil
+	}
+	mode, err := strconv.ParseUint(s, 8, 32)
+	if err != nil {
+		return 0, errors.Wrapf(err, "can't parse %q", s)
+	}
+	return os.FileMode(mode) & os.ModePerm, nil
+}
+
+func newRenderer(conf *Config) (mapipwriter.Renderer, error) {
+	if conf.OutputTemplate != "" {
+		return mapipwriter.NewTemplateRenderer(conf.OutputTemplate)
+	}
+	switch strings.ToLower(conf.OutputFormat) {
+	case "", "yaml":
+		if conf.OutputMultipleTo {
+			return mapipwriter.YAMLListRenderer(conf.ExtendedOutput), nil
+		}
+		return mapipwriter.YAMLRenderer(conf.ExtendedOutput), nil
+	case "hosts":
+		return mapipwriter.NewHostsRenderer(conf.HostsColumns)
+	case "env":
+		return mapipwriter.NewEnvFileRenderer(conf.EnvPrefix), nil
+	case "protobuf":
+		return mapipwriter.ProtobufRenderer, nil
+	case "coredns":
+		return mapipwriter.NewCoreDNSHostsRenderer(conf.DNSZone, conf.ReverseEntries), nil
+	case "nftables":
+		return mapipwriter.NewNftRenderer(conf.NftTable), nil
+	case "ipset":
+		return mapipwriter.NewIpsetRenderer(conf.IpsetName), nil
+	default:
+		return nil, errors.Errorf("unknown output format %q", conf.OutputFormat)
+	}
+}
+
+// startNodeSource watches nodes and returns their translator without DNS resolution
+func startNodeSource(ctx context.Context, conf *Config, f *informerFactories, eventsCh chan<- mapipwriter.Event) func(watch.Event) []mapipwriter.Event {
+	var cloudIPs = discoverPublicIPs(ctx, conf)
+	var resolver = newNodeResolver(conf)
+	var translateNode = newNodeTranslator(ctx, conf, cloudIPs)
+	var translate = func(e watch.Event) []mapipwriter.Event {
+		return append(translateNode(e), translationFromPodToNode(ctx, withCloudAddresses(e, conf, cloudIPs), conf.NodeName, conf.PodIP, conf.ExternalIPAnnotation)...)
+	}
+
+	if conf.NodeOwnOnly && conf.NodeName == "" {
+		log.FromContext(ctx).Fatal("NSM_NODE_NAME is required to watch only the own node")
+	}
+	var informer = f.get(v1.NamespaceAll, nodeListOptions(conf)).Core().V1().Nodes().Informer()
+	translate = withPublished(nodeKey, withResolver(ctx, resolver, translate))
+	f.run(ctx, conf, "nodes", informer, translate, eventsCh)
+	startNodeDNSRefresh(ctx, conf, informer.GetStore(), resolver, translate, eventsCh)
+	return translate
+}
+
+func nodeKey(object runtime.Object) string {
+	return object.(*corev1.Node).Name
+}
 
-		select {
-		case e, ok := <-w.ResultChan():
-			if !ok {
-				w.Stop()
-				w = getWatchFn()
-				continue
+// newNodeTranslator returns a translator of nodes. cloudIPs are used as external addresses of the node the app runs
+// on if it has no external addresses. Calico and kube-ovn annotations are used as internal addresses if they are
+// enabled.
+func newNodeTranslator(ctx context.Context, conf *Config, cloudIPs []string) func(watch.Event) []mapipwriter.Event {
+	var nodeCounter metrics.NodeCounter
+	return func(e watch.Event) []mapipwriter.Event {
+		e = withCloudAddresses(e, conf, cloudIPs)
+		if conf.NodeCalicoAddresses {
+			e = withCalicoAddresses(e)
+		}
+		if conf.NodeKubeOVNAddresses {
+			e = withKubeOVNAddresses(e, splitList(conf.KubeOVNAnnotations))
+		}
+		var node = e.Object.(*corev1.Node)
+		var zone, region string
+		if conf.MetricsTopologyLabels {
+			zone, region = nodeTopology(node)
+		}
+		nodeCounter.Update(ctx, node.Name, e.Type == watch.Deleted, metrics.TopologyAttributes(zone, region)...)
+		if _, invalid := overrideAddresses(node, conf.ExternalIPAnnotation); len(invalid) > 0 && e.Type != watch.Deleted {
+			log.FromContext(ctx).Warnf("node %v: annotation %v contains invalid IPs %v, they are ignored", node.Name, conf.ExternalIPAnnotation, invalid)
+		}
+
+		var result = translationFromNode(e, conf.ExternalIPAnnotation, conf.NodeAllExternalIPs)
+		if conf.NodePodCIDRs {
+			result = append(result, translationFromNodePodCIDRs(e, result)...)
+		}
+		for i := range result {
+			result[i].Zone, result[i].Region = zone, region
+			result[i].Node = node.Name
+		}
+		return result
+	}
+}
+
+// nodeListOptions returns options of nodes of the cluster the app runs in. If NodeOwnOnly is set then only the node
+// of NodeName is selected with a field selector.
+func nodeListOptions(conf *Config) v1.ListOptions {
+	var options = v1.ListOptions{LabelSelector: conf.NodeSelector}
+	if conf.NodeOwnOnly {
+		options.FieldSelector = "metadata.name=" + conf.NodeName
+	}
+	return options
+}
+
+func listNodes(ctx context.Context, c kubernetes.Interface, options v1.ListOptions, translate func(watch.Event) []mapipwriter.Event) ([]mapipwriter.Event, error) {
+	list, err := c.CoreV1().Nodes().List(ctx, options)
+	if err != nil {
+		return nil, errors.Wrap(err, "can't list nodes")
+	}
+
+	var result []mapipwriter.Event
+	for i := 0; i < len(list.Items); i++ {
+		result = append(result, translate(watch.Event{
+			Type:   watch.Added,
+			Object: &list.Items[i],
+		})...)
+	}
+	return result, nil
+}
+
+func startConfigMapSource(ctx context.Context, conf *Config, f *informerFactories, eventsCh chan<- mapipwriter.Event) {
+	var translate = newConfigMapTranslator(ctx, conf)
+
+	for _, name := range configMapNames(conf) {
+		var name = name
+		var factory = f.get(configMapNamespace(conf), v1.ListOptions{FieldSelector: "metadata.name=" + name})
+		f.run(ctx, conf, "configmaps", factory.Core().V1().ConfigMaps().Informer(), func(e watch.Event) []mapipwriter.Event {
+			if e.Object.(*corev1.ConfigMap).Name != name {
+				return nil
 			}
-			events := translateFn(e)
-			for _, event := range events {
-				out <- event
+			return translate(e)
+		}, eventsCh)
+	}
+
+	if conf.FromConfigMapSelector != "" {
+		var factory = f.get(configMapNamespace(conf), v1.ListOptions{LabelSelector: conf.FromConfigMapSelector})
+		f.run(ctx, conf, "configmaps", factory.Core().V1().ConfigMaps().Informer(), translate, eventsCh)
+	}
+}
+
+// listConfigMaps returns events of the entries of the configmaps matching FromConfigMapSelector
+func listConfigMaps(ctx context.Context, conf *Config, c kubernetes.Interface, translate func(watch.Event) []mapipwriter.Event) ([]mapipwriter.Event, error) {
+	list, err := c.CoreV1().ConfigMaps(configMapNamespace(conf)).List(ctx, v1.ListOptions{LabelSelector: conf.FromConfigMapSelector})
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't list configmaps %v", conf.FromConfigMapSelector)
+	}
+
+	var result []mapipwriter.Event
+	for i := 0; i < len(list.Items); i++ {
+		result = append(result, translate(watch.Event{
+			Type:   watch.Added,
+			Object: &list.Items[i],
+		})...)
+	}
+	return result, nil
+}
+
+// configMapNamespace returns the namespace of input configmaps and IPTranslations. Empty value means all namespaces.
+func configMapNamespace(conf *Config) string {
+	if conf.FromAllNamespaces {
+		return v1.NamespaceAll
+	}
+	return conf.Namespace
+}
+
+// configMapNames returns names of the configmaps entries are merged from
+func configMapNames(conf *Config) []string {
+	return splitList(conf.FromConfigMap)
+}
+
+// splitList returns distinct non-empty items of the comma separated list
+func splitList(list string) []string {
+	var result []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" && !slices.Contains(result, item) {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// newConfigMapTranslator returns a translator of configmaps shared by watchers of all configmaps
+func newConfigMapTranslator(ctx context.Context, conf *Config) func(watch.Event) []mapipwriter.Event {
+	var published publishedEntries
+	var mu sync.Mutex
+	var invalidValues = metrics.Int64Counter(ctx, metrics.InvalidConfigMapValuesName,
+		metric.WithDescription("count of configmap values that can't be used as a map of ips"),
+	)
+	return func(e watch.Event) []mapipwriter.Event {
+		var events = translateFromConfigmap(ctx, e, invalidValues)
+		var cm = e.Object.(*corev1.ConfigMap)
+		if conf.FromAllNamespaces {
+			for i := range events {
+				events[i].Namespace = cm.Namespace
 			}
-		case <-ctx.Done():
-			return
 		}
+		if conf.ConfigMapAdditive {
+			return events
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		return published.update(cm.Namespace+"/"+cm.Name, e.Type, events)
+	}
+}
+
+// getConfigMap returns events of the entries of the configmap. It returns no events if the configmap doesn't exist.
+// If FromAllNamespaces is set then configmaps with the name of all namespaces are merged.
+func getConfigMap(ctx context.Context, conf *Config, c kubernetes.Interface, name string, translate func(watch.Event) []mapipwriter.Event) ([]mapipwriter.Event, error) {
+	if conf.FromAllNamespaces {
+		list, err := c.CoreV1().ConfigMaps(v1.NamespaceAll).List(ctx, v1.ListOptions{FieldSelector: "metadata.name=" + name})
+		if err != nil {
+			return nil, errors.Wrapf(err, "can't list configmaps %v", name)
+		}
+		var result []mapipwriter.Event
+		for i := 0; i < len(list.Items); i++ {
+			if list.Items[i].Name == name {
+				result = append(result, translate(watch.Event{Type: watch.Added, Object: &list.Items[i]})...)
+			}
+		}
+		return result, nil
+	}
+
+	cm, err := c.CoreV1().ConfigMaps(conf.Namespace).Get(ctx, name, v1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't get configmap %v", name)
 	}
+	return translate(watch.Event{
+		Type:   watch.Added,
+		Object: cm,
+	}), nil
 }
 
-func translateFromConfigmap(e watch.Event) []mapipwriter.Event {
+// withPublished returns the translator withdrawing translations the object of the event published before, but are
+// missed in its current translation, e.g. when addresses of a node are changed. Objects are identified by key. It's
+// safe for concurrent use.
+func withPublished(key func(runtime.Object) string, translate func(watch.Event) []mapipwriter.Event) func(watch.Event) []mapipwriter.Event {
+	var published publishedEntries
+	var mu sync.Mutex
+	return func(e watch.Event) []mapipwriter.Event {
+		var events = translate(e)
+		mu.Lock()
+		defer mu.Unlock()
+		return published.update(key(e.Object), e.Type, events)
+	}
+}
+
+// publishedEntries remembers translations published from each object of a source to withdraw the ones removed on
+// update
+type publishedEntries struct {
+	entries map[string]map[mapipwriter.Translation]struct{}
+}
+
+// update returns events with Deleted events appended for translations that were published from the object with the
+// key before, but are missed in the current events. Translations still published from other objects are kept, so
+// objects can contribute the same translation.
+func (p *publishedEntries) update(key string, eventType watch.EventType, events []mapipwriter.Event) []mapipwriter.Event {
+	if p.entries == nil {
+		p.entries = make(map[string]map[mapipwriter.Translation]struct{})
+	}
+
+	var next = make(map[mapipwriter.Translation]struct{})
+	if eventType != watch.Deleted {
+		for i := range events {
+			next[events[i].Translation] = struct{}{}
+		}
+	} else {
+		events = slices.DeleteFunc(events, func(event mapipwriter.Event) bool {
+			return p.publishedByOthers(key, event.Translation)
+		})
+	}
+
+	for translation := range p.entries[key] {
+		if _, ok := next[translation]; !ok && !p.publishedByOthers(key, translation) {
+			events = append(events, mapipwriter.Event{
+				Type:        watch.Deleted,
+				Translation: translation,
+			})
+		}
+	}
+
+	if eventType == watch.Deleted {
+		delete(p.entries, key)
+	} else {
+		p.entries[key] = next
+	}
+
+	return events
+}
+
+func (p *publishedEntries) publishedByOthers(key string, translation mapipwriter.Translation) bool {
+	for other, translations := range p.entries {
+		if _, ok := translations[translation]; ok && other != key {
+			return true
+		}
+	}
+	return false
+}
+
+func logWatchError(ctx context.Context, resource string, err error, exitOnForbidden bool) {
+	var logger = log.FromContext(ctx)
+	var logFn = logger.Errorf
+	if exitOnForbidden {
+		logFn = logger.Fatalf
+	}
+
+	switch {
+	case apierrors.IsResourceExpired(err) || apierrors.IsGone(err):
+		logger.Debugf("resource version of the watch of %v is too old, it's resumed after a list: %v", resource, err.Error())
+	case apierrors.IsForbidden(err):
+		logFn("forbidden to watch %v: %v. Make sure the service account has RBAC permissions to list and watch %v", resource, err.Error(), resource)
+	case apierrors.IsUnauthorized(err):
+		logFn("unauthorized to watch %v: %v. Make sure the service account token is mounted and valid", resource, err.Error())
+	case err != nil:
+		logger.Errorf("can't watch %v: %v", resource, err.Error())
+	default:
+		logger.Errorf("cant supply watcher")
+	}
+}
+
+func translateFromConfigmap(ctx context.Context, e watch.Event, invalidValues metric.Int64Counter) []mapipwriter.Event {
 	var res []mapipwriter.Event
 	var c = e.Object.(*corev1.ConfigMap)
 
-	for _, v := range c.Data {
+	for k, v := range c.Data {
 		var m map[string]string
-		if err := yaml.Unmarshal([]byte(v), &m); err == nil {
-			for from, to := range m {
-				res = append(res, mapipwriter.Event{
-					Type: e.Type,
-					Translation: mapipwriter.Translation{
-						From: from,
-						To:   to,
-					},
-				})
-			}
+		if err := yaml.Unmarshal([]byte(v), &m); err != nil {
+			reportInvalidConfigMapValue(ctx, invalidValues, c.Name, k, v, err)
+			continue
+		}
+		for from, to := range m {
+			res = append(res, mapipwriter.Event{
+				Type: e.Type,
+				Translation: mapipwriter.Translation{
+					From: from,
+					To:   to,
+				},
+			})
 		}
 	}
 
 	return res
 }
 
-func translationFromPodToNode(ctx context.Context, e watch.Event, currentNodeName string) *mapipwriter.Event {
+func reportInvalidConfigMapValue(ctx context.Context, invalidValues metric.Int64Counter, name, key, value string, err error) {
+	invalidValues.Add(ctx, 1, metric.WithAttributes(attribute.String("configmap", name), attribute.String("key", key)))
+
+	var raw interface{}
+	if yaml.Unmarshal([]byte(value), &raw) != nil {
+		log.FromContext(ctx).Warnf("configmap %v: key %q is not a valid YAML, entries are skipped: %v", name, key, err.Error())
+		return
+	}
+	log.FromContext(ctx).Warnf("configmap %v: key %q contains %v, but a map of ip to ip is expected, entries are skipped", name, key, yamlShape(raw))
+}
+
+func yamlShape(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "an empty value"
+	case []interface{}:
+		return "a list"
+	case map[interface{}]interface{}:
+		return "a map with non-scalar values"
+	case string:
+		return "a string"
+	default:
+		return fmt.Sprintf("a scalar of type %T", v)
+	}
+}
+
+func translationFromPodToNode(ctx context.Context, e watch.Event, currentNodeName, podIP, overrideAnnotation string) []mapipwriter.Event {
 	var node = e.Object.(*corev1.Node)
 
 	if node.Name != currentNodeName || e.Type == watch.Deleted {
 		return nil
 	}
 
-	var result = &mapipwriter.Event{
+	var result = mapipwriter.Event{
 		Type: watch.Added,
 		Translation: mapipwriter.Translation{
 			From: getPublicIP(ctx),
@@ -301,52 +1303,157 @@ func translationFromPodToNode(ctx context.Context, e watch.Event, currentNodeNam
 			result.To = node.Status.Addresses[i].Address
 		}
 	}
+	if overrides, _ := overrideAddresses(node, overrideAnnotation); len(overrides) > 0 {
+		result.To = overrides[0]
+	}
+
+	if podIP == "" {
+		return []mapipwriter.Event{result}
+	}
+
+	// map the pod's own IP to the node address as well
+	var podEvent = result
+	podEvent.From = podIP
+
+	return []mapipwriter.Event{result, podEvent}
+}
+
+func nodeTopology(node *corev1.Node) (zone, region string) {
+	zone = node.Labels[corev1.LabelTopologyZone]
+	if zone == "" {
+		zone = node.Labels[corev1.LabelFailureDomainBetaZone]
+	}
+	region = node.Labels[corev1.LabelTopologyRegion]
+	if region == "" {
+		region = node.Labels[corev1.LabelFailureDomainBetaRegion]
+	}
+	return zone, region
+}
 
+func externalAddresses(node *corev1.Node, overrideAnnotation string) []string {
+	if overrides, _ := overrideAddresses(node, overrideAnnotation); len(overrides) > 0 {
+		return overrides
+	}
+
+	var result []string
+	for i := 0; i < len(node.Status.Addresses); i++ {
+		if node.Status.Addresses[i].Type == corev1.NodeExternalIP {
+			result = append(result, node.Status.Addresses[i].Address)
+		}
+	}
 	return result
 }
 
-func translationFromNode(e watch.Event) []mapipwriter.Event {
+// overrideAddresses returns IPs of the comma separated value of the override annotation, e.g. "203.0.113.5" or
+// "203.0.113.5,2001:db8::5" for a dual-stack node. Values that are not IPs are returned as invalid.
+func overrideAddresses(node *corev1.Node, overrideAnnotation string) (valid, invalid []string) {
+	if overrideAnnotation == "" {
+		return nil, nil
+	}
+	for _, value := range strings.Split(node.Annotations[overrideAnnotation], ",") {
+		if value = strings.TrimSpace(value); value == "" {
+			continue
+		}
+		if net.ParseIP(value) == nil {
+			invalid = append(invalid, value)
+			continue
+		}
+		valid = append(valid, value)
+	}
+	return valid, invalid
+}
+
+func translationFromNode(e watch.Event, overrideAnnotation string, allExternals bool) []mapipwriter.Event {
 	var result []mapipwriter.Event
 
 	var node = e.Object.(*corev1.Node)
+	var externals = externalAddresses(node, overrideAnnotation)
 
-	// map internal ip on itself, in case we don't have an external IP
 	for i := 0; i < len(node.Status.Addresses); i++ {
-		if node.Status.Addresses[i].Type == corev1.NodeInternalIP {
+		if node.Status.Addresses[i].Type != corev1.NodeInternalIP {
+			continue
+		}
+		var internal = node.Status.Addresses[i].Address
+		var targets = externals
+		if len(targets) > 1 && !allExternals {
+			targets = []string{firstOfSameFamily(internal, targets)}
+		}
+
+		// map internal ip on itself, in case we don't have an external IP
+		if len(targets) == 0 {
 			result = append(result, mapipwriter.Event{
 				Type: e.Type,
 				Translation: mapipwriter.Translation{
-					From: node.Status.Addresses[i].Address,
-					To:   node.Status.Addresses[i].Address,
+					From: internal,
+					To:   internal,
 				},
 			})
-
-			result[0].To = node.Status.Addresses[i].Address
-		}
-	}
-
-	// if we have external IPs, instead map internal IP to external
-	for i := 0; i < len(node.Status.Addresses); i++ {
-		if node.Status.Addresses[i].Type == corev1.NodeExternalIP {
-			for j := 0; j < len(result); j++ {
-				result[j].To = node.Status.Addresses[i].Address
-			}
-			break
+			continue
 		}
-	}
 
-	// map external IP to itself, in case we want to send data from external IP
-	for i := 0; i < len(node.Status.Addresses); i++ {
-		if node.Status.Addresses[i].Type == corev1.NodeExternalIP {
+		// if we have external IPs, instead map internal IP to external
+		for _, external := range targets {
 			result = append(result, mapipwriter.Event{
 				Type: e.Type,
 				Translation: mapipwriter.Translation{
-					From: node.Status.Addresses[i].Address,
-					To:   node.Status.Addresses[i].Address,
+					From: internal,
+					To:   external,
 				},
 			})
 		}
 	}
 
+	// map external IP to itself, in case we want to send data from external IP
+	for _, external := range externals {
+		result = append(result, mapipwriter.Event{
+			Type: e.Type,
+			Translation: mapipwriter.Translation{
+				From: external,
+				To:   external,
+			},
+		})
+	}
+
+	return result
+}
+
+// firstOfSameFamily returns the first address of the same family as ip or the first address if there is no such one
+func firstOfSameFamily(ip string, addresses []string) string {
+	for _, address := range addresses {
+		if isIPv4(address) == isIPv4(ip) {
+			return address
+		}
+	}
+	return addresses[0]
+}
+
+// translationFromNodePodCIDRs maps pod CIDRs of the node to the address the node is mapped to, so whole pod subnets
+// are translated
+func translationFromNodePodCIDRs(e watch.Event, nodeEvents []mapipwriter.Event) []mapipwriter.Event {
+	var node = e.Object.(*corev1.Node)
+	if len(nodeEvents) == 0 {
+		return nil
+	}
+
+	var podCIDRs = node.Spec.PodCIDRs
+	if len(podCIDRs) == 0 && node.Spec.PodCIDR != "" {
+		podCIDRs = []string{node.Spec.PodCIDR}
+	}
+
+	var result []mapipwriter.Event
+	for _, podCIDR := range podCIDRs {
+		var translation = mapipwriter.Translation{From: podCIDR}
+		for i := range nodeEvents {
+			// pick the first address of the same family
+			translation.To = nodeEvents[i].To
+			if translation.Validate() == nil {
+				break
+			}
+		}
+		result = append(result, mapipwriter.Event{
+			Type:        e.Type,
+			Translation: translation,
+		})
+	}
 	return result
 }
@@ -17,22 +17,48 @@
 package main_test
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/kelseyhightower/envconfig"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	logrustest "github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 	"go.uber.org/goleak"
 	"gopkg.in/yaml.v2"
 
 	mainpkg "github.com/networkservicemesh/cmd-map-ip-k8s"
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+	"github.com/networkservicemesh/sdk/pkg/tools/log/logruslogger"
 
 	v1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
 	k8stest "k8s.io/client-go/testing"
 )
 
@@ -57,7 +83,7 @@ func Test_NodeHasChanged(t *testing.T) {
 		"1.1.1.3": "2.1.1.3",
 	}
 
-	var appCh = mainpkg.Start(ctx, conf, client)
+	var appCh = mainpkg.Start(ctx, conf, client, nil)
 	go func() {
 		defer watcher.Stop()
 		time.Sleep(time.Millisecond * 30)
@@ -89,72 +115,367 @@ func Test_NodeHasChanged(t *testing.T) {
 	}, time.Second*2, time.Second/10)
 }
 
-func Test_ConfigMapLoadedFromStart(t *testing.T) {
+func Test_SkipUnchangedWritesByDefault(t *testing.T) {
 	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("k8s.io/klog/v2.(*loggingT).flushDaemon"))
 
 	var ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
 	defer cancel()
 
-	var conf = &mainpkg.Config{
-		OutputPath:    filepath.Join(t.TempDir(), "output.yaml"),
-		FromConfigMap: "test",
-		Namespace:     "nsm",
-	}
+	t.Setenv("NSM_OUTPUT_PATH", filepath.Join(t.TempDir(), "output.yaml"))
 
-	var client = fake.NewSimpleClientset()
-	_, err := client.CoreV1().ConfigMaps(conf.Namespace).Create(ctx, &v1.ConfigMap{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      "test",
-			Namespace: "nsm",
+	var conf = &mainpkg.Config{}
+	require.NoError(t, envconfig.Process("nsm", conf))
+
+	var node = &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status: v1.NodeStatus{
+			Addresses: []v1.NodeAddress{
+				{Type: v1.NodeInternalIP, Address: "1.1.1.1"},
+				{Type: v1.NodeExternalIP, Address: "2.1.1.1"},
+			},
 		},
-		Data: map[string]string{
-			"config.yaml": "1.1.1.1: 2.1.1.1\n1.1.1.2: 2.1.1.2",
+	}
+	var client = fake.NewSimpleClientset(node)
+
+	var appCh = mainpkg.Start(ctx, conf, client, nil)
+
+	require.Len(t, appCh, 0)
+	require.Eventually(t, func() bool {
+		return verifyIPmap(conf.OutputPath, map[string]string{"1.1.1.1": "2.1.1.1"}, false)
+	}, time.Second, time.Second/10)
+
+	written, err := os.Stat(conf.OutputPath)
+	require.NoError(t, err)
+
+	node.Labels = map[string]string{"updated": "true"}
+	_, err = client.CoreV1().Nodes().Update(ctx, node, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	time.Sleep(time.Millisecond * 200)
+
+	current, err := os.Stat(conf.OutputPath)
+	require.NoError(t, err)
+	require.Equal(t, written.ModTime(), current.ModTime())
+}
+
+func Test_Kubeconfig(t *testing.T) {
+	const kubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: dev
+  cluster:
+    server: https://%v:6443
+contexts:
+- name: dev
+  context:
+    cluster: dev
+    user: dev
+current-context: dev
+users:
+- name: dev
+  user:
+    token: token
+`
+	var dir = t.TempDir()
+	for _, name := range []string{"nsm", "standard"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(fmt.Sprintf(kubeconfig, name+".example.com")), 0o600))
+	}
+	t.Setenv("KUBERNETES_SERVICE_HOST", "")
+	t.Setenv("KUBECONFIG", filepath.Join(dir, "standard"))
+
+	restConfig, err := mainpkg.NewRestConfig(&mainpkg.Config{Kubeconfig: filepath.Join(dir, "nsm")})
+	require.NoError(t, err)
+	require.Equal(t, "https://nsm.example.com:6443", restConfig.Host)
+
+	restConfig, err = mainpkg.NewRestConfig(&mainpkg.Config{})
+	require.NoError(t, err)
+	require.Equal(t, "https://standard.example.com:6443", restConfig.Host)
+
+	_, err = mainpkg.NewRestConfig(&mainpkg.Config{Kubeconfig: filepath.Join(dir, "missing")})
+	require.Error(t, err)
+}
+
+func Test_ConfigFileReload(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("k8s.io/klog/v2.(*loggingT).flushDaemon"))
+
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var dir = t.TempDir()
+	var configFile = filepath.Join(dir, "config", "config.yaml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(configFile), 0o700))
+	require.NoError(t, os.WriteFile(configFile, []byte("NSM_OUTPUT_PATH: "+filepath.Join(dir, "a.yaml")+"\n"), 0o600))
+	t.Setenv("NSM_CONFIG_FILE", configFile)
+	t.Setenv("NSM_OUTPUT_PATH", filepath.Join(dir, "env.yaml"))
+
+	conf, err := mainpkg.LoadConfig(nil)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(dir, "a.yaml"), conf.OutputPath)
+
+	var client = fake.NewSimpleClientset(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status: v1.NodeStatus{
+			Addresses: []v1.NodeAddress{
+				{Type: v1.NodeInternalIP, Address: "1.1.1.1"},
+				{Type: v1.NodeExternalIP, Address: "2.1.1.1"},
+			},
 		},
-	}, metav1.CreateOptions{})
+	})
+
+	var appCh = mainpkg.StartWithReload(ctx, conf, client, nil, nil)
+
+	require.Eventually(t, func() bool {
+		return verifyIPmap(filepath.Join(dir, "a.yaml"), map[string]string{"1.1.1.1": "2.1.1.1"}, false)
+	}, time.Second, time.Second/10)
+
+	require.NoError(t, os.WriteFile(configFile, []byte("output_path: "+filepath.Join(dir, "b.yaml")+"\nwrite_debounce: 10ms\n"), 0o600))
+
+	require.Eventually(t, func() bool {
+		return verifyIPmap(filepath.Join(dir, "b.yaml"), map[string]string{"1.1.1.1": "2.1.1.1"}, false)
+	}, time.Second*2, time.Second/10)
+
+	require.NoError(t, os.WriteFile(configFile, []byte("unknown: value\n"), 0o600))
+	_, err = mainpkg.LoadConfig(nil)
+	require.Error(t, err)
+
+	cancel()
+	<-appCh
+}
+
+func Test_ConfigFileReloadKeepsOutput(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("k8s.io/klog/v2.(*loggingT).flushDaemon"))
+
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var dir = t.TempDir()
+	var output = filepath.Join(dir, "output.yaml")
+	var configFile = filepath.Join(dir, "config", "config.yaml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(configFile), 0o700))
+	require.NoError(t, os.WriteFile(configFile, []byte("output_path: "+output+"\noutput_on_shutdown: truncate\n"), 0o600))
+	t.Setenv("NSM_CONFIG_FILE", configFile)
+
+	conf, err := mainpkg.LoadConfig(nil)
 	require.NoError(t, err)
 
-	var appCh = mainpkg.Start(ctx, conf, client)
+	var client = fake.NewSimpleClientset(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status: v1.NodeStatus{
+			Addresses: []v1.NodeAddress{
+				{Type: v1.NodeInternalIP, Address: "1.1.1.1"},
+				{Type: v1.NodeExternalIP, Address: "2.1.1.1"},
+			},
+		},
+	})
+
+	var appCh = mainpkg.StartWithReload(ctx, conf, client, nil, nil)
 
-	require.Len(t, appCh, 0)
+	require.Eventually(t, func() bool {
+		return verifyIPmap(output, map[string]string{"1.1.1.1": "2.1.1.1"}, false)
+	}, time.Second, time.Second/10)
 
+	require.NoError(t, os.WriteFile(configFile, []byte("output_path: "+output+"\noutput_on_shutdown: truncate\n"+
+		"static_mappings: 10.0.0.1=203.0.113.1\n"), 0o600))
+
+	// the map written before the restart is kept until the restarted app writes the complete one
 	require.Eventually(t, func() bool {
-		return verifyIPmap(
-			conf.OutputPath,
-			map[string]string{
-				"1.1.1.1": "2.1.1.1",
-				"1.1.1.2": "2.1.1.2",
+		require.True(t, verifyIPmap(output, map[string]string{"1.1.1.1": "2.1.1.1"}, false))
+		return verifyIPmap(output, map[string]string{"10.0.0.1": "203.0.113.1"}, false)
+	}, time.Second*2, time.Millisecond)
+
+	cancel()
+	<-appCh
+}
+
+func Test_ConfigFileReloadServers(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("k8s.io/klog/v2.(*loggingT).flushDaemon"))
+
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	var httpAddr = listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	var dir = t.TempDir()
+	var socket = filepath.Join(dir, "query.sock")
+	var configFile = filepath.Join(dir, "config", "config.yaml")
+	var config = "output_path: " + filepath.Join(dir, "output.yaml") + "\nquery_socket: " + socket + "\nhttp_listen_on: " + httpAddr + "\n"
+	require.NoError(t, os.MkdirAll(filepath.Dir(configFile), 0o700))
+	require.NoError(t, os.WriteFile(configFile, []byte(config), 0o600))
+	t.Setenv("NSM_CONFIG_FILE", configFile)
+
+	conf, err := mainpkg.LoadConfig(nil)
+	require.NoError(t, err)
+
+	var client = fake.NewSimpleClientset(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status: v1.NodeStatus{
+			Addresses: []v1.NodeAddress{
+				{Type: v1.NodeInternalIP, Address: "1.1.1.1"},
+				{Type: v1.NodeExternalIP, Address: "2.1.1.1"},
 			},
-			false,
-		)
+		},
+	})
+
+	var resolve = func(ip string) string {
+		conn, dialErr := net.Dial("unix", socket)
+		if dialErr != nil {
+			return ""
+		}
+		defer func() { _ = conn.Close() }()
+		_, _ = fmt.Fprintf(conn, "resolve %v\n", ip)
+		var response struct {
+			To string `json:"to"`
+		}
+		_ = json.NewDecoder(conn).Decode(&response)
+		return response.To
+	}
+	var getMapping = func(ip string) int {
+		request, requestErr := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+httpAddr+"/mappings/"+ip, http.NoBody)
+		require.NoError(t, requestErr)
+		response, requestErr := http.DefaultClient.Do(request)
+		if requestErr != nil {
+			return 0
+		}
+		_ = response.Body.Close()
+		return response.StatusCode
+	}
+
+	var appCh = mainpkg.StartWithReload(ctx, conf, client, nil, nil)
+
+	require.Eventually(t, func() bool {
+		return resolve("1.1.1.1") == "2.1.1.1" && getMapping("1.1.1.1") == http.StatusOK
+	}, time.Second*2, time.Second/10)
+
+	require.NoError(t, os.WriteFile(configFile, []byte(config+"static_mappings: 10.0.0.1=203.0.113.1\n"), 0o600))
+
+	// servers of the restarted app listen on the same addresses, the socket is not removed by the stopped app
+	require.Eventually(t, func() bool {
+		return resolve("10.0.0.1") == "203.0.113.1" && getMapping("10.0.0.1") == http.StatusOK
 	}, time.Second*2, time.Second/10)
+	require.Equal(t, "2.1.1.1", resolve("1.1.1.1"))
+
+	cancel()
+	<-appCh
 }
 
-func Test_ConfigMapHasChanged(t *testing.T) {
+func Test_Flags(t *testing.T) {
+	var configFile = filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte("output_path: file.yaml\nnamespace: file\n"), 0o600))
+	t.Setenv("NSM_OUTPUT_PATH", "env.yaml")
+	t.Setenv("NSM_NODE_NAME", "env-node")
+	t.Setenv("NSM_NAMESPACE", "env")
+
+	conf, err := mainpkg.LoadConfig([]string{"--output-path", "flag.yaml", "--one-shot", "--write-debounce=1s", "--config-file", configFile})
+	require.NoError(t, err)
+	require.Equal(t, "flag.yaml", conf.OutputPath)
+	require.Equal(t, "file", conf.Namespace)
+	require.Equal(t, "env-node", conf.NodeName)
+	require.Equal(t, "INFO", conf.LogLevel)
+	require.True(t, conf.OneShot)
+	require.Equal(t, time.Second, conf.WriteDebounce)
+
+	_, err = mainpkg.LoadConfig([]string{"--write-debounce=often"})
+	require.Error(t, err)
+	_, err = mainpkg.LoadConfig([]string{"--unknown"})
+	require.Error(t, err)
+	_, err = mainpkg.LoadConfig([]string{"--version"})
+	require.ErrorIs(t, err, mainpkg.ErrVersion)
+}
+
+func Test_NodeExternalIPAnnotationDualStack(t *testing.T) {
 	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("k8s.io/klog/v2.(*loggingT).flushDaemon"))
 
 	var ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
 	defer cancel()
 
 	var conf = &mainpkg.Config{
-		OutputPath:    filepath.Join(t.TempDir(), "output.yaml"),
-		FromConfigMap: "test",
-		Namespace:     "nsm",
+		OutputPath:           filepath.Join(t.TempDir(), "output.yaml"),
+		ExternalIPAnnotation: "nsm.io/external-ip",
+		NodeName:             "node-1",
+		PodIP:                "10.244.0.5",
+	}
+
+	var node = &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node-1",
+			Annotations: map[string]string{
+				"nsm.io/external-ip": "203.0.113.5, not-an-ip, 2001:db8::5",
+			},
+		},
+		Status: v1.NodeStatus{
+			Addresses: []v1.NodeAddress{
+				{Type: v1.NodeInternalIP, Address: "1.1.1.1"},
+				{Type: v1.NodeInternalIP, Address: "fd00::1"},
+				{Type: v1.NodeExternalIP, Address: "2.1.1.1"},
+			},
+		},
 	}
 
 	var client = fake.NewSimpleClientset()
 	watcher := watch.NewFake()
-	client.PrependWatchReactor("configmaps", k8stest.DefaultWatchReactor(watcher, nil))
+	client.PrependWatchReactor("nodes", k8stest.DefaultWatchReactor(watcher, nil))
 
-	var appCh = mainpkg.Start(ctx, conf, client)
+	var appCh = mainpkg.Start(ctx, conf, client, nil)
 	go func() {
 		defer watcher.Stop()
 		time.Sleep(time.Millisecond * 30)
-		watcher.Add(&v1.ConfigMap{
+		watcher.Add(node)
+	}()
+
+	require.Len(t, appCh, 0)
+
+	require.Eventually(t, func() bool {
+		return verifyIPmap(conf.OutputPath, map[string]string{
+			"1.1.1.1":     "203.0.113.5",
+			"fd00::1":     "2001:db8::5",
+			"10.244.0.5":  "203.0.113.5",
+			"203.0.113.5": "203.0.113.5",
+			"2001:db8::5": "2001:db8::5",
+		}, false)
+	}, time.Second*2, time.Second/10)
+
+	require.False(t, verifyIPmap(conf.OutputPath, map[string]string{"not-an-ip": "not-an-ip"}, false))
+	require.False(t, verifyIPmap(conf.OutputPath, map[string]string{"2.1.1.1": "2.1.1.1"}, false))
+}
+
+func Test_PodIPMapping(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("k8s.io/klog/v2.(*loggingT).flushDaemon"))
+
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	t.Setenv("NSM_POD_IP", "10.244.0.5")
+	t.Setenv("NSM_NODE_NAME", "node-1")
+	t.Setenv("NSM_OUTPUT_PATH", filepath.Join(t.TempDir(), "output.yaml"))
+
+	var conf = &mainpkg.Config{}
+	require.NoError(t, envconfig.Process("nsm", conf))
+
+	var client = fake.NewSimpleClientset()
+	watcher := watch.NewFake()
+	client.PrependWatchReactor("nodes", k8stest.DefaultWatchReactor(watcher, nil))
+
+	var appCh = mainpkg.Start(ctx, conf, client, nil)
+	go func() {
+		defer watcher.Stop()
+		time.Sleep(time.Millisecond * 30)
+		watcher.Add(&v1.Node{
 			ObjectMeta: metav1.ObjectMeta{
-				Name: "test",
+				Name: "node-1",
 			},
-			Data: map[string]string{
-				"config.yaml": "1.1.1.1: 2.1.1.1\n1.1.1.2: 2.1.1.2",
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{
+						Type:    v1.NodeInternalIP,
+						Address: "1.1.1.1",
+					},
+					{
+						Type:    v1.NodeExternalIP,
+						Address: "2.1.1.1",
+					},
+				},
 			},
 		})
 	}()
@@ -162,44 +483,2277 @@ func Test_ConfigMapHasChanged(t *testing.T) {
 	require.Len(t, appCh, 0)
 
 	require.Eventually(t, func() bool {
-		return verifyIPmap(
-			conf.OutputPath,
-			map[string]string{
-				"1.1.1.1": "2.1.1.1",
-				"1.1.1.2": "2.1.1.2",
+		return verifyIPmap(conf.OutputPath, map[string]string{
+			"1.1.1.1":    "2.1.1.1",
+			"10.244.0.5": "2.1.1.1",
+		}, true)
+	}, time.Second*2, time.Second/10)
+}
+
+func Test_NodeExternalIPAnnotation(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("k8s.io/klog/v2.(*loggingT).flushDaemon"))
+
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var conf = &mainpkg.Config{
+		OutputPath:           filepath.Join(t.TempDir(), "output.yaml"),
+		ExternalIPAnnotation: "nsm.io/external-ip",
+	}
+
+	var client = fake.NewSimpleClientset(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node-1",
+			Annotations: map[string]string{
+				"nsm.io/external-ip": "203.0.113.5",
 			},
-			false,
-		)
+		},
+		Status: v1.NodeStatus{
+			Addresses: []v1.NodeAddress{
+				{
+					Type:    v1.NodeInternalIP,
+					Address: "1.1.1.1",
+				},
+				{
+					Type:    v1.NodeExternalIP,
+					Address: "2.1.1.1",
+				},
+			},
+		},
+	})
+
+	var appCh = mainpkg.Start(ctx, conf, client, nil)
+
+	require.Len(t, appCh, 0)
+
+	require.Eventually(t, func() bool {
+		return verifyIPmap(conf.OutputPath, map[string]string{"1.1.1.1": "203.0.113.5"}, true)
 	}, time.Second*2, time.Second/10)
+
+	require.False(t, verifyIPmap(conf.OutputPath, map[string]string{"2.1.1.1": "2.1.1.1"}, false))
 }
 
-func verifyIPmap(p string, expected map[string]string, checkTargetMapping bool) bool {
-	// #nosec
-	b, err := os.ReadFile(p)
+func Test_NodeExternalIPAnnotationChanged(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("k8s.io/klog/v2.(*loggingT).flushDaemon"))
 
-	if err != nil {
-		return false
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var conf = &mainpkg.Config{
+		OutputPath:           filepath.Join(t.TempDir(), "output.yaml"),
+		ExternalIPAnnotation: "nsm.io/external-ip",
 	}
 
-	var m = make(map[string]string)
+	var node = &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "node-1",
+			Annotations: map[string]string{"nsm.io/external-ip": "203.0.113.5"},
+		},
+		Status: v1.NodeStatus{
+			Addresses: []v1.NodeAddress{{Type: v1.NodeInternalIP, Address: "1.1.1.1"}},
+		},
+	}
+	var client = fake.NewSimpleClientset(node)
 
-	err = yaml.Unmarshal(b, &m)
+	var appCh = mainpkg.Start(ctx, conf, client, nil)
 
-	if err != nil {
-		return false
+	require.Len(t, appCh, 0)
+	require.Eventually(t, func() bool {
+		return verifyIPmap(conf.OutputPath, map[string]string{"1.1.1.1": "203.0.113.5"}, true)
+	}, time.Second*2, time.Second/10)
+
+	node.Annotations["nsm.io/external-ip"] = "203.0.113.1"
+	_, err := client.CoreV1().Nodes().Update(ctx, node, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		// #nosec
+		b, readErr := os.ReadFile(conf.OutputPath)
+		return readErr == nil && verifyIPmap(conf.OutputPath, map[string]string{"1.1.1.1": "203.0.113.1"}, true) &&
+			!strings.Contains(string(b), "203.0.113.5")
+	}, time.Second*2, time.Second/10)
+}
+
+func Test_NodeAllExternalIPs(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("k8s.io/klog/v2.(*loggingT).flushDaemon"))
+
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var conf = &mainpkg.Config{
+		OutputPath:         filepath.Join(t.TempDir(), "output.yaml"),
+		OutputMultipleTo:   true,
+		NodeAllExternalIPs: true,
 	}
 
-	for k, v := range expected {
-		if m[k] != v {
+	var client = fake.NewSimpleClientset(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node-1",
+		},
+		Status: v1.NodeStatus{
+			Addresses: []v1.NodeAddress{
+				{
+					Type:    v1.NodeInternalIP,
+					Address: "1.1.1.1",
+				},
+				{
+					Type:    v1.NodeExternalIP,
+					Address: "2.1.1.1",
+				},
+				{
+					Type:    v1.NodeExternalIP,
+					Address: "2.1.1.2",
+				},
+			},
+		},
+	})
+
+	var appCh = mainpkg.Start(ctx, conf, client, nil)
+
+	require.Len(t, appCh, 0)
+
+	var expected = map[string][]string{
+		"1.1.1.1": {"2.1.1.1", "2.1.1.2"},
+		"2.1.1.1": {"2.1.1.1"},
+		"2.1.1.2": {"2.1.1.2"},
+	}
+	require.Eventually(t, func() bool {
+		// #nosec
+		b, err := os.ReadFile(conf.OutputPath)
+		if err != nil {
 			return false
 		}
-		if checkTargetMapping {
-			// check that target values are mapped on themselves
-			if m[v] != v {
-				return false
-			}
-		}
+		var m map[string][]string
+		return yaml.Unmarshal(b, &m) == nil && reflect.DeepEqual(expected, m)
+	}, time.Second*2, time.Second/10)
+}
+
+func Test_NodePodCIDRs(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("k8s.io/klog/v2.(*loggingT).flushDaemon"))
+
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var conf = &mainpkg.Config{
+		OutputPath:   filepath.Join(t.TempDir(), "output.yaml"),
+		NodePodCIDRs: true,
 	}
 
-	return true
+	var client = fake.NewSimpleClientset(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node-1",
+		},
+		Spec: v1.NodeSpec{
+			PodCIDRs: []string{"10.244.1.0/24", "fd00:10:244:1::/64"},
+		},
+		Status: v1.NodeStatus{
+			Addresses: []v1.NodeAddress{
+				{
+					Type:    v1.NodeInternalIP,
+					Address: "1.1.1.1",
+				},
+				{
+					Type:    v1.NodeInternalIP,
+					Address: "fd00::1",
+				},
+				{
+					Type:    v1.NodeExternalIP,
+					Address: "2.1.1.1",
+				},
+			},
+		},
+	})
+
+	var appCh = mainpkg.Start(ctx, conf, client, nil)
+
+	require.Len(t, appCh, 0)
+
+	require.Eventually(t, func() bool {
+		return verifyIPmap(conf.OutputPath, map[string]string{
+			"1.1.1.1":       "2.1.1.1",
+			"10.244.1.0/24": "2.1.1.1",
+		}, true)
+	}, time.Second*2, time.Second/10)
+}
+
+func Test_RunOnce(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("k8s.io/klog/v2.(*loggingT).flushDaemon"))
+
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var conf = &mainpkg.Config{
+		FromConfigMap: "test",
+		Namespace:     "nsm",
+		OneShotStdout: true,
+	}
+
+	var client = fake.NewSimpleClientset(
+		&v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test",
+				Namespace: "nsm",
+			},
+			Data: map[string]string{
+				"config.yaml": "1.1.1.1: 2.1.1.1",
+			},
+		},
+		&v1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "node-1",
+			},
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{
+						Type:    v1.NodeInternalIP,
+						Address: "3.1.1.1",
+					},
+				},
+			},
+		},
+	)
+
+	var stdout bytes.Buffer
+	require.NoError(t, mainpkg.RunOnce(ctx, conf, client, nil, &stdout))
+	require.Equal(t, "1.1.1.1: 2.1.1.1\n3.1.1.1: 3.1.1.1\n", stdout.String())
+}
+
+func Test_ConfigMapLoadedFromStart(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("k8s.io/klog/v2.(*loggingT).flushDaemon"))
+
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var conf = &mainpkg.Config{
+		OutputPath:    filepath.Join(t.TempDir(), "output.yaml"),
+		FromConfigMap: "test",
+		Namespace:     "nsm",
+	}
+
+	var client = fake.NewSimpleClientset()
+	_, err := client.CoreV1().ConfigMaps(conf.Namespace).Create(ctx, &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "nsm",
+		},
+		Data: map[string]string{
+			"config.yaml": "1.1.1.1: 2.1.1.1\n1.1.1.2: 2.1.1.2",
+		},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	var appCh = mainpkg.Start(ctx, conf, client, nil)
+
+	require.Len(t, appCh, 0)
+
+	require.Eventually(t, func() bool {
+		return verifyIPmap(
+			conf.OutputPath,
+			map[string]string{
+				"1.1.1.1": "2.1.1.1",
+				"1.1.1.2": "2.1.1.2",
+			},
+			false,
+		)
+	}, time.Second*2, time.Second/10)
+}
+
+func Test_ConfigMapKeyRemoved(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("k8s.io/klog/v2.(*loggingT).flushDaemon"))
+
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var conf = &mainpkg.Config{
+		OutputPath:    filepath.Join(t.TempDir(), "output.yaml"),
+		FromConfigMap: "test",
+		Namespace:     "nsm",
+		ConfigMapOnly: true,
+	}
+
+	var client = fake.NewSimpleClientset()
+	watcher := watch.NewFake()
+	client.PrependWatchReactor("configmaps", k8stest.DefaultWatchReactor(watcher, nil))
+
+	var cm = &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "nsm",
+		},
+		Data: map[string]string{
+			"config.yaml": "1.1.1.1: 2.1.1.1\n1.1.1.2: 2.1.1.2",
+		},
+	}
+
+	var appCh = mainpkg.Start(ctx, conf, client, nil)
+	go func() {
+		defer watcher.Stop()
+		time.Sleep(time.Millisecond * 30)
+		watcher.Add(cm.DeepCopy())
+		time.Sleep(time.Millisecond * 300)
+		cm.Data["config.yaml"] = "1.1.1.1: 2.1.1.1"
+		watcher.Modify(cm.DeepCopy())
+	}()
+
+	require.Len(t, appCh, 0)
+
+	require.Eventually(t, func() bool {
+		return verifyIPmap(conf.OutputPath, map[string]string{
+			"1.1.1.1": "2.1.1.1",
+			"1.1.1.2": "2.1.1.2",
+		}, false)
+	}, time.Second*2, time.Second/10)
+
+	require.Eventually(t, func() bool {
+		return verifyIPmap(conf.OutputPath, map[string]string{"1.1.1.1": "2.1.1.1"}, false) &&
+			!verifyIPmap(conf.OutputPath, map[string]string{"1.1.1.2": "2.1.1.2"}, false)
+	}, time.Second*2, time.Second/10)
+}
+
+func Test_ConfigMapOnly(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("k8s.io/klog/v2.(*loggingT).flushDaemon"))
+
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var conf = &mainpkg.Config{
+		OutputPath:    filepath.Join(t.TempDir(), "output.yaml"),
+		FromConfigMap: "test",
+		Namespace:     "nsm",
+		ConfigMapOnly: true,
+	}
+
+	var client = fake.NewSimpleClientset(
+		&v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test",
+				Namespace: "nsm",
+			},
+			Data: map[string]string{
+				"config.yaml": "1.1.1.1: 2.1.1.1",
+			},
+		},
+		&v1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "node-1",
+			},
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{
+						Type:    v1.NodeInternalIP,
+						Address: "3.1.1.1",
+					},
+				},
+			},
+		},
+	)
+
+	var appCh = mainpkg.Start(ctx, conf, client, nil)
+
+	require.Len(t, appCh, 0)
+
+	require.Eventually(t, func() bool {
+		return verifyIPmap(conf.OutputPath, map[string]string{"1.1.1.1": "2.1.1.1"}, false)
+	}, time.Second*2, time.Second/10)
+
+	require.False(t, verifyIPmap(conf.OutputPath, map[string]string{"3.1.1.1": "3.1.1.1"}, false))
+	for _, action := range client.Actions() {
+		require.NotEqual(t, "nodes", action.GetResource().Resource)
+	}
+}
+
+func Test_MultipleOutputPaths(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("k8s.io/klog/v2.(*loggingT).flushDaemon"))
+
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var dir = t.TempDir()
+	var paths = []string{filepath.Join(dir, "first", "output.yaml"), filepath.Join(dir, "second", "output.yaml")}
+	var conf = &mainpkg.Config{
+		OutputPath: strings.Join(paths, ", "),
+	}
+
+	var client = fake.NewSimpleClientset(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node-1",
+		},
+		Status: v1.NodeStatus{
+			Addresses: []v1.NodeAddress{
+				{
+					Type:    v1.NodeInternalIP,
+					Address: "127.0.0.1",
+				},
+			},
+		},
+	})
+
+	var appCh = mainpkg.Start(ctx, conf, client, nil)
+
+	require.Len(t, appCh, 0)
+
+	for _, path := range paths {
+		require.Eventually(t, func() bool {
+			return verifyIPmap(path, map[string]string{"127.0.0.1": "127.0.0.1"}, false)
+		}, time.Second*2, time.Second/10)
+	}
+}
+
+func Test_ToConfigMap(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("k8s.io/klog/v2.(*loggingT).flushDaemon"))
+
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var conf = &mainpkg.Config{
+		OutputPath:           filepath.Join(t.TempDir(), "output.yaml"),
+		Namespace:            "nsm",
+		ToConfigMap:          "external-ips",
+		ToConfigMapNamespace: "shared",
+		ToConfigMapKey:       "external_ips.yaml",
+	}
+
+	var client = fake.NewSimpleClientset(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node-1",
+		},
+		Status: v1.NodeStatus{
+			Addresses: []v1.NodeAddress{
+				{
+					Type:    v1.NodeInternalIP,
+					Address: "127.0.0.1",
+				},
+				{
+					Type:    v1.NodeExternalIP,
+					Address: "148.142.120.1",
+				},
+			},
+		},
+	})
+
+	var appCh = mainpkg.Start(ctx, conf, client, nil)
+
+	require.Len(t, appCh, 0)
+
+	require.Eventually(t, func() bool {
+		cm, err := client.CoreV1().ConfigMaps("shared").Get(ctx, "external-ips", metav1.GetOptions{})
+		if err != nil {
+			return false
+		}
+		var m map[string]string
+		if yaml.Unmarshal([]byte(cm.Data["external_ips.yaml"]), &m) != nil {
+			return false
+		}
+		return m["127.0.0.1"] == "148.142.120.1" && m["148.142.120.1"] == "148.142.120.1"
+	}, time.Second*2, time.Second/10)
+}
+
+func Test_ConfigMapHasChanged(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("k8s.io/klog/v2.(*loggingT).flushDaemon"))
+
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var conf = &mainpkg.Config{
+		OutputPath:    filepath.Join(t.TempDir(), "output.yaml"),
+		FromConfigMap: "test",
+		Namespace:     "nsm",
+	}
+
+	var client = fake.NewSimpleClientset()
+	watcher := watch.NewFake()
+	client.PrependWatchReactor("configmaps", k8stest.DefaultWatchReactor(watcher, nil))
+
+	var appCh = mainpkg.Start(ctx, conf, client, nil)
+	go func() {
+		defer watcher.Stop()
+		time.Sleep(time.Millisecond * 30)
+		watcher.Add(&v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test",
+			},
+			Data: map[string]string{
+				"config.yaml": "1.1.1.1: 2.1.1.1\n1.1.1.2: 2.1.1.2",
+			},
+		})
+	}()
+
+	require.Len(t, appCh, 0)
+
+	require.Eventually(t, func() bool {
+		return verifyIPmap(
+			conf.OutputPath,
+			map[string]string{
+				"1.1.1.1": "2.1.1.1",
+				"1.1.1.2": "2.1.1.2",
+			},
+			false,
+		)
+	}, time.Second*2, time.Second/10)
+}
+
+func Test_ConfigMapWrongShape(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("k8s.io/klog/v2.(*loggingT).flushDaemon"))
+
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var hook = logrustest.NewGlobal()
+	defer logrus.StandardLogger().ReplaceHooks(make(logrus.LevelHooks))
+	ctx = log.WithLog(ctx, logruslogger.New(ctx))
+
+	var conf = &mainpkg.Config{
+		OutputPath:    filepath.Join(t.TempDir(), "output.yaml"),
+		FromConfigMap: "test",
+		Namespace:     "nsm",
+	}
+
+	var client = fake.NewSimpleClientset(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "nsm",
+		},
+		Data: map[string]string{
+			"config.yaml": "1.1.1.1: 2.1.1.1",
+			"list.yaml":   "- 1.1.1.2\n- 2.1.1.2",
+		},
+	})
+
+	var appCh = mainpkg.Start(ctx, conf, client, nil)
+
+	require.Len(t, appCh, 0)
+
+	require.Eventually(t, func() bool {
+		return verifyIPmap(conf.OutputPath, map[string]string{"1.1.1.1": "2.1.1.1"}, false)
+	}, time.Second*2, time.Second/10)
+
+	var warned bool
+	for _, entry := range hook.AllEntries() {
+		if entry.Level == logrus.WarnLevel &&
+			strings.Contains(entry.Message, `key "list.yaml" contains a list`) {
+			warned = true
+		}
+	}
+	require.True(t, warned)
+}
+
+func Test_WatchForbidden(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("k8s.io/klog/v2.(*loggingT).flushDaemon"))
+
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var hook = logrustest.NewGlobal()
+	defer logrus.StandardLogger().ReplaceHooks(make(logrus.LevelHooks))
+	ctx = log.WithLog(ctx, logruslogger.New(ctx))
+
+	var conf = &mainpkg.Config{
+		OutputPath: filepath.Join(t.TempDir(), "output.yaml"),
+	}
+
+	var client = fake.NewSimpleClientset()
+	client.PrependWatchReactor("nodes", func(k8stest.Action) (bool, watch.Interface, error) {
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "nodes"}, "", errors.New("rbac denied"))
+	})
+
+	var appCh = mainpkg.Start(ctx, conf, client, nil)
+
+	require.Len(t, appCh, 0)
+
+	require.Eventually(t, func() bool {
+		for _, entry := range hook.AllEntries() {
+			if entry.Level == logrus.ErrorLevel &&
+				strings.Contains(entry.Message, "forbidden to watch nodes") &&
+				strings.Contains(entry.Message, "RBAC permissions to list and watch nodes") {
+				return true
+			}
+		}
+		return false
+	}, time.Second*2, time.Second/10)
+}
+
+func Test_WatchResumesFromResourceVersion(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("k8s.io/klog/v2.(*loggingT).flushDaemon"))
+
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var conf = &mainpkg.Config{
+		OutputPath: filepath.Join(t.TempDir(), "output.yaml"),
+	}
+
+	var client = fake.NewSimpleClientset()
+	var watcher = watch.NewFake()
+	var resumedCh = make(chan string, 1)
+	var watches int32
+	client.PrependWatchReactor("nodes", func(action k8stest.Action) (bool, watch.Interface, error) {
+		if atomic.AddInt32(&watches, 1) == 1 {
+			return true, watcher, nil
+		}
+		select {
+		case resumedCh <- action.(k8stest.WatchAction).GetWatchRestrictions().ResourceVersion:
+		default:
+		}
+		return true, watch.NewFake(), nil
+	})
+
+	var appCh = mainpkg.Start(ctx, conf, client, nil)
+	go func() {
+		watcher.Add(&v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1", ResourceVersion: "10"},
+			Status:     v1.NodeStatus{Addresses: []v1.NodeAddress{{Type: v1.NodeInternalIP, Address: "1.1.1.1"}}},
+		})
+		watcher.Stop()
+	}()
+
+	require.Len(t, appCh, 0)
+
+	select {
+	case resourceVersion := <-resumedCh:
+		require.Equal(t, "10", resourceVersion)
+	case <-time.After(time.Second * 2):
+		require.FailNow(t, "the watch is not resumed")
+	}
+
+	var lists int
+	for _, action := range client.Actions() {
+		if action.GetVerb() == "list" && action.GetResource().Resource == "nodes" {
+			lists++
+		}
+	}
+	require.Equal(t, 1, lists)
+}
+
+func Test_WatchRestartsMetric(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("k8s.io/klog/v2.(*loggingT).flushDaemon"))
+
+	var reader = sdkmetric.NewManualReader()
+	var prevProvider = otel.GetMeterProvider()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	defer otel.SetMeterProvider(prevProvider)
+
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var conf = &mainpkg.Config{
+		OutputPath: filepath.Join(t.TempDir(), "output.yaml"),
+	}
+
+	var client = fake.NewSimpleClientset()
+	client.PrependWatchReactor("nodes", func(k8stest.Action) (bool, watch.Interface, error) {
+		return true, nil, apierrors.NewServiceUnavailable("apiserver is restarting")
+	})
+
+	var appCh = mainpkg.Start(ctx, conf, client, nil)
+
+	require.Len(t, appCh, 0)
+
+	require.Eventually(t, func() bool {
+		return sumMetric(t, reader, "map_ip_watch_restarts", attribute.String("resource", "nodes")) > 0
+	}, time.Second*2, time.Second/10)
+}
+
+func Test_InitialListRetried(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("k8s.io/klog/v2.(*loggingT).flushDaemon"))
+
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	var healthAddr = listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	var conf = &mainpkg.Config{
+		OutputPath:     filepath.Join(t.TempDir(), "output.yaml"),
+		HealthListenOn: healthAddr,
+	}
+
+	var client = fake.NewSimpleClientset(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status: v1.NodeStatus{
+			Addresses: []v1.NodeAddress{
+				{Type: v1.NodeInternalIP, Address: "1.1.1.1"},
+				{Type: v1.NodeExternalIP, Address: "148.142.120.1"},
+			},
+		},
+	})
+	var failures atomic.Int32
+	client.PrependReactor("list", "nodes", func(k8stest.Action) (bool, runtime.Object, error) {
+		if failures.Add(1) == 1 {
+			return true, nil, apierrors.NewServiceUnavailable("apiserver is restarting")
+		}
+		return false, nil, nil
+	})
+
+	var readyz = func() int {
+		request, requestErr := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+healthAddr+"/readyz", http.NoBody)
+		require.NoError(t, requestErr)
+		response, requestErr := http.DefaultClient.Do(request)
+		if requestErr != nil {
+			return 0
+		}
+		_ = response.Body.Close()
+		return response.StatusCode
+	}
+
+	var appCh = mainpkg.Start(ctx, conf, client, nil)
+
+	require.Len(t, appCh, 0)
+	require.Eventually(t, func() bool {
+		return readyz() == http.StatusServiceUnavailable
+	}, time.Second/2, time.Millisecond*10)
+
+	require.Eventually(t, func() bool {
+		return verifyIPmap(conf.OutputPath, map[string]string{"1.1.1.1": "148.142.120.1"}, false)
+	}, time.Second*5, time.Second/10)
+	require.Eventually(t, func() bool {
+		return readyz() == http.StatusOK
+	}, time.Second, time.Second/10)
+}
+
+func Test_MetricsTopologyLabels(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("k8s.io/klog/v2.(*loggingT).flushDaemon"))
+
+	var reader = sdkmetric.NewManualReader()
+	var prevProvider = otel.GetMeterProvider()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+	defer otel.SetMeterProvider(prevProvider)
+
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var conf = &mainpkg.Config{
+		OutputPath:            filepath.Join(t.TempDir(), "output.yaml"),
+		MetricsTopologyLabels: true,
+	}
+
+	var client = fake.NewSimpleClientset(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node-1",
+			Labels: map[string]string{
+				v1.LabelTopologyZone:   "zone-a",
+				v1.LabelTopologyRegion: "region-1",
+			},
+		},
+		Status: v1.NodeStatus{
+			Addresses: []v1.NodeAddress{
+				{
+					Type:    v1.NodeInternalIP,
+					Address: "1.1.1.1",
+				},
+			},
+		},
+	})
+
+	var appCh = mainpkg.Start(ctx, conf, client, nil)
+
+	require.Len(t, appCh, 0)
+
+	require.Eventually(t, func() bool {
+		return sumMetric(t, reader, "map_ip_nodes", attribute.String("zone", "zone-a")) == 1 &&
+			sumMetric(t, reader, "map_ip_entries", attribute.String("zone", "zone-a")) == 1
+	}, time.Second*2, time.Second/10)
+}
+
+func Test_EventQueueSize(t *testing.T) {
+	for _, size := range []int{0, 1} {
+		t.Run(fmt.Sprintf("size %v", size), func(t *testing.T) {
+			defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("k8s.io/klog/v2.(*loggingT).flushDaemon"))
+
+			var reader = sdkmetric.NewManualReader()
+			var prevProvider = otel.GetMeterProvider()
+			otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+			defer otel.SetMeterProvider(prevProvider)
+
+			var ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			var conf = &mainpkg.Config{
+				OutputPath:     filepath.Join(t.TempDir(), "output.yaml"),
+				EventQueueSize: size,
+			}
+
+			const count = 20
+			var client = fake.NewSimpleClientset()
+			var expected = make(map[string]string)
+			for i := 0; i < count; i++ {
+				_, err := client.CoreV1().Nodes().Create(ctx, &v1.Node{
+					ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("node-%v", i)},
+					Status: v1.NodeStatus{
+						Addresses: []v1.NodeAddress{
+							{Type: v1.NodeInternalIP, Address: fmt.Sprintf("10.0.0.%v", i)},
+							{Type: v1.NodeExternalIP, Address: fmt.Sprintf("148.142.120.%v", i)},
+						},
+					},
+				}, metav1.CreateOptions{})
+				require.NoError(t, err)
+				expected[fmt.Sprintf("10.0.0.%v", i)] = fmt.Sprintf("148.142.120.%v", i)
+			}
+
+			var appCh = mainpkg.Start(ctx, conf, client, nil)
+
+			require.Len(t, appCh, 0)
+
+			require.Eventually(t, func() bool {
+				return verifyIPmap(conf.OutputPath, expected, false)
+			}, time.Second*2, time.Second/10)
+
+			var rm metricdata.ResourceMetrics
+			require.NoError(t, reader.Collect(ctx, &rm))
+			var found bool
+			for _, sm := range rm.ScopeMetrics {
+				for _, m := range sm.Metrics {
+					found = found || m.Name == "map_ip_event_queue_depth"
+				}
+			}
+			require.True(t, found)
+		})
+	}
+}
+
+func sumMetric(t *testing.T, reader sdkmetric.Reader, name string, attr attribute.KeyValue) int64 {
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(context.Background(), &rm))
+
+	var result int64
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if m.Name != name {
+				continue
+			}
+			sum, ok := m.Data.(metricdata.Sum[int64])
+			if !ok {
+				continue
+			}
+			for _, dp := range sum.DataPoints {
+				if v, found := dp.Attributes.Value(attr.Key); found && v == attr.Value {
+					result += dp.Value
+				}
+			}
+		}
+	}
+	return result
+}
+
+func verifyIPmap(p string, expected map[string]string, checkTargetMapping bool) bool {
+	// #nosec
+	b, err := os.ReadFile(p)
+
+	if err != nil {
+		return false
+	}
+
+	var m = make(map[string]string)
+
+	err = yaml.Unmarshal(b, &m)
+
+	if err != nil {
+		return false
+	}
+
+	for k, v := range expected {
+		if m[k] != v {
+			return false
+		}
+		if checkTargetMapping {
+			// check that target values are mapped on themselves
+			if m[v] != v {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func Test_LoadBalancerServices(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("k8s.io/klog/v2.(*loggingT).flushDaemon"))
+
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var conf = &mainpkg.Config{
+		OutputPath:            filepath.Join(t.TempDir(), "output.yaml"),
+		ConfigMapOnly:         true,
+		FromLoadBalancers:     true,
+		LoadBalancerNodePorts: true,
+	}
+
+	var client = fake.NewSimpleClientset(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status: v1.NodeStatus{
+			Addresses: []v1.NodeAddress{{Type: v1.NodeInternalIP, Address: "10.0.0.1"}},
+		},
+	})
+	watcher := watch.NewFake()
+	client.PrependWatchReactor("services", k8stest.DefaultWatchReactor(watcher, nil))
+
+	var service = &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "lb", Namespace: "default"},
+		Spec: v1.ServiceSpec{
+			Type:      v1.ServiceTypeLoadBalancer,
+			ClusterIP: "10.96.0.10",
+			Ports:     []v1.ServicePort{{Port: 443, NodePort: 30443}},
+		},
+		Status: v1.ServiceStatus{LoadBalancer: v1.LoadBalancerStatus{
+			Ingress: []v1.LoadBalancerIngress{{Hostname: "lb.example.com"}, {IP: "148.142.120.1"}},
+		}},
+	}
+
+	var appCh = mainpkg.Start(ctx, conf, client, nil)
+	go func() {
+		defer watcher.Stop()
+		time.Sleep(time.Millisecond * 30)
+		watcher.Add(service.DeepCopy())
+		time.Sleep(time.Millisecond * 300)
+		service.Status.LoadBalancer.Ingress = []v1.LoadBalancerIngress{{IP: "148.142.120.2"}}
+		watcher.Modify(service.DeepCopy())
+	}()
+
+	require.Len(t, appCh, 0)
+
+	require.Eventually(t, func() bool {
+		return verifyIPmap(conf.OutputPath, map[string]string{
+			"10.96.0.10": "148.142.120.1",
+			"10.0.0.1":   "148.142.120.1",
+		}, false)
+	}, time.Second*2, time.Second/10)
+
+	require.Eventually(t, func() bool {
+		return verifyIPmap(conf.OutputPath, map[string]string{
+			"10.96.0.10": "148.142.120.2",
+			"10.0.0.1":   "148.142.120.2",
+		}, false)
+	}, time.Second*2, time.Second/10)
+}
+
+func Test_LoadBalancerServicesFollowNodes(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("k8s.io/klog/v2.(*loggingT).flushDaemon"))
+
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var conf = &mainpkg.Config{
+		OutputPath:            filepath.Join(t.TempDir(), "output.yaml"),
+		ConfigMapOnly:         true,
+		FromLoadBalancers:     true,
+		LoadBalancerNodePorts: true,
+	}
+
+	var client = fake.NewSimpleClientset(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status: v1.NodeStatus{
+			Addresses: []v1.NodeAddress{{Type: v1.NodeInternalIP, Address: "10.0.0.1"}},
+		},
+	}, &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "lb", Namespace: "default"},
+		Spec: v1.ServiceSpec{
+			Type:      v1.ServiceTypeLoadBalancer,
+			ClusterIP: "10.96.0.10",
+			Ports:     []v1.ServicePort{{Port: 443, NodePort: 30443}},
+		},
+		Status: v1.ServiceStatus{LoadBalancer: v1.LoadBalancerStatus{
+			Ingress: []v1.LoadBalancerIngress{{IP: "148.142.120.1"}},
+		}},
+	})
+
+	var appCh = mainpkg.Start(ctx, conf, client, nil)
+
+	require.Len(t, appCh, 0)
+	require.Eventually(t, func() bool {
+		return verifyIPmap(conf.OutputPath, map[string]string{"10.0.0.1": "148.142.120.1"}, false)
+	}, time.Second*2, time.Second/10)
+
+	_, err := client.CoreV1().Nodes().Create(ctx, &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-2"},
+		Status: v1.NodeStatus{
+			Addresses: []v1.NodeAddress{{Type: v1.NodeInternalIP, Address: "10.0.0.2"}},
+		},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, client.CoreV1().Nodes().Delete(ctx, "node-1", metav1.DeleteOptions{}))
+
+	require.Eventually(t, func() bool {
+		return verifyIPmap(conf.OutputPath, map[string]string{"10.0.0.2": "148.142.120.1"}, false) &&
+			!verifyIPmap(conf.OutputPath, map[string]string{"10.0.0.1": "148.142.120.1"}, false)
+	}, time.Second*2, time.Second/10)
+
+	var nodeLists int
+	for _, action := range client.Actions() {
+		if action.Matches("list", "nodes") {
+			nodeLists++
+		}
+	}
+	require.Equal(t, 1, nodeLists, "nodes are listed once by the informer")
+}
+
+func Test_Ingresses(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("k8s.io/klog/v2.(*loggingT).flushDaemon"))
+
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var conf = &mainpkg.Config{
+		OutputPath:    filepath.Join(t.TempDir(), "output.yaml"),
+		ConfigMapOnly: true,
+		FromIngresses: true,
+	}
+
+	var client = fake.NewSimpleClientset(&v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec:       v1.ServiceSpec{ClusterIP: "10.96.0.10"},
+	}, &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "default"},
+		Spec:       v1.ServiceSpec{ClusterIP: "10.96.0.11"},
+	})
+	watcher := watch.NewFake()
+	client.PrependWatchReactor("ingresses", k8stest.DefaultWatchReactor(watcher, nil))
+
+	var ingress = &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec: networkingv1.IngressSpec{
+			DefaultBackend: &networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{Name: "web"}},
+			Rules: []networkingv1.IngressRule{{IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+				Paths: []networkingv1.HTTPIngressPath{{Path: "/api", Backend: networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{Name: "api"}}}},
+			}}}},
+		},
+		Status: networkingv1.IngressStatus{LoadBalancer: v1.LoadBalancerStatus{
+			Ingress: []v1.LoadBalancerIngress{{IP: "148.142.120.1"}},
+		}},
+	}
+
+	var appCh = mainpkg.Start(ctx, conf, client, nil)
+	go func() {
+		defer watcher.Stop()
+		time.Sleep(time.Millisecond * 30)
+		watcher.Add(ingress.DeepCopy())
+		time.Sleep(time.Millisecond * 300)
+		ingress.Spec.Rules = nil
+		watcher.Modify(ingress.DeepCopy())
+	}()
+
+	require.Len(t, appCh, 0)
+
+	require.Eventually(t, func() bool {
+		return verifyIPmap(conf.OutputPath, map[string]string{
+			"10.96.0.10": "148.142.120.1",
+			"10.96.0.11": "148.142.120.1",
+		}, false)
+	}, time.Second*2, time.Second/10)
+
+	require.Eventually(t, func() bool {
+		return verifyIPmap(conf.OutputPath, map[string]string{"10.96.0.10": "148.142.120.1"}, false) &&
+			!verifyIPmap(conf.OutputPath, map[string]string{"10.96.0.11": "148.142.120.1"}, false)
+	}, time.Second*2, time.Second/10)
+}
+
+func Test_EndpointSlices(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("k8s.io/klog/v2.(*loggingT).flushDaemon"))
+
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var conf = &mainpkg.Config{
+		OutputPath:            filepath.Join(t.TempDir(), "output.yaml"),
+		Namespace:             "nsm",
+		ConfigMapOnly:         true,
+		EndpointSliceServices: "backend",
+	}
+
+	var nodeName = "node-1"
+	var client = fake.NewSimpleClientset(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: nodeName},
+		Status: v1.NodeStatus{
+			Addresses: []v1.NodeAddress{
+				{Type: v1.NodeInternalIP, Address: "10.0.0.1"},
+				{Type: v1.NodeExternalIP, Address: "148.142.120.1"},
+			},
+		},
+	})
+	watcher := watch.NewFake()
+	client.PrependWatchReactor("endpointslices", k8stest.DefaultWatchReactor(watcher, nil))
+
+	var slice = &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "backend-abc",
+			Namespace: "nsm",
+			Labels:    map[string]string{discoveryv1.LabelServiceName: "backend"},
+		},
+		AddressType: discoveryv1.AddressTypeIPv4,
+		Endpoints: []discoveryv1.Endpoint{
+			{Addresses: []string{"172.16.0.5"}, NodeName: &nodeName},
+			{Addresses: []string{"172.16.0.6"}},
+		},
+	}
+
+	var appCh = mainpkg.Start(ctx, conf, client, nil)
+	go func() {
+		defer watcher.Stop()
+		time.Sleep(time.Millisecond * 30)
+		watcher.Add(slice.DeepCopy())
+		time.Sleep(time.Millisecond * 300)
+		watcher.Delete(slice.DeepCopy())
+	}()
+
+	require.Len(t, appCh, 0)
+
+	require.Eventually(t, func() bool {
+		return verifyIPmap(conf.OutputPath, map[string]string{"172.16.0.5": "148.142.120.1"}, false)
+	}, time.Second*2, time.Second/10)
+
+	require.Eventually(t, func() bool {
+		return !verifyIPmap(conf.OutputPath, map[string]string{"172.16.0.5": "148.142.120.1"}, false)
+	}, time.Second*2, time.Second/10)
+}
+
+func Test_EndpointSlicesFollowNode(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("k8s.io/klog/v2.(*loggingT).flushDaemon"))
+
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var conf = &mainpkg.Config{
+		OutputPath:            filepath.Join(t.TempDir(), "output.yaml"),
+		Namespace:             "nsm",
+		ConfigMapOnly:         true,
+		EndpointSliceServices: "backend",
+	}
+
+	var nodeName = "node-1"
+	var node = &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: nodeName},
+		Status: v1.NodeStatus{
+			Addresses: []v1.NodeAddress{{Type: v1.NodeExternalIP, Address: "148.142.120.1"}},
+		},
+	}
+	var client = fake.NewSimpleClientset(node, &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "backend-abc",
+			Namespace: "nsm",
+			Labels:    map[string]string{discoveryv1.LabelServiceName: "backend"},
+		},
+		AddressType: discoveryv1.AddressTypeIPv4,
+		Endpoints:   []discoveryv1.Endpoint{{Addresses: []string{"172.16.0.5"}, NodeName: &nodeName}},
+	})
+
+	var appCh = mainpkg.Start(ctx, conf, client, nil)
+
+	require.Len(t, appCh, 0)
+	require.Eventually(t, func() bool {
+		return verifyIPmap(conf.OutputPath, map[string]string{"172.16.0.5": "148.142.120.1"}, false)
+	}, time.Second*2, time.Second/10)
+
+	node.ResourceVersion = "2"
+	node.Status.Addresses = []v1.NodeAddress{{Type: v1.NodeExternalIP, Address: "148.142.120.2"}}
+	_, err := client.CoreV1().Nodes().Update(ctx, node, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return verifyIPmap(conf.OutputPath, map[string]string{"172.16.0.5": "148.142.120.2"}, false)
+	}, time.Second*2, time.Second/10)
+}
+
+func Test_HostNetworkPods(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("k8s.io/klog/v2.(*loggingT).flushDaemon"))
+
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var conf = &mainpkg.Config{
+		OutputPath:      filepath.Join(t.TempDir(), "output.yaml"),
+		ConfigMapOnly:   true,
+		HostNetworkPods: "app=nsmgr",
+	}
+
+	var client = fake.NewSimpleClientset(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status: v1.NodeStatus{
+			Addresses: []v1.NodeAddress{
+				{Type: v1.NodeInternalIP, Address: "10.0.0.1"},
+				{Type: v1.NodeExternalIP, Address: "148.142.120.1"},
+			},
+		},
+	})
+	watcher := watch.NewFake()
+	client.PrependWatchReactor("pods", k8stest.DefaultWatchReactor(watcher, nil))
+
+	var pod = &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "nsmgr-abc", Namespace: "nsm", Labels: map[string]string{"app": "nsmgr"}},
+		Spec:       v1.PodSpec{HostNetwork: true, NodeName: "node-1"},
+		Status:     v1.PodStatus{PodIP: "10.0.0.100"},
+	}
+
+	var appCh = mainpkg.Start(ctx, conf, client, nil)
+	go func() {
+		defer watcher.Stop()
+		time.Sleep(time.Millisecond * 30)
+		watcher.Add(pod.DeepCopy())
+		time.Sleep(time.Millisecond * 300)
+		pod.Spec.HostNetwork = false
+		watcher.Modify(pod.DeepCopy())
+	}()
+
+	require.Len(t, appCh, 0)
+
+	require.Eventually(t, func() bool {
+		return verifyIPmap(conf.OutputPath, map[string]string{"10.0.0.100": "148.142.120.1"}, false)
+	}, time.Second*2, time.Second/10)
+
+	require.Eventually(t, func() bool {
+		return !verifyIPmap(conf.OutputPath, map[string]string{"10.0.0.100": "148.142.120.1"}, false)
+	}, time.Second*2, time.Second/10)
+}
+
+func Test_HostNetworkPodsFollowNode(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("k8s.io/klog/v2.(*loggingT).flushDaemon"))
+
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var conf = &mainpkg.Config{
+		OutputPath:      filepath.Join(t.TempDir(), "output.yaml"),
+		ConfigMapOnly:   true,
+		HostNetworkPods: "app=nsmgr",
+	}
+
+	var node = &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status: v1.NodeStatus{
+			Addresses: []v1.NodeAddress{{Type: v1.NodeExternalIP, Address: "148.142.120.1"}},
+		},
+	}
+	var client = fake.NewSimpleClientset(node, &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "nsmgr-abc", Namespace: "nsm", Labels: map[string]string{"app": "nsmgr"}},
+		Spec:       v1.PodSpec{HostNetwork: true, NodeName: "node-1"},
+		Status:     v1.PodStatus{PodIP: "10.0.0.100"},
+	})
+
+	var appCh = mainpkg.Start(ctx, conf, client, nil)
+
+	require.Len(t, appCh, 0)
+	require.Eventually(t, func() bool {
+		return verifyIPmap(conf.OutputPath, map[string]string{"10.0.0.100": "148.142.120.1"}, false)
+	}, time.Second*2, time.Second/10)
+
+	node.ResourceVersion = "2"
+	node.Status.Addresses = []v1.NodeAddress{{Type: v1.NodeExternalIP, Address: "148.142.120.2"}}
+	_, err := client.CoreV1().Nodes().Update(ctx, node, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return verifyIPmap(conf.OutputPath, map[string]string{"10.0.0.100": "148.142.120.2"}, false)
+	}, time.Second*2, time.Second/10)
+
+	for _, action := range client.Actions() {
+		require.False(t, action.Matches("get", "nodes"), "nodes are read from the cache of the informer")
+	}
+}
+
+func Test_MultusPods(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("k8s.io/klog/v2.(*loggingT).flushDaemon"))
+
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var conf = &mainpkg.Config{
+		OutputPath:    filepath.Join(t.TempDir(), "output.yaml"),
+		ConfigMapOnly: true,
+		MultusPods:    "app=nse",
+	}
+
+	var client = fake.NewSimpleClientset(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status: v1.NodeStatus{
+			Addresses: []v1.NodeAddress{
+				{Type: v1.NodeInternalIP, Address: "10.0.0.1"},
+				{Type: v1.NodeExternalIP, Address: "148.142.120.1"},
+			},
+		},
+	})
+	watcher := watch.NewFake()
+	client.PrependWatchReactor("pods", k8stest.DefaultWatchReactor(watcher, nil))
+
+	var pod = &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "nse-abc",
+			Namespace: "nsm",
+			Labels:    map[string]string{"app": "nse"},
+			Annotations: map[string]string{
+				"k8s.v1.cni.cncf.io/network-status": `[{"name":"kindnet","ips":["10.244.1.5"],"default":true},` +
+					`{"name":"default/macvlan","interface":"net1","ips":["192.168.10.5"]}]`,
+			},
+		},
+		Spec: v1.PodSpec{NodeName: "node-1"},
+	}
+
+	var appCh = mainpkg.Start(ctx, conf, client, nil)
+	go func() {
+		defer watcher.Stop()
+		time.Sleep(time.Millisecond * 30)
+		watcher.Add(pod.DeepCopy())
+		time.Sleep(time.Millisecond * 300)
+		watcher.Delete(pod.DeepCopy())
+	}()
+
+	require.Len(t, appCh, 0)
+
+	require.Eventually(t, func() bool {
+		return verifyIPmap(conf.OutputPath, map[string]string{"192.168.10.5": "148.142.120.1"}, false)
+	}, time.Second*2, time.Second/10)
+
+	require.Eventually(t, func() bool {
+		return !verifyIPmap(conf.OutputPath, map[string]string{"192.168.10.5": "148.142.120.1"}, false)
+	}, time.Second*2, time.Second/10)
+}
+
+func Test_MultipleConfigMaps(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("k8s.io/klog/v2.(*loggingT).flushDaemon"))
+
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var conf = &mainpkg.Config{
+		OutputPath:    filepath.Join(t.TempDir(), "output.yaml"),
+		FromConfigMap: "team-a, team-b",
+		Namespace:     "nsm",
+		ConfigMapOnly: true,
+	}
+
+	var teamA = &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "team-a", Namespace: "nsm"},
+		Data:       map[string]string{"config.yaml": "1.1.1.1: 2.1.1.1\n1.1.1.2: 2.1.1.2"},
+	}
+	var teamB = &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "team-b", Namespace: "nsm"},
+		Data:       map[string]string{"config.yaml": "1.1.1.2: 2.1.1.2\n1.1.1.3: 2.1.1.3"},
+	}
+	var client = fake.NewSimpleClientset(teamA, teamB)
+
+	var appCh = mainpkg.Start(ctx, conf, client, nil)
+	go func() {
+		time.Sleep(time.Millisecond * 300)
+		_ = client.CoreV1().ConfigMaps("nsm").Delete(ctx, teamB.Name, metav1.DeleteOptions{})
+	}()
+
+	require.Len(t, appCh, 0)
+
+	require.Eventually(t, func() bool {
+		return verifyIPmap(conf.OutputPath, map[string]string{
+			"1.1.1.1": "2.1.1.1",
+			"1.1.1.2": "2.1.1.2",
+			"1.1.1.3": "2.1.1.3",
+		}, false)
+	}, time.Second*2, time.Second/10)
+
+	require.Eventually(t, func() bool {
+		return verifyIPmap(conf.OutputPath, map[string]string{
+			"1.1.1.1": "2.1.1.1",
+			"1.1.1.2": "2.1.1.2",
+		}, false) && !verifyIPmap(conf.OutputPath, map[string]string{"1.1.1.3": "2.1.1.3"}, false)
+	}, time.Second*2, time.Second/10)
+}
+
+func Test_ReconcileInterval(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("k8s.io/klog/v2.(*loggingT).flushDaemon"))
+
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var conf = &mainpkg.Config{
+		OutputPath:        filepath.Join(t.TempDir(), "output.yaml"),
+		FromConfigMap:     "config",
+		Namespace:         "nsm",
+		ConfigMapOnly:     true,
+		ReconcileInterval: time.Millisecond * 100,
+	}
+
+	var client = fake.NewSimpleClientset(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "config", Namespace: "nsm"},
+		Data:       map[string]string{"config.yaml": "1.1.1.1: 2.1.1.1"},
+	})
+	// the watch never delivers events, so the deletion of the configmap is missed
+	var watcher = watch.NewFake()
+	defer watcher.Stop()
+	client.PrependWatchReactor("configmaps", k8stest.DefaultWatchReactor(watcher, nil))
+
+	var appCh = mainpkg.Start(ctx, conf, client, nil)
+
+	require.Len(t, appCh, 0)
+
+	require.Eventually(t, func() bool {
+		return verifyIPmap(conf.OutputPath, map[string]string{"1.1.1.1": "2.1.1.1"}, false)
+	}, time.Second*2, time.Second/10)
+
+	require.NoError(t, client.CoreV1().ConfigMaps("nsm").Delete(ctx, "config", metav1.DeleteOptions{}))
+
+	require.Eventually(t, func() bool {
+		return !verifyIPmap(conf.OutputPath, map[string]string{"1.1.1.1": "2.1.1.1"}, false)
+	}, time.Second*2, time.Second/10)
+}
+
+func Test_StaticMappings(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("k8s.io/klog/v2.(*loggingT).flushDaemon"))
+
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var conf = &mainpkg.Config{
+		OutputPath:     filepath.Join(t.TempDir(), "output.yaml"),
+		FromConfigMap:  "team-a",
+		Namespace:      "nsm",
+		ConfigMapOnly:  true,
+		StaticMappings: "1.1.1.1=203.0.113.1, 10.0.0.1=203.0.113.2",
+	}
+
+	var client = fake.NewSimpleClientset(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "team-a", Namespace: "nsm"},
+		Data:       map[string]string{"config.yaml": "1.1.1.1: 2.1.1.1\n1.1.1.2: 2.1.1.2"},
+	})
+
+	var appCh = mainpkg.Start(ctx, conf, client, nil)
+
+	require.Len(t, appCh, 0)
+
+	require.Eventually(t, func() bool {
+		return verifyIPmap(conf.OutputPath, map[string]string{
+			"1.1.1.1":  "203.0.113.1",
+			"1.1.1.2":  "2.1.1.2",
+			"10.0.0.1": "203.0.113.2",
+		}, false)
+	}, time.Second*2, time.Second/10)
+
+	// #nosec
+	b, err := os.ReadFile(conf.OutputPath)
+	require.NoError(t, err)
+	require.NotContains(t, string(b), "2.1.1.1")
+}
+
+func Test_FromFiles(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("k8s.io/klog/v2.(*loggingT).flushDaemon"))
+
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var dir = t.TempDir()
+	var conf = &mainpkg.Config{
+		OutputPath:    filepath.Join(t.TempDir(), "output.yaml"),
+		FromFiles:     filepath.Join(dir, "mappings.yaml"),
+		ConfigMapOnly: true,
+	}
+	require.NoError(t, os.WriteFile(conf.FromFiles, []byte("1.1.1.1: 2.1.1.1\n1.1.1.2: 2.1.1.2"), 0o600))
+
+	var appCh = mainpkg.Start(ctx, conf, fake.NewSimpleClientset(), nil)
+
+	require.Len(t, appCh, 0)
+
+	require.Eventually(t, func() bool {
+		return verifyIPmap(conf.OutputPath, map[string]string{
+			"1.1.1.1": "2.1.1.1",
+			"1.1.1.2": "2.1.1.2",
+		}, false)
+	}, time.Second*2, time.Second/10)
+
+	// the file is replaced by rename as projected volumes and editors do
+	var tmp = filepath.Join(dir, "mappings.yaml.tmp")
+	require.NoError(t, os.WriteFile(tmp, []byte("1.1.1.1: 2.1.1.3"), 0o600))
+	require.NoError(t, os.Rename(tmp, conf.FromFiles))
+
+	require.Eventually(t, func() bool {
+		return verifyIPmap(conf.OutputPath, map[string]string{"1.1.1.1": "2.1.1.3"}, false) &&
+			!verifyIPmap(conf.OutputPath, map[string]string{"1.1.1.2": "2.1.1.2"}, false)
+	}, time.Second*2, time.Second/10)
+
+	require.NoError(t, os.Remove(conf.FromFiles))
+
+	require.Eventually(t, func() bool {
+		return !verifyIPmap(conf.OutputPath, map[string]string{"1.1.1.1": "2.1.1.3"}, false)
+	}, time.Second*2, time.Second/10)
+}
+
+func Test_IPTranslations(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("k8s.io/klog/v2.(*loggingT).flushDaemon"))
+
+	var created = time.Now().UTC().Format(time.RFC3339)
+	var deleteCh = make(chan struct{})
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/apis/nsm.io/v1alpha1/namespaces/nsm/iptranslations", r.URL.Path)
+		if r.URL.Query().Get("watch") != "true" {
+			_, _ = w.Write([]byte(`{"apiVersion":"nsm.io/v1alpha1","kind":"IPTranslationList","items":[
+				{"metadata":{"name":"a","namespace":"nsm"},"spec":{"from":"1.1.1.1","to":"2.1.1.1"}},
+				{"metadata":{"name":"b","namespace":"nsm"},"spec":{"from":"1.1.1.1","to":"2.1.1.2","priority":10}},
+				{"metadata":{"name":"c","namespace":"nsm","creationTimestamp":"` + created + `"},"spec":{"from":"1.1.1.3","to":"2.1.1.3","ttl":"2s"}}]}`))
+			return
+		}
+		w.(http.Flusher).Flush()
+		select {
+		case <-deleteCh:
+			_, _ = w.Write([]byte(`{"type":"DELETED","object":{"apiVersion":"nsm.io/v1alpha1","kind":"IPTranslation","metadata":{"name":"b","namespace":"nsm"},"spec":{"from":"1.1.1.1","to":"2.1.1.2","priority":10}}}` + "\n"))
+			w.(http.Flusher).Flush()
+		case <-r.Context().Done():
+			return
+		}
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	// the context is canceled before the server is closed to finish the watch
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var conf = &mainpkg.Config{
+		OutputPath:         filepath.Join(t.TempDir(), "output.yaml"),
+		Namespace:          "nsm",
+		ConfigMapOnly:      true,
+		FromIPTranslations: true,
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(&rest.Config{Host: server.URL})
+	require.NoError(t, err)
+
+	var appCh = mainpkg.Start(ctx, conf, fake.NewSimpleClientset(), dynamicClient)
+
+	require.Len(t, appCh, 0)
+
+	require.Eventually(t, func() bool {
+		return verifyIPmap(conf.OutputPath, map[string]string{
+			"1.1.1.1": "2.1.1.2",
+			"1.1.1.3": "2.1.1.3",
+		}, false)
+	}, time.Second*2, time.Second/10)
+
+	close(deleteCh)
+
+	require.Eventually(t, func() bool {
+		return verifyIPmap(conf.OutputPath, map[string]string{"1.1.1.1": "2.1.1.1"}, false)
+	}, time.Second*2, time.Second/10)
+
+	// c expires in 2 seconds since its creation
+	require.Eventually(t, func() bool {
+		return !verifyIPmap(conf.OutputPath, map[string]string{"1.1.1.3": "2.1.1.3"}, false)
+	}, time.Second*4, time.Second/10)
+}
+
+func Test_MetalLB(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("k8s.io/klog/v2.(*loggingT).flushDaemon"))
+
+	var failoverCh = make(chan struct{})
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/apis/metallb.io/v1beta1/namespaces/metallb-system/servicel2statuses", r.URL.Path)
+		if r.URL.Query().Get("watch") != "true" {
+			_, _ = w.Write([]byte(`{"apiVersion":"metallb.io/v1beta1","kind":"ServiceL2StatusList","items":[{"metadata":{"name":"l2-web","namespace":"metallb-system"},
+				"status":{"node":"node-1","serviceName":"web","serviceNamespace":"default"}}]}`))
+			return
+		}
+		w.(http.Flusher).Flush()
+		select {
+		case <-failoverCh:
+			_, _ = w.Write([]byte(`{"type":"MODIFIED","object":{"apiVersion":"metallb.io/v1beta1","kind":"ServiceL2Status","metadata":{"name":"l2-web","namespace":"metallb-system"},` +
+				`"status":{"node":"node-2","serviceName":"web","serviceNamespace":"default"}}}` + "\n"))
+			w.(http.Flusher).Flush()
+		case <-r.Context().Done():
+			return
+		}
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	// the context is canceled before the server is closed to finish the watch
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var conf = &mainpkg.Config{
+		OutputPath:       filepath.Join(t.TempDir(), "output.yaml"),
+		ConfigMapOnly:    true,
+		FromMetalLB:      true,
+		MetalLBNamespace: "metallb-system",
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(&rest.Config{Host: server.URL})
+	require.NoError(t, err)
+	var client = fake.NewSimpleClientset(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status:     v1.NodeStatus{Addresses: []v1.NodeAddress{{Type: v1.NodeInternalIP, Address: "10.0.0.1"}}},
+	}, &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-2"},
+		Status:     v1.NodeStatus{Addresses: []v1.NodeAddress{{Type: v1.NodeInternalIP, Address: "10.0.0.2"}}},
+	}, &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec:       v1.ServiceSpec{Type: v1.ServiceTypeLoadBalancer, ClusterIP: "10.96.0.10"},
+		Status: v1.ServiceStatus{LoadBalancer: v1.LoadBalancerStatus{
+			Ingress: []v1.LoadBalancerIngress{{IP: "192.168.1.240"}},
+		}},
+	})
+
+	var appCh = mainpkg.Start(ctx, conf, client, dynamicClient)
+
+	require.Len(t, appCh, 0)
+
+	require.Eventually(t, func() bool {
+		return verifyIPmap(conf.OutputPath, map[string]string{"10.0.0.1": "192.168.1.240"}, false)
+	}, time.Second*2, time.Second/10)
+
+	close(failoverCh)
+
+	require.Eventually(t, func() bool {
+		return verifyIPmap(conf.OutputPath, map[string]string{"10.0.0.2": "192.168.1.240"}, false) &&
+			!verifyIPmap(conf.OutputPath, map[string]string{"10.0.0.1": "192.168.1.240"}, false)
+	}, time.Second*2, time.Second/10)
+}
+
+func Test_GatewayAPI(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("k8s.io/klog/v2.(*loggingT).flushDaemon"))
+
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/apis/gateway.networking.k8s.io/v1/gateways", r.URL.Path)
+		if r.URL.Query().Get("watch") != "true" {
+			_, _ = w.Write([]byte(`{"apiVersion":"gateway.networking.k8s.io/v1","kind":"GatewayList","items":[{"metadata":{"name":"nsm","namespace":"default"},"status":{"addresses":[
+				{"type":"Hostname","value":"gw.example.com"},{"type":"IPAddress","value":"148.142.120.1"},{"value":"2001:db8::1"}]}}]}`))
+			return
+		}
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	// the context is canceled before the server is closed to finish the watch
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var conf = &mainpkg.Config{
+		OutputPath:     filepath.Join(t.TempDir(), "output.yaml"),
+		ConfigMapOnly:  true,
+		FromGatewayAPI: true,
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(&rest.Config{Host: server.URL})
+	require.NoError(t, err)
+	var client = fake.NewSimpleClientset(&v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "nsm-istio",
+			Namespace: "default",
+			Labels:    map[string]string{"gateway.networking.k8s.io/gateway-name": "nsm"},
+		},
+		Spec: v1.ServiceSpec{ClusterIP: "10.96.0.10", ClusterIPs: []string{"10.96.0.10", "fd00::10"}},
+	}, &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"},
+		Spec:       v1.ServiceSpec{ClusterIP: "10.96.0.11"},
+	})
+
+	var appCh = mainpkg.Start(ctx, conf, client, dynamicClient)
+
+	require.Len(t, appCh, 0)
+
+	require.Eventually(t, func() bool {
+		return verifyIPmap(conf.OutputPath, map[string]string{
+			"10.96.0.10": "148.142.120.1",
+			"fd00::10":   "2001:db8::1",
+		}, false) && !verifyIPmap(conf.OutputPath, map[string]string{"10.96.0.11": "148.142.120.1"}, false)
+	}, time.Second*2, time.Second/10)
+}
+
+func Test_RemoteKubeconfigs(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("k8s.io/klog/v2.(*loggingT).flushDaemon"))
+
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/api/v1/nodes", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("watch") != "true" {
+			_, _ = w.Write([]byte(`{"kind":"NodeList","apiVersion":"v1","items":[{"metadata":{"name":"node-1"},"status":{"addresses":[
+				{"type":"InternalIP","address":"10.1.0.1"},{"type":"ExternalIP","address":"148.142.121.1"}]}}]}`))
+			return
+		}
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	// the context is canceled before the server is closed to finish the watch
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var dir = t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cluster-2.yaml"), []byte(`apiVersion: v1
+kind: Config
+clusters:
+- name: cluster-2
+  cluster:
+    server: `+server.URL+`
+contexts:
+- name: cluster-2
+  context:
+    cluster: cluster-2
+current-context: cluster-2
+`), 0o600))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "..data"), 0o700))
+
+	var conf = &mainpkg.Config{
+		OutputPath:        filepath.Join(t.TempDir(), "output.yaml"),
+		ConfigMapOnly:     true,
+		RemoteKubeconfigs: dir,
+		ExtendedOutput:    true,
+	}
+
+	var appCh = mainpkg.Start(ctx, conf, fake.NewSimpleClientset(), nil)
+
+	require.Len(t, appCh, 0)
+
+	require.Eventually(t, func() bool {
+		// #nosec
+		b, err := os.ReadFile(conf.OutputPath)
+		if err != nil {
+			return false
+		}
+		var m map[string]struct {
+			To      string `yaml:"to"`
+			Cluster string `yaml:"cluster"`
+		}
+		return yaml.Unmarshal(b, &m) == nil && m["10.1.0.1"].To == "148.142.121.1" && m["10.1.0.1"].Cluster == "cluster-2"
+	}, time.Second*2, time.Second/10)
+}
+
+func Test_ClusterAPIMachines(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("k8s.io/klog/v2.(*loggingT).flushDaemon"))
+
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/apis/cluster.x-k8s.io/v1beta1/namespaces/capi/machines", r.URL.Path)
+		if r.URL.Query().Get("watch") != "true" {
+			_, _ = w.Write([]byte(`{"apiVersion":"cluster.x-k8s.io/v1beta1","kind":"MachineList","items":[{"metadata":{"name":"md-0-abcde","namespace":"capi"},"status":{"addresses":[
+				{"type":"Hostname","address":"md-0-abcde"},{"type":"InternalIP","address":"10.0.0.5"},{"type":"ExternalIP","address":"148.142.120.5"}]}}]}`))
+			return
+		}
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	// the context is canceled before the server is closed to finish the watch
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var conf = &mainpkg.Config{
+		OutputPath:          filepath.Join(t.TempDir(), "output.yaml"),
+		ConfigMapOnly:       true,
+		FromClusterAPI:      true,
+		ClusterAPINamespace: "capi",
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(&rest.Config{Host: server.URL})
+	require.NoError(t, err)
+
+	var appCh = mainpkg.Start(ctx, conf, fake.NewSimpleClientset(), dynamicClient)
+
+	require.Len(t, appCh, 0)
+
+	require.Eventually(t, func() bool {
+		return verifyIPmap(conf.OutputPath, map[string]string{"10.0.0.5": "148.142.120.5"}, true)
+	}, time.Second*2, time.Second/10)
+}
+
+func Test_KubeVirt(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("k8s.io/klog/v2.(*loggingT).flushDaemon"))
+
+	var migrateCh = make(chan struct{})
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/apis/kubevirt.io/v1/virtualmachineinstances", r.URL.Path)
+		if r.URL.Query().Get("watch") != "true" {
+			_, _ = w.Write([]byte(`{"apiVersion":"kubevirt.io/v1","kind":"VirtualMachineInstanceList","items":[{"metadata":{"name":"vm","namespace":"default"},"status":{"nodeName":"node-1",
+				"interfaces":[{"name":"default","ipAddress":"10.244.1.5","ipAddresses":["10.244.1.5"]}]}}]}`))
+			return
+		}
+		w.(http.Flusher).Flush()
+		select {
+		case <-migrateCh:
+			_, _ = w.Write([]byte(`{"type":"MODIFIED","object":{"apiVersion":"kubevirt.io/v1","kind":"VirtualMachineInstance","metadata":{"name":"vm","namespace":"default"},"status":{"nodeName":"node-2",` +
+				`"interfaces":[{"name":"default","ipAddress":"10.244.1.5"}]}}}` + "\n"))
+			w.(http.Flusher).Flush()
+		case <-r.Context().Done():
+			return
+		}
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	// the context is canceled before the server is closed to finish the watch
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var conf = &mainpkg.Config{
+		OutputPath:    filepath.Join(t.TempDir(), "output.yaml"),
+		ConfigMapOnly: true,
+		FromKubeVirt:  true,
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(&rest.Config{Host: server.URL})
+	require.NoError(t, err)
+	var client = fake.NewSimpleClientset(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status:     v1.NodeStatus{Addresses: []v1.NodeAddress{{Type: v1.NodeExternalIP, Address: "148.142.120.1"}}},
+	}, &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-2"},
+		Status:     v1.NodeStatus{Addresses: []v1.NodeAddress{{Type: v1.NodeExternalIP, Address: "148.142.120.2"}}},
+	})
+
+	var appCh = mainpkg.Start(ctx, conf, client, dynamicClient)
+
+	require.Len(t, appCh, 0)
+
+	require.Eventually(t, func() bool {
+		return verifyIPmap(conf.OutputPath, map[string]string{"10.244.1.5": "148.142.120.1"}, false)
+	}, time.Second*2, time.Second/10)
+
+	close(migrateCh)
+
+	require.Eventually(t, func() bool {
+		return verifyIPmap(conf.OutputPath, map[string]string{"10.244.1.5": "148.142.120.2"}, false)
+	}, time.Second*2, time.Second/10)
+}
+
+func Test_CiliumNodes(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("k8s.io/klog/v2.(*loggingT).flushDaemon"))
+
+	var server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/apis/cilium.io/v2/ciliumnodes", r.URL.Path)
+		if r.URL.Query().Get("watch") != "true" {
+			_, _ = w.Write([]byte(`{"apiVersion":"cilium.io/v2","kind":"CiliumNodeList","items":[{"metadata":{"name":"node-1"},"spec":{"addresses":[
+				{"type":"InternalIP","ip":"10.0.0.1"},{"type":"CiliumInternalIP","ip":"10.244.0.1"},{"type":"ExternalIP","ip":"148.142.120.1"}]}}]}`))
+			return
+		}
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	// the context is canceled before the server is closed to finish the watch
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var conf = &mainpkg.Config{
+		OutputPath:      filepath.Join(t.TempDir(), "output.yaml"),
+		ConfigMapOnly:   true,
+		FromCiliumNodes: true,
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(&rest.Config{Host: server.URL})
+	require.NoError(t, err)
+
+	var appCh = mainpkg.Start(ctx, conf, fake.NewSimpleClientset(), dynamicClient)
+
+	require.Len(t, appCh, 0)
+
+	require.Eventually(t, func() bool {
+		return verifyIPmap(conf.OutputPath, map[string]string{"10.0.0.1": "148.142.120.1"}, true) &&
+			!verifyIPmap(conf.OutputPath, map[string]string{"10.244.0.1": "148.142.120.1"}, false)
+	}, time.Second*2, time.Second/10)
+}
+
+func Test_ConfigMapSelector(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("k8s.io/klog/v2.(*loggingT).flushDaemon"))
+
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var conf = &mainpkg.Config{
+		OutputPath:            filepath.Join(t.TempDir(), "output.yaml"),
+		FromConfigMapSelector: "app=nsm-ipmap",
+		Namespace:             "nsm",
+		ConfigMapOnly:         true,
+	}
+
+	var labels = map[string]string{"app": "nsm-ipmap"}
+	var client = fake.NewSimpleClientset(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "existing", Namespace: "nsm", Labels: labels},
+		Data:       map[string]string{"config.yaml": "1.1.1.1: 2.1.1.1"},
+	}, &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "unlabeled", Namespace: "nsm"},
+		Data:       map[string]string{"config.yaml": "1.1.1.9: 2.1.1.9"},
+	})
+	watcher := watch.NewFake()
+	client.PrependWatchReactor("configmaps", k8stest.DefaultWatchReactor(watcher, nil))
+
+	var appCh = mainpkg.Start(ctx, conf, client, nil)
+	go func() {
+		defer watcher.Stop()
+		time.Sleep(time.Millisecond * 300)
+		watcher.Add(&v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "created", Namespace: "nsm", Labels: labels},
+			Data:       map[string]string{"config.yaml": "1.1.1.2: 2.1.1.2"},
+		})
+	}()
+
+	require.Len(t, appCh, 0)
+
+	require.Eventually(t, func() bool {
+		return verifyIPmap(conf.OutputPath, map[string]string{
+			"1.1.1.1": "2.1.1.1",
+			"1.1.1.2": "2.1.1.2",
+		}, false) && !verifyIPmap(conf.OutputPath, map[string]string{"1.1.1.9": "2.1.1.9"}, false)
+	}, time.Second*2, time.Second/10)
+}
+
+func Test_ConfigMapAllNamespaces(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("k8s.io/klog/v2.(*loggingT).flushDaemon"))
+
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var conf = &mainpkg.Config{
+		OutputPath:            filepath.Join(t.TempDir(), "output.yaml"),
+		FromConfigMapSelector: "app=nsm-ipmap",
+		FromAllNamespaces:     true,
+		Namespace:             "nsm",
+		ConfigMapOnly:         true,
+		ExtendedOutput:        true,
+	}
+
+	var labels = map[string]string{"app": "nsm-ipmap"}
+	var teamB = &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "ipmap", Namespace: "team-b", Labels: labels},
+		Data:       map[string]string{"config.yaml": "1.1.1.2: 2.1.1.2"},
+	}
+	var client = fake.NewSimpleClientset(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "ipmap", Namespace: "team-a", Labels: labels},
+		Data:       map[string]string{"config.yaml": "1.1.1.1: 2.1.1.1"},
+	}, teamB)
+	watcher := watch.NewFake()
+	client.PrependWatchReactor("configmaps", k8stest.DefaultWatchReactor(watcher, nil))
+
+	var appCh = mainpkg.Start(ctx, conf, client, nil)
+	go func() {
+		defer watcher.Stop()
+		time.Sleep(time.Millisecond * 300)
+		watcher.Delete(teamB.DeepCopy())
+	}()
+
+	require.Len(t, appCh, 0)
+
+	var readOutput = func() map[string]map[string]string {
+		var m map[string]map[string]string
+		// #nosec
+		b, err := os.ReadFile(conf.OutputPath)
+		if err != nil || yaml.Unmarshal(b, &m) != nil {
+			return nil
+		}
+		return m
+	}
+
+	require.Eventually(t, func() bool {
+		return reflect.DeepEqual(readOutput(), map[string]map[string]string{
+			"1.1.1.1": {"to": "2.1.1.1", "namespace": "team-a"},
+			"1.1.1.2": {"to": "2.1.1.2", "namespace": "team-b"},
+		})
+	}, time.Second*2, time.Second/10)
+
+	require.Eventually(t, func() bool {
+		return reflect.DeepEqual(readOutput(), map[string]map[string]string{
+			"1.1.1.1": {"to": "2.1.1.1", "namespace": "team-a"},
+		})
+	}, time.Second*2, time.Second/10)
+}
+
+func Test_NodeResolveDNS(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("k8s.io/klog/v2.(*loggingT).flushDaemon"))
+
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var conf = &mainpkg.Config{
+		OutputPath:     filepath.Join(t.TempDir(), "output.yaml"),
+		NodeResolveDNS: true,
+	}
+
+	var client = fake.NewSimpleClientset(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status: v1.NodeStatus{
+			Addresses: []v1.NodeAddress{
+				{Type: v1.NodeInternalIP, Address: "10.0.0.1"},
+				{Type: v1.NodeExternalDNS, Address: "localhost"},
+			},
+		},
+	}, &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-2"},
+		Status: v1.NodeStatus{
+			Addresses: []v1.NodeAddress{
+				{Type: v1.NodeInternalIP, Address: "10.0.0.2"},
+				{Type: v1.NodeExternalIP, Address: "148.142.120.2"},
+				{Type: v1.NodeExternalDNS, Address: "localhost"},
+			},
+		},
+	})
+
+	var appCh = mainpkg.Start(ctx, conf, client, nil)
+
+	require.Len(t, appCh, 0)
+
+	require.Eventually(t, func() bool {
+		return verifyIPmap(conf.OutputPath, map[string]string{
+			"10.0.0.1": "127.0.0.1",
+			"10.0.0.2": "148.142.120.2",
+		}, true)
+	}, time.Second*2, time.Second/10)
+}
+
+func Test_NodeCalicoAddresses(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("k8s.io/klog/v2.(*loggingT).flushDaemon"))
+
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var conf = &mainpkg.Config{
+		OutputPath:          filepath.Join(t.TempDir(), "output.yaml"),
+		NodeCalicoAddresses: true,
+	}
+
+	var client = fake.NewSimpleClientset(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "node-1",
+			Annotations: map[string]string{
+				"projectcalico.org/IPv4Address": "10.0.0.1/24",
+				"projectcalico.org/IPv6Address": "fd00::1/64",
+			},
+		},
+		Status: v1.NodeStatus{
+			Addresses: []v1.NodeAddress{
+				{Type: v1.NodeExternalIP, Address: "148.142.120.1"},
+				{Type: v1.NodeExternalIP, Address: "2001:db8::1"},
+			},
+		},
+	}, &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "node-2",
+			Annotations: map[string]string{"projectcalico.org/IPv4Address": "10.0.0.99/24"},
+		},
+		Status: v1.NodeStatus{
+			Addresses: []v1.NodeAddress{
+				{Type: v1.NodeInternalIP, Address: "10.0.0.2"},
+				{Type: v1.NodeExternalIP, Address: "148.142.120.2"},
+			},
+		},
+	})
+
+	var appCh = mainpkg.Start(ctx, conf, client, nil)
+
+	require.Len(t, appCh, 0)
+
+	require.Eventually(t, func() bool {
+		return verifyIPmap(conf.OutputPath, map[string]string{
+			"10.0.0.1": "148.142.120.1",
+			"fd00::1":  "2001:db8::1",
+			"10.0.0.2": "148.142.120.2",
+		}, true) && !verifyIPmap(conf.OutputPath, map[string]string{"10.0.0.99": "148.142.120.2"}, false)
+	}, time.Second*2, time.Second/10)
+}
+
+func Test_NodeKubeOVNAddresses(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("k8s.io/klog/v2.(*loggingT).flushDaemon"))
+
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var conf = &mainpkg.Config{
+		OutputPath:           filepath.Join(t.TempDir(), "output.yaml"),
+		NodeKubeOVNAddresses: true,
+		KubeOVNAnnotations:   "ovn.kubernetes.io/ip_address",
+	}
+
+	var client = fake.NewSimpleClientset(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "node-1",
+			Annotations: map[string]string{"ovn.kubernetes.io/ip_address": "100.64.0.2,fd00:100:64::2"},
+		},
+		Status: v1.NodeStatus{
+			Addresses: []v1.NodeAddress{
+				{Type: v1.NodeInternalIP, Address: "10.0.0.1"},
+				{Type: v1.NodeExternalIP, Address: "148.142.120.1"},
+				{Type: v1.NodeExternalIP, Address: "2001:db8::1"},
+			},
+		},
+	})
+
+	var appCh = mainpkg.Start(ctx, conf, client, nil)
+
+	require.Len(t, appCh, 0)
+
+	require.Eventually(t, func() bool {
+		return verifyIPmap(conf.OutputPath, map[string]string{
+			"10.0.0.1":       "148.142.120.1",
+			"100.64.0.2":     "148.142.120.1",
+			"fd00:100:64::2": "2001:db8::1",
+		}, true)
+	}, time.Second*2, time.Second/10)
+}
+
+func Test_NodeSelector(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("k8s.io/klog/v2.(*loggingT).flushDaemon"))
+
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var conf = &mainpkg.Config{
+		OutputPath:   filepath.Join(t.TempDir(), "output.yaml"),
+		NodeSelector: "nsm.io/enabled=true",
+	}
+
+	var newNode = func(name, internal, external string, labels map[string]string) *v1.Node {
+		return &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+			Status: v1.NodeStatus{
+				Addresses: []v1.NodeAddress{
+					{Type: v1.NodeInternalIP, Address: internal},
+					{Type: v1.NodeExternalIP, Address: external},
+				},
+			},
+		}
+	}
+	var client = fake.NewSimpleClientset(
+		newNode("node-1", "1.1.1.1", "2.1.1.1", map[string]string{"nsm.io/enabled": "true"}),
+		newNode("node-2", "1.1.1.2", "2.1.1.2", nil),
+	)
+
+	var appCh = mainpkg.Start(ctx, conf, client, nil)
+
+	require.Len(t, appCh, 0)
+
+	require.Eventually(t, func() bool {
+		return verifyIPmap(conf.OutputPath, map[string]string{"1.1.1.1": "2.1.1.1"}, true)
+	}, time.Second*2, time.Second/10)
+
+	require.False(t, verifyIPmap(conf.OutputPath, map[string]string{"1.1.1.2": "2.1.1.2"}, false))
+}
+
+func Test_NodeOwnOnly(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("k8s.io/klog/v2.(*loggingT).flushDaemon"))
+
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var conf = &mainpkg.Config{
+		OutputPath:  filepath.Join(t.TempDir(), "output.yaml"),
+		NodeName:    "node-1",
+		NodeOwnOnly: true,
+	}
+
+	var client = fake.NewSimpleClientset(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status: v1.NodeStatus{
+			Addresses: []v1.NodeAddress{
+				{Type: v1.NodeInternalIP, Address: "10.0.0.1"},
+				{Type: v1.NodeExternalIP, Address: "148.142.120.1"},
+			},
+		},
+	})
+
+	var appCh = mainpkg.Start(ctx, conf, client, nil)
+
+	require.Len(t, appCh, 0)
+
+	require.Eventually(t, func() bool {
+		return verifyIPmap(conf.OutputPath, map[string]string{"10.0.0.1": "148.142.120.1"}, false)
+	}, time.Second*2, time.Second/10)
+
+	require.Eventually(t, func() bool {
+		var selectors = make(map[string]string)
+		for _, action := range client.Actions() {
+			switch action := action.(type) {
+			case k8stest.ListAction:
+				selectors[action.GetVerb()] = action.GetListRestrictions().Fields.String()
+			case k8stest.WatchAction:
+				selectors[action.GetVerb()] = action.GetWatchRestrictions().Fields.String()
+			}
+		}
+		return reflect.DeepEqual(map[string]string{"list": "metadata.name=node-1", "watch": "metadata.name=node-1"}, selectors)
+	}, time.Second*2, time.Second/10)
+}
+
+func Test_CloudMetadata(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("k8s.io/klog/v2.(*loggingT).flushDaemon"))
+
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var imds = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/latest/api/token":
+			_, _ = w.Write([]byte("token"))
+		case "/latest/meta-data/public-ipv4":
+			_, _ = w.Write([]byte("203.0.113.5"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer imds.Close()
+
+	var conf = &mainpkg.Config{
+		OutputPath:       filepath.Join(t.TempDir(), "output.yaml"),
+		NodeName:         "node-1",
+		CloudMetadata:    "aws",
+		CloudMetadataURL: imds.URL,
+	}
+
+	var client = fake.NewSimpleClientset(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status: v1.NodeStatus{
+			Addresses: []v1.NodeAddress{{Type: v1.NodeInternalIP, Address: "1.1.1.1"}},
+		},
+	}, &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-2"},
+		Status: v1.NodeStatus{
+			Addresses: []v1.NodeAddress{{Type: v1.NodeInternalIP, Address: "1.1.1.2"}},
+		},
+	})
+
+	var appCh = mainpkg.Start(ctx, conf, client, nil)
+
+	require.Len(t, appCh, 0)
+
+	require.Eventually(t, func() bool {
+		return verifyIPmap(conf.OutputPath, map[string]string{
+			"1.1.1.1": "203.0.113.5",
+			"1.1.1.2": "1.1.1.2",
+		}, true)
+	}, time.Second*2, time.Second/10)
+}
+
+func Test_CloudMetadataOverride(t *testing.T) {
+	defer goleak.VerifyNone(t, goleak.IgnoreTopFunction("k8s.io/klog/v2.(*loggingT).flushDaemon"))
+
+	var ctx, cancel = context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	var imds = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/metadata/loadbalancer" {
+			_, _ = w.Write([]byte(`{"loadbalancer":{"publicIpAddresses":[{"frontendIpAddress":"203.0.113.10"}]}}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer imds.Close()
+
+	var conf = &mainpkg.Config{
+		OutputPath:            filepath.Join(t.TempDir(), "output.yaml"),
+		NodeName:              "node-1",
+		CloudMetadata:         "azure",
+		CloudMetadataURL:      imds.URL,
+		CloudMetadataOverride: true,
+	}
+
+	var client = fake.NewSimpleClientset(&v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Status: v1.NodeStatus{
+			Addresses: []v1.NodeAddress{
+				{Type: v1.NodeInternalIP, Address: "1.1.1.1"},
+				{Type: v1.NodeExternalIP, Address: "2.1.1.1"},
+			},
+		},
+	})
+
+	var appCh = mainpkg.Start(ctx, conf, client, nil)
+
+	require.Len(t, appCh, 0)
+
+	require.Eventually(t, func() bool {
+		return verifyIPmap(conf.OutputPath, map[string]string{"1.1.1.1": "203.0.113.10"}, true)
+	}, time.Second*2, time.Second/10)
+
+	require.False(t, verifyIPmap(conf.OutputPath, map[string]string{"2.1.1.1": "2.1.1.1"}, false))
 }
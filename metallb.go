@@ -0,0 +1,85 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	corelisters "k8s.io/client-go/listers/core/v1"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/metallb"
+)
+
+func startMetalLBSource(ctx context.Context, conf *Config, f *informerFactories, eventsCh chan<- mapipwriter.Event) {
+	var nodes, services = f.nodes(), f.services()
+	var informer = f.customInformer(conf.MetalLBNamespace, metallb.GroupVersionResource)
+	var translate = synchronized(newMetalLBTranslator(ctx, conf, &cachedObjects{
+		nodes:    corelisters.NewNodeLister(nodes.GetIndexer()),
+		services: corelisters.NewServiceLister(services.GetIndexer()),
+	}))
+	f.follow(ctx, nodes, informer, internalIPsChanged, runsOn(announcingNode), translate, eventsCh)
+	f.follow(ctx, services, informer, resourceChanged, isAnnouncedService, translate, eventsCh)
+	f.run(ctx, conf, metallb.ServiceL2StatusResource, informer, translate, eventsCh)
+}
+
+func announcingNode(obj interface{}) string {
+	return fromUnstructured[metallb.ServiceL2Status](obj).Status.Node
+}
+
+// isAnnouncedService reports whether the Service is announced by the status
+func isAnnouncedService(serviceObj, statusObj interface{}) bool {
+	var service, status = serviceObj.(*corev1.Service), fromUnstructured[metallb.ServiceL2Status](statusObj)
+	return service.Namespace == status.Status.ServiceNamespace && service.Name == status.Status.ServiceName
+}
+
+// newMetalLBTranslator returns a translator of ServiceL2Statuses mapping internal IPs of the announcing node to
+// load balancer IPs of the Service. The Service and the node are resolved on events of the status and on their changes,
+// so translations follow a failover of the announcement to another node.
+func newMetalLBTranslator(ctx context.Context, conf *Config, objects clusterObjects) func(watch.Event) []mapipwriter.Event {
+	var published publishedEntries
+	return func(e watch.Event) []mapipwriter.Event {
+		var status = fromUnstructured[metallb.ServiceL2Status](e.Object)
+		var sources, targets []string
+		if e.Type != watch.Deleted {
+			sources, targets = metalLBAnnouncement(ctx, objects, status)
+		}
+		return published.update(status.Namespace+"/"+status.Name, e.Type, translationToSameFamily(e.Type, sources, targets))
+	}
+}
+
+// metalLBAnnouncement returns internal IPs of the node announcing the Service and load balancer IPs of the Service
+func metalLBAnnouncement(ctx context.Context, objects clusterObjects, status *metallb.ServiceL2Status) (nodeIPs, vips []string) {
+	node, err := objects.getNode(ctx, status.Status.Node)
+	if err != nil {
+		log.FromContext(ctx).Warnf("can't get node %v announcing service %v/%v: %v", status.Status.Node,
+			status.Status.ServiceNamespace, status.Status.ServiceName, err.Error())
+		return nil, nil
+	}
+	service, err := objects.getService(ctx, status.Status.ServiceNamespace, status.Status.ServiceName)
+	if err != nil {
+		log.FromContext(ctx).Warnf("can't get service %v/%v announced by node %v: %v", status.Status.ServiceNamespace,
+			status.Status.ServiceName, status.Status.Node, err.Error())
+		return nil, nil
+	}
+
+	return internalIPs(node), loadBalancerIPs(&service.Status.LoadBalancer)
+}
@@ -0,0 +1,113 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+)
+
+// networkStatusAnnotation is the annotation of Multus with the status of networks of the pod
+const networkStatusAnnotation = "k8s.v1.cni.cncf.io/network-status"
+
+// networkStatus is an item of networkStatusAnnotation
+type networkStatus struct {
+	Name    string   `json:"name"`
+	IPs     []string `json:"ips,omitempty"`
+	Default bool     `json:"default,omitempty"`
+}
+
+func startMultusPodSource(ctx context.Context, conf *Config, f *informerFactories, eventsCh chan<- mapipwriter.Event) {
+	var nodes = f.nodes()
+	var informer = f.get(conf.MultusNamespace, v1.ListOptions{LabelSelector: conf.MultusPods}).Core().V1().Pods().Informer()
+	var translate = synchronized(newMultusPodTranslator(ctx, conf, &cachedObjects{nodes: corelisters.NewNodeLister(nodes.GetIndexer())}))
+	f.follow(ctx, nodes, informer, externalAddressesChanged(conf), runsOn(podNodeName), translate, eventsCh)
+	f.run(ctx, conf, "pods", informer, translate, eventsCh)
+}
+
+func listMultusPods(ctx context.Context, conf *Config, c kubernetes.Interface, translate func(watch.Event) []mapipwriter.Event) ([]mapipwriter.Event, error) {
+	list, err := c.CoreV1().Pods(conf.MultusNamespace).List(ctx, v1.ListOptions{LabelSelector: conf.MultusPods})
+	if err != nil {
+		return nil, errors.Wrap(err, "can't list pods")
+	}
+
+	var result []mapipwriter.Event
+	for i := 0; i < len(list.Items); i++ {
+		result = append(result, translate(watch.Event{
+			Type:   watch.Added,
+			Object: &list.Items[i],
+		})...)
+	}
+	return result, nil
+}
+
+// newMultusPodTranslator returns a translator of Pods mapping IPs of their secondary networks attached by Multus to
+// the external IP of the node they run on
+func newMultusPodTranslator(ctx context.Context, conf *Config, objects clusterObjects) func(watch.Event) []mapipwriter.Event {
+	var published publishedEntries
+	return func(e watch.Event) []mapipwriter.Event {
+		var pod = e.Object.(*corev1.Pod)
+		var events []mapipwriter.Event
+		if e.Type != watch.Deleted && pod.Spec.NodeName != "" {
+			if ips := secondaryNetworkIPs(ctx, pod); len(ips) > 0 {
+				events = translationToSameFamily(e.Type, ips, nodeExternalAddresses(ctx, conf, objects, pod.Spec.NodeName))
+			}
+		}
+		for i := range events {
+			events[i].Node = pod.Spec.NodeName
+		}
+		return published.update(pod.Namespace+"/"+pod.Name, e.Type, events)
+	}
+}
+
+// secondaryNetworkIPs returns IPs of non-default networks of networkStatusAnnotation of the pod
+func secondaryNetworkIPs(ctx context.Context, pod *corev1.Pod) []string {
+	var value, ok = pod.Annotations[networkStatusAnnotation]
+	if !ok {
+		return nil
+	}
+	var statuses []networkStatus
+	if err := json.Unmarshal([]byte(value), &statuses); err != nil {
+		log.FromContext(ctx).Warnf("pod %v/%v: invalid %v annotation: %v", pod.Namespace, pod.Name, networkStatusAnnotation, err.Error())
+		return nil
+	}
+
+	var result []string
+	for _, status := range statuses {
+		if status.Default {
+			continue
+		}
+		for _, ip := range status.IPs {
+			if net.ParseIP(ip) != nil {
+				result = append(result, ip)
+			}
+		}
+	}
+	return result
+}
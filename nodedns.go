@@ -0,0 +1,170 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"net"
+	"slices"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+)
+
+// nodeDNSTimeout limits resolving of a hostname of a node
+const nodeDNSTimeout = 5 * time.Second
+
+// ipTypesOfDNS are types of IP addresses DNS addresses of nodes are resolved into
+var ipTypesOfDNS = map[corev1.NodeAddressType]corev1.NodeAddressType{
+	corev1.NodeExternalDNS: corev1.NodeExternalIP,
+	corev1.NodeInternalDNS: corev1.NodeInternalIP,
+}
+
+// nodeResolver resolves DNS addresses of nodes into IPs and caches results until the next refresh. Nil resolver
+// doesn't change nodes.
+type nodeResolver struct {
+	mu    sync.Mutex
+	cache map[string][]string
+}
+
+func newNodeResolver(conf *Config) *nodeResolver {
+	if !conf.NodeResolveDNS {
+		return nil
+	}
+	return &nodeResolver{cache: make(map[string][]string)}
+}
+
+// apply returns the event with IPs of DNS addresses added to the node. DNS addresses are resolved only if the node has
+// no IPs of the corresponding type, e.g. ExternalDNS addresses are resolved if there are no ExternalIP addresses.
+func (r *nodeResolver) apply(ctx context.Context, e watch.Event) watch.Event {
+	if r == nil {
+		return e
+	}
+	var node = e.Object.(*corev1.Node)
+	var present = make(map[corev1.NodeAddressType]bool)
+	for _, address := range node.Status.Addresses {
+		present[address.Type] = true
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var resolved []corev1.NodeAddress
+	for _, address := range node.Status.Addresses {
+		var ipType, ok = ipTypesOfDNS[address.Type]
+		if !ok || present[ipType] {
+			continue
+		}
+		for _, ip := range r.lookup(ctx, address.Address) {
+			var resolvedAddress = corev1.NodeAddress{Type: ipType, Address: ip}
+			if !slices.Contains(resolved, resolvedAddress) {
+				resolved = append(resolved, resolvedAddress)
+			}
+		}
+	}
+	if len(resolved) == 0 {
+		return e
+	}
+
+	node = node.DeepCopy()
+	node.Status.Addresses = append(node.Status.Addresses, resolved...)
+	e.Object = node
+	return e
+}
+
+func (r *nodeResolver) lookup(ctx context.Context, host string) []string {
+	if ips, ok := r.cache[host]; ok {
+		return ips
+	}
+	var ips = resolveHost(ctx, host)
+	r.cache[host] = ips
+	return ips
+}
+
+// refresh resolves cached hostnames again. It returns true if any of them is changed. Previous results are kept if
+// a hostname can't be resolved.
+func (r *nodeResolver) refresh(ctx context.Context) (changed bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for host, ips := range r.cache {
+		if next := resolveHost(ctx, host); next != nil && !slices.Equal(next, ips) {
+			r.cache[host] = next
+			changed = true
+		}
+	}
+	return changed
+}
+
+// resolveHost returns sorted IPs of the host. It returns nil if the host can't be resolved.
+func resolveHost(ctx context.Context, host string) []string {
+	var lookupCtx, cancel = context.WithTimeout(ctx, nodeDNSTimeout)
+	defer cancel()
+
+	ips, err := net.DefaultResolver.LookupHost(lookupCtx, host)
+	if err != nil {
+		log.FromContext(ctx).Warnf("can't resolve node address %v: %v", host, err.Error())
+		return nil
+	}
+	slices.Sort(ips)
+	return ips
+}
+
+// withResolver returns the translator applying the resolver to events of nodes first
+func withResolver(ctx context.Context, resolver *nodeResolver, translate func(watch.Event) []mapipwriter.Event) func(watch.Event) []mapipwriter.Event {
+	return func(e watch.Event) []mapipwriter.Event {
+		return translate(resolver.apply(ctx, e))
+	}
+}
+
+// startNodeDNSRefresh resolves DNS addresses of nodes again every NodeDNSRefresh. If IPs of a hostname are changed
+// then nodes are translated again with translate, which applies the resolver and withdraws translations of previous
+// IPs.
+func startNodeDNSRefresh(ctx context.Context, conf *Config, nodes cache.Store, resolver *nodeResolver,
+	translate func(watch.Event) []mapipwriter.Event, eventsCh chan<- mapipwriter.Event) {
+	if resolver == nil || conf.NodeDNSRefresh <= 0 {
+		return
+	}
+
+	go func() {
+		var ticker = time.NewTicker(conf.NodeDNSRefresh)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			if !resolver.refresh(ctx) {
+				continue
+			}
+			for _, node := range nodes.List() {
+				for _, event := range translate(watch.Event{Type: watch.Modified, Object: node.(*corev1.Node)}) {
+					eventsCh <- event
+				}
+			}
+		}
+	}()
+}
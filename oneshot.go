@@ -0,0 +1,227 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/cilium"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/clusterapi"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/gatewayapi"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/iptranslation"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/kubevirt"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/metallb"
+)
+
+// RunOnce lists objects of the enabled sources once, adds static mappings and writes the map into stdout or into the
+// output paths if OneShotStdout is false
+func RunOnce(ctx context.Context, conf *Config, c kubernetes.Interface, d dynamic.Interface, stdout io.Writer) error {
+	mapWriter, err := newOneShotWriter(conf, stdout)
+	if err != nil {
+		return err
+	}
+
+	var static []mapipwriter.Event
+	if conf.StaticMappings != "" {
+		if static, err = staticEvents(conf); err != nil {
+			return err
+		}
+	}
+
+	var translateNode func(watch.Event) []mapipwriter.Event
+	if !conf.ConfigMapOnly {
+		translateNode = newNodeTranslator(ctx, conf, discoverPublicIPs(ctx, conf))
+	}
+	events, err := listOneShotSources(ctx, conf, c, d, translateNode)
+	if err != nil {
+		return err
+	}
+
+	return mapWriter.WriteOnce(ctx, append(static, withoutStatic(static, events)...))
+}
+
+func newOneShotWriter(conf *Config, stdout io.Writer) (*mapipwriter.MapIPWriter, error) {
+	render, err := newRenderer(conf)
+	if err != nil {
+		return nil, err
+	}
+
+	var mapWriter = &mapipwriter.MapIPWriter{
+		Order: conf.OutputOrder,
+	}
+	if conf.ToCIDRRemap != "" {
+		if mapWriter.TransformTo, err = newTransformTo(conf); err != nil {
+			return nil, err
+		}
+	}
+	if conf.OneShotStdout {
+		mapWriter.Targets = []mapipwriter.Target{&mapipwriter.StreamTarget{Writer: stdout, Render: render}}
+	} else if mapWriter.Targets, err = newFileTargets(conf, render); err != nil {
+		return nil, err
+	}
+	return mapWriter, nil
+}
+
+// oneShotSource lists events of objects of a source once
+type oneShotSource struct {
+	enabled bool
+	list    func() ([]mapipwriter.Event, error)
+}
+
+// listOneShotSources returns events of objects of the enabled sources in the order of Start. Nodes are listed if
+// translateNode is set.
+func listOneShotSources(ctx context.Context, conf *Config, c kubernetes.Interface, d dynamic.Interface,
+	translateNode func(watch.Event) []mapipwriter.Event) ([]mapipwriter.Event, error) {
+	var translateConfigMap = newConfigMapTranslator(ctx, conf)
+	var objects = newClientObjects(c)
+	var sources = []oneShotSource{
+		{conf.FromConfigMap != "", func() ([]mapipwriter.Event, error) {
+			return getConfigMaps(ctx, conf, c, translateConfigMap)
+		}},
+		{conf.FromConfigMapSelector != "", func() ([]mapipwriter.Event, error) {
+			return listConfigMaps(ctx, conf, c, translateConfigMap)
+		}},
+		{conf.FromFiles != "", func() ([]mapipwriter.Event, error) {
+			return readFiles(conf, new(fileTranslator))
+		}},
+		{conf.FromIPTranslations, func() ([]mapipwriter.Event, error) {
+			var s = newIPTranslations(conf)
+			return listCustomResources(ctx, d, iptranslation.GroupVersionResource, configMapNamespace(conf),
+				func(e watch.Event) []mapipwriter.Event { return s.update(e, time.Now()) })
+		}},
+		{translateNode != nil, func() ([]mapipwriter.Event, error) {
+			return listNodes(ctx, c, nodeListOptions(conf), withResolver(ctx, newNodeResolver(conf), translateNode))
+		}},
+		{conf.FromLoadBalancers, func() ([]mapipwriter.Event, error) {
+			return listServices(ctx, conf, c, newServiceTranslator(ctx, conf, objects))
+		}},
+		{conf.FromIngresses, func() ([]mapipwriter.Event, error) {
+			return listIngresses(ctx, conf, c, newIngressTranslator(ctx, conf, objects))
+		}},
+		{conf.FromMetalLB, func() ([]mapipwriter.Event, error) {
+			return listCustomResources(ctx, d, metallb.GroupVersionResource, conf.MetalLBNamespace, newMetalLBTranslator(ctx, conf, objects))
+		}},
+		{conf.FromGatewayAPI, func() ([]mapipwriter.Event, error) {
+			return listCustomResources(ctx, d, gatewayapi.GroupVersionResource, conf.GatewayAPINamespace, newGatewayTranslator(ctx, objects))
+		}},
+		{conf.FromClusterAPI, func() ([]mapipwriter.Event, error) {
+			return listCustomResources(ctx, d, clusterapi.GroupVersionResource, conf.ClusterAPINamespace, newMachineTranslator(conf))
+		}},
+		{conf.FromKubeVirt, func() ([]mapipwriter.Event, error) {
+			return listCustomResources(ctx, d, kubevirt.GroupVersionResource, conf.KubeVirtNamespace, newVMITranslator(ctx, conf, objects))
+		}},
+		{conf.FromCiliumNodes, func() ([]mapipwriter.Event, error) {
+			return listCustomResources(ctx, d, cilium.GroupVersionResource, v1.NamespaceAll, newCiliumNodeTranslator(conf))
+		}},
+		{conf.RemoteKubeconfigs != "", func() ([]mapipwriter.Event, error) {
+			return listRemoteClusterNodes(ctx, conf)
+		}},
+		{conf.EndpointSliceServices != "", func() ([]mapipwriter.Event, error) {
+			return listServicesEndpointSlices(ctx, conf, c, objects)
+		}},
+		{conf.HostNetworkPods != "", func() ([]mapipwriter.Event, error) {
+			return listHostNetworkPods(ctx, conf, c, newHostNetworkPodTranslator(ctx, conf, objects))
+		}},
+		{conf.MultusPods != "", func() ([]mapipwriter.Event, error) {
+			return listMultusPods(ctx, conf, c, newMultusPodTranslator(ctx, conf, objects))
+		}},
+	}
+
+	var result []mapipwriter.Event
+	for _, source := range sources {
+		if !source.enabled {
+			continue
+		}
+		events, err := source.list()
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, events...)
+	}
+	return result, nil
+}
+
+// getConfigMaps returns events of the entries of the configmaps of FromConfigMap
+func getConfigMaps(ctx context.Context, conf *Config, c kubernetes.Interface, translate func(watch.Event) []mapipwriter.Event) ([]mapipwriter.Event, error) {
+	var result []mapipwriter.Event
+	for _, name := range configMapNames(conf) {
+		events, err := getConfigMap(ctx, conf, c, name, translate)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, events...)
+	}
+	return result, nil
+}
+
+// listCustomResources returns events of custom resources of the namespace translated by translate. Empty namespace
+// means all namespaces.
+func listCustomResources(ctx context.Context, d dynamic.Interface, resource schema.GroupVersionResource, namespace string,
+	translate func(watch.Event) []mapipwriter.Event) ([]mapipwriter.Event, error) {
+	list, err := d.Resource(resource).Namespace(namespace).List(ctx, v1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't list %v", resource.Resource)
+	}
+
+	var result []mapipwriter.Event
+	for i := range list.Items {
+		result = append(result, translate(watch.Event{Type: watch.Added, Object: &list.Items[i]})...)
+	}
+	return result, nil
+}
+
+// listRemoteClusterNodes returns events of nodes of the remote clusters of RemoteKubeconfigs
+func listRemoteClusterNodes(ctx context.Context, conf *Config) ([]mapipwriter.Event, error) {
+	clusters, err := loadRemoteClusters(conf)
+	if err != nil {
+		return nil, err
+	}
+	var result []mapipwriter.Event
+	for _, cluster := range clusters {
+		var translate = newRemoteNodeTranslator(ctx, conf, cluster.name)
+		events, listErr := listNodes(ctx, cluster.client, v1.ListOptions{LabelSelector: conf.NodeSelector}, withResolver(ctx, newNodeResolver(conf), translate))
+		if listErr != nil {
+			return nil, errors.Wrapf(listErr, "cluster %v", cluster.name)
+		}
+		result = append(result, events...)
+	}
+	return result, nil
+}
+
+// listServicesEndpointSlices returns events of EndpointSlices of the Services of EndpointSliceServices
+func listServicesEndpointSlices(ctx context.Context, conf *Config, c kubernetes.Interface, objects clusterObjects) ([]mapipwriter.Event, error) {
+	var translate = newEndpointSliceTranslator(ctx, conf, objects)
+	var result []mapipwriter.Event
+	for _, service := range parseEndpointSliceServices(conf) {
+		events, err := listEndpointSlices(ctx, c, service, translate)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, events...)
+	}
+	return result, nil
+}
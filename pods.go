@@ -0,0 +1,86 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+)
+
+func startHostNetworkPodSource(ctx context.Context, conf *Config, f *informerFactories, eventsCh chan<- mapipwriter.Event) {
+	var nodes = f.nodes()
+	var informer = f.get(conf.HostNetworkNamespace, v1.ListOptions{LabelSelector: conf.HostNetworkPods}).Core().V1().Pods().Informer()
+	var translate = synchronized(newHostNetworkPodTranslator(ctx, conf, &cachedObjects{nodes: corelisters.NewNodeLister(nodes.GetIndexer())}))
+	f.follow(ctx, nodes, informer, externalAddressesChanged(conf), runsOn(podNodeName), translate, eventsCh)
+	f.run(ctx, conf, "pods", informer, translate, eventsCh)
+}
+
+func listHostNetworkPods(ctx context.Context, conf *Config, c kubernetes.Interface, translate func(watch.Event) []mapipwriter.Event) ([]mapipwriter.Event, error) {
+	list, err := c.CoreV1().Pods(conf.HostNetworkNamespace).List(ctx, v1.ListOptions{LabelSelector: conf.HostNetworkPods})
+	if err != nil {
+		return nil, errors.Wrap(err, "can't list pods")
+	}
+
+	var result []mapipwriter.Event
+	for i := 0; i < len(list.Items); i++ {
+		result = append(result, translate(watch.Event{
+			Type:   watch.Added,
+			Object: &list.Items[i],
+		})...)
+	}
+	return result, nil
+}
+
+// newHostNetworkPodTranslator returns a translator of Pods with host network mapping their IPs to the external IP of
+// the node they run on. Pods without host network are ignored.
+func newHostNetworkPodTranslator(ctx context.Context, conf *Config, objects clusterObjects) func(watch.Event) []mapipwriter.Event {
+	var published publishedEntries
+	return func(e watch.Event) []mapipwriter.Event {
+		var pod = e.Object.(*corev1.Pod)
+		var events []mapipwriter.Event
+		if e.Type != watch.Deleted && pod.Spec.HostNetwork && pod.Spec.NodeName != "" {
+			events = translationToSameFamily(e.Type, podIPs(pod), nodeExternalAddresses(ctx, conf, objects, pod.Spec.NodeName))
+		}
+		for i := range events {
+			events[i].Node = pod.Spec.NodeName
+		}
+		return published.update(pod.Namespace+"/"+pod.Name, e.Type, events)
+	}
+}
+
+func podNodeName(obj interface{}) string {
+	return obj.(*corev1.Pod).Spec.NodeName
+}
+
+func podIPs(pod *corev1.Pod) []string {
+	var result []string
+	for _, ip := range pod.Status.PodIPs {
+		result = append(result, ip.IP)
+	}
+	if len(result) == 0 && pod.Status.PodIP != "" {
+		result = append(result, pod.Status.PodIP)
+	}
+	return result
+}
@@ -0,0 +1,60 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+)
+
+// startReconcile lists objects of all sources from scratch every ReconcileInterval and replaces entries of the map
+// with them, so entries which no longer correspond to any source are removed even if their delete events are missed.
+// The map is kept if any source can't be listed. Nodes are translated with translateNode if it's set.
+func startReconcile(ctx context.Context, conf *Config, c kubernetes.Interface, d dynamic.Interface, translateNode func(watch.Event) []mapipwriter.Event,
+	mapWriter *mapipwriter.MapIPWriter) {
+	go func() {
+		var ticker = time.NewTicker(conf.ReconcileInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			var static []mapipwriter.Event
+			if conf.StaticMappings != "" {
+				// static mappings are validated at the start
+				static, _ = staticEvents(conf)
+			}
+			events, err := listOneShotSources(ctx, conf, c, d, translateNode)
+			if err != nil {
+				log.FromContext(ctx).Errorf("can't reconcile the map: %v", err.Error())
+				continue
+			}
+			mapWriter.Reconcile(ctx, append(static, withoutStatic(static, events)...))
+		}
+	}()
+}
@@ -0,0 +1,99 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+)
+
+// remoteCluster is a cluster of a kubeconfig of RemoteKubeconfigs
+type remoteCluster struct {
+	name   string
+	client kubernetes.Interface
+}
+
+// loadRemoteClusters creates clients of kubeconfigs of RemoteKubeconfigs. The name of the file without the extension
+// is the identifier of the cluster. Hidden files are skipped, so the directory can be a mounted Secret.
+func loadRemoteClusters(conf *Config) ([]remoteCluster, error) {
+	files, err := os.ReadDir(conf.RemoteKubeconfigs)
+	if err != nil {
+		return nil, errors.Wrapf(err, "can't read kubeconfigs of remote clusters from %v", conf.RemoteKubeconfigs)
+	}
+
+	var result []remoteCluster
+	for _, file := range files {
+		if strings.HasPrefix(file.Name(), ".") {
+			continue
+		}
+		var path = filepath.Join(conf.RemoteKubeconfigs, file.Name())
+		if info, statErr := os.Stat(path); statErr != nil || info.IsDir() {
+			continue
+		}
+		restConfig, buildErr := clientcmd.BuildConfigFromFlags("", path)
+		if buildErr != nil {
+			return nil, errors.Wrapf(buildErr, "can't load kubeconfig %v", path)
+		}
+		client, clientErr := kubernetes.NewForConfig(restConfig)
+		if clientErr != nil {
+			return nil, errors.Wrapf(clientErr, "can't create client of kubeconfig %v", path)
+		}
+		result = append(result, remoteCluster{
+			name:   strings.TrimSuffix(file.Name(), filepath.Ext(file.Name())),
+			client: client,
+		})
+	}
+	return result, nil
+}
+
+// newRemoteNodeTranslator returns a translator of nodes of the remote cluster. Translations are marked with the
+// identifier of the cluster. The node the app runs on belongs to the local cluster, so cloud addresses are not applied.
+func newRemoteNodeTranslator(ctx context.Context, conf *Config, cluster string) func(watch.Event) []mapipwriter.Event {
+	var translate = newNodeTranslator(ctx, conf, nil)
+	return func(e watch.Event) []mapipwriter.Event {
+		var result = translate(e)
+		for i := range result {
+			result[i].Cluster = cluster
+		}
+		return result
+	}
+}
+
+// startRemoteNodeSources lists and watches nodes of remote clusters. A remote cluster that is not available doesn't
+// block the app, its entries are loaded when it's available.
+func startRemoteNodeSources(ctx context.Context, conf *Config, clusters []remoteCluster, eventsCh chan<- mapipwriter.Event) {
+	for _, cluster := range clusters {
+		var cluster = cluster
+		var resolver = newNodeResolver(conf)
+		var translate = withPublished(nodeKey, withResolver(ctx, resolver, newRemoteNodeTranslator(ctx, conf, cluster.name)))
+
+		var f = newInformerFactories(conf, cluster.client, nil)
+		var informer = f.get(v1.NamespaceAll, v1.ListOptions{LabelSelector: conf.NodeSelector}).Core().V1().Nodes().Informer()
+		f.run(ctx, conf, "nodes of cluster "+cluster.name, informer, translate, eventsCh)
+		startNodeDNSRefresh(ctx, conf, informer.GetStore(), resolver, translate, eventsCh)
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"net"
+	"slices"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+)
+
+func startServiceSource(ctx context.Context, conf *Config, f *informerFactories, eventsCh chan<- mapipwriter.Event) {
+	var nodes = f.nodes()
+	var informer = f.get(conf.LoadBalancerNamespace, v1.ListOptions{}).Core().V1().Services().Informer()
+	var translate = synchronized(newServiceTranslator(ctx, conf, &cachedObjects{nodes: corelisters.NewNodeLister(nodes.GetIndexer())}))
+	if conf.LoadBalancerNodePorts {
+		f.follow(ctx, nodes, informer, internalIPsChanged, exposesNodePorts, translate, eventsCh)
+	}
+	f.run(ctx, conf, "services", informer, translate, eventsCh)
+}
+
+// exposesNodePorts reports whether the Service is a LoadBalancer with NodePorts exposed on every node
+func exposesNodePorts(_, serviceObj interface{}) bool {
+	var service = serviceObj.(*corev1.Service)
+	return service.Spec.Type == corev1.ServiceTypeLoadBalancer && hasNodePorts(service)
+}
+
+func listServices(ctx context.Context, conf *Config, c kubernetes.Interface, translate func(watch.Event) []mapipwriter.Event) ([]mapipwriter.Event, error) {
+	list, err := c.CoreV1().Services(conf.LoadBalancerNamespace).List(ctx, v1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrap(err, "can't list services")
+	}
+
+	var result []mapipwriter.Event
+	for i := 0; i < len(list.Items); i++ {
+		result = append(result, translate(watch.Event{
+			Type:   watch.Added,
+			Object: &list.Items[i],
+		})...)
+	}
+	return result, nil
+}
+
+// newServiceTranslator returns a translator of Services of type LoadBalancer. Translations of a Service are withdrawn
+// when its ingress addresses change or its type is changed to another one, and of internal IPs of nodes when nodes are
+// removed.
+func newServiceTranslator(ctx context.Context, conf *Config, objects clusterObjects) func(watch.Event) []mapipwriter.Event {
+	var published publishedEntries
+	return func(e watch.Event) []mapipwriter.Event {
+		var service = e.Object.(*corev1.Service)
+		var sources []string
+		if e.Type != watch.Deleted && service.Spec.Type == corev1.ServiceTypeLoadBalancer {
+			sources = serviceClusterIPs(service)
+			if conf.LoadBalancerNodePorts && hasNodePorts(service) {
+				sources = append(sources, nodeInternalIPs(ctx, conf, objects)...)
+			}
+		}
+		var events = translationToSameFamily(e.Type, sources, loadBalancerIPs(&service.Status.LoadBalancer))
+		return published.update(service.Namespace+"/"+service.Name, e.Type, events)
+	}
+}
+
+// translationToSameFamily maps each source address to the first target address of the same family
+func translationToSameFamily(eventType watch.EventType, sources, targets []string) []mapipwriter.Event {
+	var result []mapipwriter.Event
+	for _, source := range sources {
+		for _, to := range targets {
+			if isIPv4(source) != isIPv4(to) {
+				continue
+			}
+			result = append(result, mapipwriter.Event{
+				Type:        eventType,
+				Translation: mapipwriter.Translation{From: source, To: to},
+			})
+			break
+		}
+	}
+	return result
+}
+
+func serviceClusterIPs(service *corev1.Service) []string {
+	var result []string
+	for _, ip := range append([]string{service.Spec.ClusterIP}, service.Spec.ClusterIPs...) {
+		if net.ParseIP(ip) != nil && !slices.Contains(result, ip) {
+			result = append(result, ip)
+		}
+	}
+	return result
+}
+
+// loadBalancerIPs returns ingress IPs of the load balancer. Hostname ingresses are skipped.
+func loadBalancerIPs(status *corev1.LoadBalancerStatus) []string {
+	var result []string
+	for _, ingress := range status.Ingress {
+		if net.ParseIP(ingress.IP) != nil {
+			result = append(result, ingress.IP)
+		}
+	}
+	return result
+}
+
+func hasNodePorts(service *corev1.Service) bool {
+	for i := range service.Spec.Ports {
+		if service.Spec.Ports[i].NodePort != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// nodeInternalIPs lists internal IPs of the nodes matching NodeSelector NodePorts are exposed on
+func nodeInternalIPs(ctx context.Context, conf *Config, objects clusterObjects) []string {
+	nodes, err := objects.listNodes(ctx, conf.NodeSelector)
+	if err != nil {
+		log.FromContext(ctx).Errorf("can't list nodes of NodePorts: %v", err.Error())
+		return nil
+	}
+	var result []string
+	for _, node := range nodes {
+		result = append(result, internalIPs(node)...)
+	}
+	return result
+}
+
+func isIPv4(ip string) bool {
+	return net.ParseIP(ip).To4() != nil
+}
@@ -0,0 +1,132 @@
+// Copyright (c) 2026 Cisco and/or its affiliates.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at:
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/networkservicemesh/sdk/pkg/tools/log"
+
+	"github.com/networkservicemesh/cmd-map-ip-k8s/internal/mapipwriter"
+)
+
+// parseStaticMappings parses comma separated from=to translations of StaticMappings
+func parseStaticMappings(list string) ([]mapipwriter.Translation, error) {
+	var result []mapipwriter.Translation
+	for _, item := range splitList(list) {
+		from, to, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, errors.Errorf("invalid static mapping %q: expected from=to", item)
+		}
+		var translation = mapipwriter.Translation{From: strings.TrimSpace(from), To: strings.TrimSpace(to)}
+		for _, address := range []string{translation.From, translation.To} {
+			if !isIPOrCIDR(address) {
+				return nil, errors.Errorf("invalid static mapping %q: %q is neither an IP nor a CIDR", item, address)
+			}
+		}
+		if err := translation.Validate(); err != nil {
+			return nil, errors.Wrapf(err, "invalid static mapping %q", item)
+		}
+		result = append(result, translation)
+	}
+	return result, nil
+}
+
+func isIPOrCIDR(address string) bool {
+	if strings.Contains(address, "/") {
+		_, _, err := net.ParseCIDR(address)
+		return err == nil
+	}
+	return net.ParseIP(address) != nil
+}
+
+// staticEvents returns StaticMappings as events
+func staticEvents(conf *Config) ([]mapipwriter.Event, error) {
+	translations, err := parseStaticMappings(conf.StaticMappings)
+	if err != nil {
+		return nil, err
+	}
+	var result []mapipwriter.Event
+	for _, translation := range translations {
+		result = append(result, mapipwriter.Event{Type: watch.Added, Translation: translation})
+	}
+	return result, nil
+}
+
+// withoutStatic filters out events of dynamic sources which From address is mapped by static events. Static
+// mappings take precedence over all other sources.
+func withoutStatic(static, events []mapipwriter.Event) []mapipwriter.Event {
+	var result []mapipwriter.Event
+	for i := range events {
+		if !isStaticallyMapped(static, events[i].From) {
+			result = append(result, events[i])
+		}
+	}
+	return result
+}
+
+func isStaticallyMapped(static []mapipwriter.Event, from string) bool {
+	for i := range static {
+		if static[i].From == from {
+			return true
+		}
+	}
+	return false
+}
+
+// startStaticMappings sends static events into out and forwards events of dynamic sources from the returned channel
+// into out unless their From address is mapped statically
+func startStaticMappings(ctx context.Context, conf *Config, out chan<- mapipwriter.Event) chan<- mapipwriter.Event {
+	static, err := staticEvents(conf)
+	if err != nil {
+		log.FromContext(ctx).Fatal(err.Error())
+	}
+	var in = make(chan mapipwriter.Event, cap(out))
+
+	go func() {
+		for i := range static {
+			select {
+			case out <- static[i]:
+			case <-ctx.Done():
+				return
+			}
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event := <-in:
+				if isStaticallyMapped(static, event.From) {
+					log.FromContext(ctx).Debugf("entry %v is overridden by a static mapping", event.String())
+					continue
+				}
+				select {
+				case out <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return in
+}